@@ -4,8 +4,10 @@ import (
 	"image"
 	"image/color"
 	"io"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
 	"sort"
 )
 
@@ -100,6 +102,34 @@ const (
 	ImageCover
 )
 
+// ImageResampling specifies the interpolation used when an image is resampled to the resolution of the output, such as by the rasterizer or a PDF viewer. BicubicResampling is the default. NearestResampling keeps hard pixel edges, which is useful for pixel art. BoxResampling averages the image pixels that fall within each output pixel, which avoids aliasing for strongly minified images. Bilinear, bicubic and Lanczos resampling are smoothed when minifying as well. Renderers that only support smooth or non-smooth interpolation, such as PDF and SVG, treat any resampling other than NearestResampling as smooth.
+type ImageResampling int
+
+// See ImageResampling.
+const (
+	BicubicResampling ImageResampling = iota
+	NearestResampling
+	BilinearResampling
+	LanczosResampling
+	BoxResampling
+)
+
+func (resampling ImageResampling) String() string {
+	switch resampling {
+	case BicubicResampling:
+		return "Bicubic"
+	case NearestResampling:
+		return "Nearest"
+	case BilinearResampling:
+		return "Bilinear"
+	case LanczosResampling:
+		return "Lanczos"
+	case BoxResampling:
+		return "Box"
+	}
+	return "Unknown"
+}
+
 ////////////////////////////////////////////////////////////////
 
 // Paint is the type of paint used to fill or stroke a path. It can be either a color or a pattern. Default is transparent (no paint).
@@ -163,16 +193,17 @@ func ScaleDash(scale float64, offset float64, d []float64) (float64, []float64)
 	return offset * scale, d2
 }
 
-// Style is the path style that defines how to draw the path. When Fill is not set it will not fill the path. If StrokeColor is transparent or StrokeWidth is zero, it will not stroke the path. If Dashes is an empty array, it will not draw dashes but instead a solid stroke line. FillRule determines how to fill the path when paths overlap and have certain directions (clockwise, counter clockwise).
+// Style is the path style that defines how to draw the path. When Fill is not set it will not fill the path. If StrokeColor is transparent or StrokeWidth is zero, it will not stroke the path. If Dashes is an empty array, it will not draw dashes but instead a solid stroke line. FillRule determines how to fill the path when paths overlap and have certain directions (clockwise, counter clockwise). If StrokeProfile is set, the stroke width is scaled by StrokeProfile(t) along the path and the stroke is drawn as a filled outline without dashes, see Path.StrokeVariable. The profile is applied by Context and Canvas only, other renderers draw a constant-width stroke when passed such a style directly.
 type Style struct {
-	Fill         Paint
-	Stroke       Paint
-	StrokeWidth  float64
-	StrokeCapper Capper
-	StrokeJoiner Joiner
-	DashOffset   float64
-	Dashes       []float64
-	FillRule     // TODO: test for all renderers
+	Fill          Paint
+	Stroke        Paint
+	StrokeWidth   float64
+	StrokeCapper  Capper
+	StrokeJoiner  Joiner
+	StrokeProfile func(float64) float64
+	DashOffset    float64
+	Dashes        []float64
+	FillRule      // TODO: test for all renderers
 }
 
 // HasFill returns true if the style has a fill
@@ -210,6 +241,19 @@ type Renderer interface {
 	RenderImage(img image.Image, m Matrix)
 }
 
+// Metadata is information attached to drawing operations, such as an identifier, CSS classes, a link, and arbitrary key/values. It is passed to renderers that implement `SetMetadata(Metadata)`, such as the SVG renderer that writes them as attributes and the PDF renderer that adds link annotations. The identifier should be unique for the document.
+type Metadata struct {
+	ID      string
+	Classes []string
+	Link    string // URI, or # followed by an anchor name
+	Data    map[string]string
+}
+
+// Empty returns true if no metadata is set.
+func (meta Metadata) Empty() bool {
+	return meta.ID == "" && len(meta.Classes) == 0 && meta.Link == "" && len(meta.Data) == 0
+}
+
 ////////////////////////////////////////////////////////////////
 
 // CoordSystem is the coordinate system, which can be either of the four cartesian quadrants. Most useful are the I'th and IV'th quadrants. CartesianI is the default quadrant with the zero-point in the bottom-left (the default for mathematics). The CartesianII has its zero-point in the bottom-right, CartesianIII in the top-right, and CartesianIV in the top-left (often used as default for printing devices). See https://en.wikipedia.org/wiki/Cartesian_coordinate_system#Quadrants_and_octants for an explanation.
@@ -226,9 +270,10 @@ const (
 // ContextState defines the state of the context, including fill or stroke style, view and coordinate view.
 type ContextState struct {
 	Style
-	view        Matrix
-	coordView   Matrix
-	coordSystem CoordSystem
+	ImageResampling ImageResampling
+	view            Matrix
+	coordView       Matrix
+	coordSystem     CoordSystem
 }
 
 // Context maintains the state for the current path, path style, and view transformation matrix.
@@ -469,6 +514,11 @@ func (c *Context) SetStrokeJoiner(joiner Joiner) {
 	c.Style.StrokeJoiner = joiner
 }
 
+// SetStrokeProfile sets the width profile for stroking operations, which scales the stroke width along the path for t in [0,1], the distance along the path divided by its total length. Strokes with a profile are converted to filled outlines and Dashes is ignored. The default stroke profile is nil for a constant stroke width.
+func (c *Context) SetStrokeProfile(profile func(float64) float64) {
+	c.Style.StrokeProfile = profile
+}
+
 // SetDashes sets the dash pattern to be used for stroking operations. The dash offset denotes the offset into the dash array in millimeters from where to start. Negative values are allowed. The default dashes is empty at zero offset.
 func (c *Context) SetDashes(offset float64, dashes ...float64) {
 	c.Style.DashOffset = offset
@@ -480,6 +530,11 @@ func (c *Context) SetFillRule(rule FillRule) {
 	c.Style.FillRule = rule
 }
 
+// SetImageResampling sets the interpolation used to resample images drawn with DrawImage and FitImage. The default is BicubicResampling. This is passed to renderers that implement `SetImageResampling(ImageResampling)`, such as `Canvas` and the rasterizer.
+func (c *Context) SetImageResampling(resampling ImageResampling) {
+	c.ImageResampling = resampling
+}
+
 // ResetStyle resets the draw state to its default (colors, stroke widths, dashes, ...).
 func (c *Context) ResetStyle() {
 	c.Style = DefaultStyle
@@ -492,6 +547,13 @@ func (c *Context) SetZIndex(zindex int) {
 	}
 }
 
+// SetMetadata sets the metadata of the drawing operations that follow, until it is set again. Use an empty Metadata to stop attaching metadata. This will call the renderer's `SetMetadata` function only if it exists, such as for `Canvas`.
+func (c *Context) SetMetadata(meta Metadata) {
+	if setter, ok := c.Renderer.(interface{ SetMetadata(Metadata) }); ok {
+		setter.SetMetadata(meta)
+	}
+}
+
 // Pos returns the current position of the path, which is the end point of the last command.
 func (c *Context) Pos() (float64, float64) {
 	return c.path.Pos().X, c.path.Pos().Y
@@ -630,7 +692,7 @@ func (c *Context) FitImage(img image.Image, rect Rect, fit ImageFit) Rect {
 	if c.coordSystem == CartesianII || c.coordSystem == CartesianIII {
 		m = m.ReflectXAbout(float64(img.Bounds().Size().X) / 2.0)
 	}
-	c.RenderImage(img, m)
+	c.renderImage(img, m)
 	return rect
 }
 
@@ -647,6 +709,13 @@ func (c *Context) DrawPath(x, y float64, paths ...*Path) {
 	coord := c.coordView.Dot(Point{x, y})
 	m = m.Mul(c.view).Translate(coord.X, coord.Y)
 
+	if style.StrokeProfile != nil {
+		for _, path := range paths {
+			renderStrokeProfile(c.Renderer, path, style, m)
+		}
+		return
+	}
+
 	dashes := style.Dashes
 	for _, path := range paths {
 		var ok bool
@@ -658,6 +727,29 @@ func (c *Context) DrawPath(x, y float64, paths ...*Path) {
 	}
 }
 
+// renderStrokeProfile renders the path with a variable-width stroke by converting the stroke to a filled outline.
+func renderStrokeProfile(r Renderer, path *Path, style Style, m Matrix) {
+	if style.HasFill() {
+		fill := style
+		fill.Stroke = Paint{}
+		fill.StrokeProfile = nil
+		r.RenderPath(path, fill, m)
+	}
+	if style.HasStroke() {
+		width := func(t float64) float64 {
+			return style.StrokeWidth * style.StrokeProfile(t)
+		}
+		outline := path.StrokeVariable(width, style.StrokeCapper, style.StrokeJoiner, Tolerance)
+
+		stroke := style
+		stroke.Fill = style.Stroke
+		stroke.Stroke = Paint{}
+		stroke.StrokeProfile = nil
+		stroke.FillRule = NonZero
+		r.RenderPath(outline, stroke, m)
+	}
+}
+
 // DrawText draws text at position (x,y) using the current draw state.
 func (c *Context) DrawText(x, y float64, text *Text) {
 	if text.Empty() {
@@ -698,6 +790,14 @@ func (c *Context) DrawImage(x, y float64, img image.Image, resolution Resolution
 	if c.coordSystem == CartesianII || c.coordSystem == CartesianIII {
 		m = m.ReflectXAbout(float64(img.Bounds().Size().X) / 2.0)
 	}
+	c.renderImage(img, m)
+}
+
+// renderImage renders an image using the image resampling of the draw state.
+func (c *Context) renderImage(img image.Image, m Matrix) {
+	if setter, ok := c.Renderer.(interface{ SetImageResampling(ImageResampling) }); ok {
+		setter.SetImageResampling(c.ImageResampling)
+	}
 	c.RenderImage(img, m)
 }
 
@@ -711,15 +811,29 @@ type layer struct {
 	text *Text
 	img  image.Image
 
-	m     Matrix
-	style Style // only for path
+	m          Matrix
+	style      Style           // only for path
+	resampling ImageResampling // only for img
+	bounds     Rect            // before applying m
+	metadata   *Metadata       // nil if empty, shared between layers
+}
+
+// LayerRef refers to a drawing operation on the canvas by its z-index and its position within that z-index in the order of drawing.
+type LayerRef struct {
+	ZIndex, Index int
 }
 
 // Canvas stores all drawing operations as layers that can be re-rendered to other renderers.
 type Canvas struct {
-	layers map[int][]layer
-	zindex int
-	W, H   float64
+	layers     map[int][]layer
+	zindex     int
+	metadata   *Metadata
+	resampling ImageResampling
+	W, H       float64
+
+	// spatial index over the layer bounds, built when needed
+	index     *RTree
+	indexRefs []LayerRef
 }
 
 // New returns a new canvas with width and height in millimeters, that records all drawing operations into layers. The canvas can then be rendered to any other renderer.
@@ -741,13 +855,23 @@ func (c *Canvas) Size() (float64, float64) {
 	return c.W, c.H
 }
 
-// RenderPath renders a path to the canvas using a style and a transformation matrix.
+// RenderPath renders a path to the canvas using a style and a transformation matrix. Strokes with a stroke profile are stored as filled outlines.
 func (c *Canvas) RenderPath(path *Path, style Style, m Matrix) {
+	if style.StrokeProfile != nil {
+		renderStrokeProfile(c, path, style, m)
+		return
+	}
+
 	if c.layers == nil {
 		c.layers = map[int][]layer{}
 	}
 	path = path.Copy()
-	c.layers[c.zindex] = append(c.layers[c.zindex], layer{path: path, m: m, style: style})
+	bounds := path.Bounds()
+	if style.HasStroke() {
+		bounds = bounds.Expand(style.StrokeWidth / 2.0)
+	}
+	c.layers[c.zindex] = append(c.layers[c.zindex], layer{path: path, m: m, style: style, bounds: bounds, metadata: c.metadata})
+	c.index = nil
 }
 
 // RenderText renders a text object to the canvas using a transformation matrix.
@@ -755,7 +879,8 @@ func (c *Canvas) RenderText(text *Text, m Matrix) {
 	if c.layers == nil {
 		c.layers = map[int][]layer{}
 	}
-	c.layers[c.zindex] = append(c.layers[c.zindex], layer{text: text, m: m})
+	c.layers[c.zindex] = append(c.layers[c.zindex], layer{text: text, m: m, bounds: text.Bounds(), metadata: c.metadata})
+	c.index = nil
 }
 
 // RenderImage renders an image to the canvas using a transformation matrix.
@@ -763,7 +888,9 @@ func (c *Canvas) RenderImage(img image.Image, m Matrix) {
 	if c.layers == nil {
 		c.layers = map[int][]layer{}
 	}
-	c.layers[c.zindex] = append(c.layers[c.zindex], layer{img: img, m: m})
+	size := img.Bounds().Size()
+	c.layers[c.zindex] = append(c.layers[c.zindex], layer{img: img, m: m, resampling: c.resampling, bounds: Rect{0.0, 0.0, float64(size.X), float64(size.Y)}, metadata: c.metadata})
+	c.index = nil
 }
 
 // Empty return true if the canvas is empty.
@@ -774,6 +901,7 @@ func (c *Canvas) Empty() bool {
 // Reset empties the canvas.
 func (c *Canvas) Reset() {
 	c.layers = map[int][]layer{}
+	c.index = nil
 }
 
 // SetZIndex sets the z-index.
@@ -781,6 +909,30 @@ func (c *Canvas) SetZIndex(zindex int) {
 	c.zindex = zindex
 }
 
+// SetMetadata sets the metadata of the layers that follow, see Context.SetMetadata. The classes and data are copied so that later changes by the caller do not affect layers that were already drawn.
+func (c *Canvas) SetMetadata(meta Metadata) {
+	if meta.Empty() {
+		c.metadata = nil
+	} else {
+		meta.Classes = slices.Clone(meta.Classes)
+		meta.Data = maps.Clone(meta.Data)
+		c.metadata = &meta
+	}
+}
+
+// SetImageResampling sets the image resampling of the image layers that follow, see Context.SetImageResampling.
+func (c *Canvas) SetImageResampling(resampling ImageResampling) {
+	c.resampling = resampling
+}
+
+// LayerMetadata returns the metadata of a layer, see SetMetadata.
+func (c *Canvas) LayerMetadata(ref LayerRef) Metadata {
+	if ref.Index < 0 || len(c.layers[ref.ZIndex]) <= ref.Index || c.layers[ref.ZIndex][ref.Index].metadata == nil {
+		return Metadata{}
+	}
+	return *c.layers[ref.ZIndex][ref.Index].metadata
+}
+
 // Transform transforms the canvas.
 func (c *Canvas) Transform(m Matrix) {
 	for _, layers := range c.layers {
@@ -788,6 +940,7 @@ func (c *Canvas) Transform(m Matrix) {
 			layers[i].m = m.Mul(l.m)
 		}
 	}
+	c.index = nil
 }
 
 // Clip sets the canvas to the given rectangle.
@@ -797,40 +950,100 @@ func (c *Canvas) Clip(rect Rect) {
 	c.H = rect.H()
 }
 
-// Fit shrinks the canvas' size that so all elements fit with a given margin in millimeters.
+// buildIndex builds the spatial index over the bounds of all layers that have a non-empty area.
+func (c *Canvas) buildIndex() {
+	if c.index != nil {
+		return
+	}
+
+	zindices := []int{}
+	for zindex := range c.layers {
+		zindices = append(zindices, zindex)
+	}
+	sort.Ints(zindices)
+
+	rects := []Rect{}
+	c.indexRefs = c.indexRefs[:0]
+	for _, zindex := range zindices {
+		for i, l := range c.layers[zindex] {
+			// TODO: translate gradients
+			if !l.bounds.Empty() {
+				rects = append(rects, l.bounds.Transform(l.m))
+				c.indexRefs = append(c.indexRefs, LayerRef{zindex, i})
+			}
+		}
+	}
+	c.index = NewRTree(rects)
+}
+
+// Bounds returns the bounding box of all elements on the canvas in millimeters. Strokes are taken into account.
 func (c *Canvas) Bounds() Rect {
-	rect := Rect{}
-	// TODO: slow when we have many paths (see Graph example)
-	// TODO: translate gradients
-	for _, layers := range c.layers {
-		for _, l := range layers {
-			bounds := Rect{}
-			if l.path != nil {
-				bounds = l.path.Bounds()
-				if l.style.HasStroke() {
-					hw := l.style.StrokeWidth / 2.0
-					bounds.X0 -= hw
-					bounds.Y0 -= hw
-					bounds.X1 += hw
-					bounds.Y1 += hw
-				}
-			} else if l.text != nil {
-				bounds = l.text.Bounds()
-			} else if l.img != nil {
-				size := l.img.Bounds().Size()
-				bounds = Rect{0.0, 0.0, float64(size.X), float64(size.Y)}
+	c.buildIndex()
+	return c.index.Bounds()
+}
+
+// LayersAt returns the layers whose bounding box contains the point (x,y), ordered from bottom to top. It uses a spatial index that is rebuilt after the canvas is modified.
+func (c *Canvas) LayersAt(x, y float64) []LayerRef {
+	c.buildIndex()
+	return c.layerRefs(c.index.SearchPoint(Point{x, y}))
+}
+
+// LayersIn returns the layers whose bounding box touches or overlaps rect, ordered from bottom to top, see LayersAt.
+func (c *Canvas) LayersIn(rect Rect) []LayerRef {
+	c.buildIndex()
+	return c.layerRefs(c.index.Search(rect))
+}
+
+// LayerBounds returns the bounding box of a layer in millimeters, see Bounds.
+func (c *Canvas) LayerBounds(ref LayerRef) Rect {
+	if ref.Index < 0 || len(c.layers[ref.ZIndex]) <= ref.Index {
+		return Rect{}
+	}
+	l := c.layers[ref.ZIndex][ref.Index]
+	return l.bounds.Transform(l.m)
+}
+
+// HitTest returns the layers that are hit by the point (x,y) within the given tolerance in millimeters, ordered from top to bottom. Paths are hit by their filled area using their fill rule or by their stroke, while text and images are hit by their bounding box. Layer transformations and z-indices are respected.
+func (c *Canvas) HitTest(x, y, tolerance float64) []LayerRef {
+	pos := Point{x, y}
+	hit := func(p *Path, fillRule FillRule) bool {
+		return p.ContainsPoint(x, y, fillRule) || p.Distance(pos) <= tolerance
+	}
+
+	refs := c.LayersIn(Rect{x - tolerance, y - tolerance, x + tolerance, y + tolerance})
+	hits := []LayerRef{}
+	for k := len(refs) - 1; 0 <= k; k-- {
+		l := c.layers[refs[k].ZIndex][refs[k].Index]
+		if l.path == nil {
+			if hit(l.bounds.ToPath().Transform(l.m), NonZero) {
+				hits = append(hits, refs[k])
 			}
-			if !bounds.Empty() {
-				bounds = bounds.Transform(l.m)
-				if rect.Empty() {
-					rect = bounds
-				} else {
-					rect = rect.Add(bounds)
-				}
+			continue
+		}
+
+		if l.style.HasFill() && hit(l.path.Copy().Transform(l.m), l.style.FillRule) {
+			hits = append(hits, refs[k])
+		} else if l.style.HasStroke() {
+			stroke := l.path
+			if 0 < len(l.style.Dashes) {
+				dashOffset, dashes := ScaleDash(l.style.StrokeWidth, l.style.DashOffset, l.style.Dashes)
+				stroke = stroke.Dash(dashOffset, dashes...)
+			}
+			stroke = stroke.Stroke(l.style.StrokeWidth, l.style.StrokeCapper, l.style.StrokeJoiner, Tolerance)
+			if hit(stroke.Transform(l.m), NonZero) {
+				hits = append(hits, refs[k])
 			}
 		}
 	}
-	return rect
+	return hits
+}
+
+func (c *Canvas) layerRefs(is []int) []LayerRef {
+	refs := make([]LayerRef, len(is))
+	for j, i := range is {
+		refs[j] = c.indexRefs[i]
+	}
+	return refs
 }
 
 // Fit shrinks the canvas' size that so all elements fit with a given margin in millimeters.
@@ -848,7 +1061,7 @@ func (c *Canvas) RenderTo(r Renderer) {
 	c.RenderViewTo(r, Identity)
 }
 
-// RenderViewTo transforms and renders the accumulated canvas drawing operations to another renderer.
+// RenderViewTo transforms and renders the accumulated canvas drawing operations to another renderer. Layer metadata is passed to renderers that implement `SetMetadata(Metadata)`, and is reset afterwards. Likewise, the image resampling of image layers is passed to renderers that implement `SetImageResampling(ImageResampling)`.
 func (c *Canvas) RenderViewTo(r Renderer, view Matrix) {
 	zindices := []int{}
 	for zindex := range c.layers {
@@ -856,18 +1069,50 @@ func (c *Canvas) RenderViewTo(r Renderer, view Matrix) {
 	}
 	sort.Ints(zindices)
 
+	// pass metadata to renderers that support it only when it changes, so that renderers
+	// without metadata support or canvases without metadata are unaffected
+	setter, _ := r.(interface{ SetMetadata(Metadata) })
+	var metadata *Metadata
+	resampler, _ := r.(interface{ SetImageResampling(ImageResampling) })
+	var resampling ImageResampling
 	for _, zindex := range zindices {
 		for _, l := range c.layers[zindex] {
+			if setter != nil && l.metadata != metadata {
+				if l.metadata == nil {
+					setter.SetMetadata(Metadata{})
+				} else {
+					setter.SetMetadata(*l.metadata)
+				}
+				metadata = l.metadata
+			}
+
 			m := view.Mul(l.m)
 			if l.path != nil {
 				r.RenderPath(l.path, l.style, m)
 			} else if l.text != nil {
 				r.RenderText(l.text, m)
 			} else if l.img != nil {
+				if resampler != nil && l.resampling != resampling {
+					resampler.SetImageResampling(l.resampling)
+					resampling = l.resampling
+				}
 				r.RenderImage(l.img, m)
 			}
 		}
 	}
+	if metadata != nil {
+		setter.SetMetadata(Metadata{})
+	}
+	if resampling != BicubicResampling {
+		resampler.SetImageResampling(BicubicResampling)
+	}
+}
+
+// RenderAlongTo renders copies of the canvas to another renderer along a path, starting at offset and then every interval (in millimeters), see Path.Placements. The origin of the canvas is placed on the path and its x-axis follows the direction of the path.
+func (c *Canvas) RenderAlongTo(r Renderer, path *Path, offset, interval float64) {
+	for _, m := range path.Placements(offset, interval) {
+		c.RenderViewTo(r, m)
+	}
 }
 
 // Writer can write a canvas to a writer.
@@ -0,0 +1,1009 @@
+package canvas
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"sort"
+
+	"github.com/tdewolff/canvas/text"
+	"github.com/tdewolff/font"
+)
+
+// canvasEncodingVersion is the version of the binary and JSON encodings of a canvas, which is incremented for incompatible changes.
+const canvasEncodingVersion = 1
+
+// canvasBinaryMagic precedes the binary encoding of a canvas.
+const canvasBinaryMagic = "CNVS"
+
+// The encoded canvas refers to fonts, font faces, and metadata by their one-based index into the tables of canvasData, so that they are stored once and remain shared after decoding. An index of zero means none.
+type canvasData struct {
+	Version  int            `json:"version"`
+	Fonts    []fontData     `json:"fonts,omitempty"`
+	Faces    []fontFaceData `json:"faces,omitempty"`
+	Metadata []metadataData `json:"metadata,omitempty"`
+	Canvas   canvasLayers   `json:"canvas"`
+}
+
+type canvasLayers struct {
+	W      float64     `json:"width"`
+	H      float64     `json:"height"`
+	Layers []layerData `json:"layers,omitempty"`
+}
+
+type layerData struct {
+	ZIndex   int        `json:"zindex,omitempty"`
+	Metadata int        `json:"metadata,omitempty"`
+	M        Matrix     `json:"matrix"`
+	Path     []float64  `json:"path,omitempty"`
+	Style    *styleData `json:"style,omitempty"` // set for paths only
+	Text     *textData  `json:"text,omitempty"`
+	Image    []byte     `json:"image,omitempty"` // PNG
+
+	Resampling ImageResampling `json:"resampling,omitempty"` // set for images only
+}
+
+type metadataData struct {
+	ID      string      `json:"id,omitempty"`
+	Classes []string    `json:"classes,omitempty"`
+	Link    string      `json:"link,omitempty"`
+	Data    [][2]string `json:"data,omitempty"` // sorted by key
+}
+
+type paintData struct {
+	Color    color.RGBA    `json:"color"`
+	Gradient *gradientData `json:"gradient,omitempty"`
+	Pattern  *patternData  `json:"pattern,omitempty"`
+}
+
+type gradientData struct {
+	Type  string  `json:"type"` // linear or radial
+	Stops []Stop  `json:"stops,omitempty"`
+	Start Point   `json:"start"` // start point or first center
+	End   Point   `json:"end"`   // end point or second center
+	R0    float64 `json:"r0,omitempty"`
+	R1    float64 `json:"r1,omitempty"`
+}
+
+type patternData struct {
+	Type      string    `json:"type"` // line, cross, or shape hatch
+	Fill      paintData `json:"fill"`
+	Thickness float64   `json:"thickness,omitempty"`
+	Cell      Matrix    `json:"cell"`
+	Shape     []float64 `json:"shape,omitempty"`
+	Distance  float64   `json:"distance,omitempty"`
+}
+
+type styleData struct {
+	Fill        paintData  `json:"fill"`
+	Stroke      paintData  `json:"stroke"`
+	StrokeWidth float64    `json:"strokeWidth"`
+	Capper      string     `json:"capper,omitempty"`
+	Joiner      joinerData `json:"joiner"`
+	DashOffset  float64    `json:"dashOffset,omitempty"`
+	Dashes      []float64  `json:"dashes,omitempty"`
+	FillRule    FillRule   `json:"fillRule,omitempty"`
+}
+
+type joinerData struct {
+	Type  string  `json:"type,omitempty"`
+	Gap   string  `json:"gap,omitempty"` // for miter and arcs joiners
+	Limit float64 `json:"limit,omitempty"`
+}
+
+type fontData struct {
+	Hash       string    `json:"hash"` // hex encoded SHA-256 of data
+	Name       string    `json:"name"`
+	Style      FontStyle `json:"style"`
+	Variations string    `json:"variations,omitempty"`
+	Features   string    `json:"features,omitempty"`
+	Data       []byte    `json:"data,omitempty"` // SFNT, omitted if stored before with the same hash
+}
+
+type fontFaceData struct {
+	Font       int            `json:"font"`
+	Size       float64        `json:"size"`
+	Style      FontStyle      `json:"style"`
+	Variant    FontVariant    `json:"variant,omitempty"`
+	Fill       paintData      `json:"fill"`
+	Deco       []decoData     `json:"deco,omitempty"`
+	Hinting    font.Hinting   `json:"hinting,omitempty"`
+	FauxBold   float64        `json:"fauxBold,omitempty"`
+	FauxItalic float64        `json:"fauxItalic,omitempty"`
+	XOffset    int32          `json:"xOffset,omitempty"`
+	YOffset    int32          `json:"yOffset,omitempty"`
+	Language   string         `json:"language,omitempty"`
+	Script     text.Script    `json:"script,omitempty"`
+	Direction  text.Direction `json:"direction,omitempty"`
+	MmPerEm    float64        `json:"mmPerEm"`
+}
+
+type decoData struct {
+	Type    string    `json:"type"`
+	Fill    paintData `json:"fill"`
+	Outline float64   `json:"outline,omitempty"`
+	Width   float64   `json:"width,omitempty"` // for stroke only
+}
+
+type textData struct {
+	WritingMode     WritingMode     `json:"writingMode,omitempty"`
+	TextOrientation TextOrientation `json:"textOrientation,omitempty"`
+	Width           float64         `json:"width"`
+	Height          float64         `json:"height"`
+	Text            string          `json:"text"`
+	OverflowsX      bool            `json:"overflowsX,omitempty"`
+	OverflowsY      bool            `json:"overflowsY,omitempty"`
+	Lines           []textLineData  `json:"lines,omitempty"`
+}
+
+type textLineData struct {
+	Y     float64        `json:"y"`
+	Spans []textSpanData `json:"spans,omitempty"`
+}
+
+type textSpanData struct {
+	X         float64          `json:"x"`
+	Width     float64          `json:"width"`
+	Face      int              `json:"face"`
+	Text      string           `json:"text,omitempty"`
+	Glyphs    []glyphData      `json:"glyphs,omitempty"`
+	Direction text.Direction   `json:"direction,omitempty"`
+	Rotation  text.Rotation    `json:"rotation,omitempty"`
+	Level     int              `json:"level,omitempty"`
+	Objects   []textObjectData `json:"objects,omitempty"`
+}
+
+type glyphData struct {
+	ID       uint16      `json:"id"`
+	Cluster  uint32      `json:"cluster"`
+	XAdvance int32       `json:"xAdvance,omitempty"`
+	YAdvance int32       `json:"yAdvance,omitempty"`
+	XOffset  int32       `json:"xOffset,omitempty"`
+	YOffset  int32       `json:"yOffset,omitempty"`
+	Text     string      `json:"text,omitempty"`
+	Size     float64     `json:"size"`
+	Script   text.Script `json:"script,omitempty"`
+	Vertical bool        `json:"vertical,omitempty"`
+}
+
+type textObjectData struct {
+	Canvas canvasLayers  `json:"canvas"`
+	X      float64       `json:"x"`
+	Y      float64       `json:"y"`
+	Width  float64       `json:"width"`
+	Height float64       `json:"height"`
+	VAlign VerticalAlign `json:"valign,omitempty"`
+}
+
+// MarshalBinary encodes the canvas including all layers, z-indices, styles, gradients, hatch patterns, text, fonts, images, and metadata, so that it can be stored and later be decoded using UnmarshalBinary and rendered to any renderer. Fonts are stored once and referenced by their hash, and images are stored as PNG. It returns an error for hatch patterns with a custom hatcher, and custom gradients, patterns, cappers, joiners, or font decorators, as these cannot be serialized.
+func (c *Canvas) MarshalBinary() ([]byte, error) {
+	data, err := c.encode()
+	if err != nil {
+		return nil, err
+	}
+	b := &bytes.Buffer{}
+	b.WriteString(canvasBinaryMagic)
+	if err := gob.NewEncoder(b).Encode(data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a canvas encoded with MarshalBinary, replacing all layers.
+func (c *Canvas) UnmarshalBinary(b []byte) error {
+	if !bytes.HasPrefix(b, []byte(canvasBinaryMagic)) {
+		return errors.New("invalid canvas data")
+	}
+	data := &canvasData{}
+	if err := gob.NewDecoder(bytes.NewReader(b[len(canvasBinaryMagic):])).Decode(data); err != nil {
+		return err
+	}
+	return c.decode(data)
+}
+
+// MarshalJSON encodes the canvas as JSON, see MarshalBinary.
+func (c *Canvas) MarshalJSON() ([]byte, error) {
+	data, err := c.encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON decodes a canvas encoded with MarshalJSON, replacing all layers.
+func (c *Canvas) UnmarshalJSON(b []byte) error {
+	data := &canvasData{}
+	if err := json.Unmarshal(b, data); err != nil {
+		return err
+	}
+	return c.decode(data)
+}
+
+////////////////////////////////////////////////////////////////
+
+type canvasEncoder struct {
+	*canvasData
+	fonts     map[*Font]int
+	fontKeys  map[fontKey]int
+	fontFiles map[string]bool
+	faces     map[*FontFace]int
+	metadata  map[*Metadata]int
+}
+
+// fontKey identifies an encoded font by the hash of its data and its properties, so that the same font loaded more than once is stored once.
+type fontKey struct {
+	hash, name           string
+	style                FontStyle
+	variations, features string
+}
+
+func (c *Canvas) encode() (*canvasData, error) {
+	enc := &canvasEncoder{
+		canvasData: &canvasData{Version: canvasEncodingVersion},
+		fonts:      map[*Font]int{},
+		fontKeys:   map[fontKey]int{},
+		fontFiles:  map[string]bool{},
+		faces:      map[*FontFace]int{},
+		metadata:   map[*Metadata]int{},
+	}
+	var err error
+	if enc.Canvas, err = enc.encodeCanvas(c); err != nil {
+		return nil, err
+	}
+	return enc.canvasData, nil
+}
+
+func (enc *canvasEncoder) encodeCanvas(c *Canvas) (canvasLayers, error) {
+	zindices := []int{}
+	for zindex := range c.layers {
+		zindices = append(zindices, zindex)
+	}
+	sort.Ints(zindices)
+
+	data := canvasLayers{W: c.W, H: c.H}
+	for _, zindex := range zindices {
+		for _, l := range c.layers[zindex] {
+			ld := layerData{
+				ZIndex:   zindex,
+				Metadata: enc.encodeMetadata(l.metadata),
+				M:        l.m,
+			}
+			var err error
+			if l.path != nil {
+				ld.Path = l.path.d
+				if ld.Style, err = enc.encodeStyle(l.style); err != nil {
+					return canvasLayers{}, err
+				}
+			} else if l.text != nil {
+				if ld.Text, err = enc.encodeText(l.text); err != nil {
+					return canvasLayers{}, err
+				}
+			} else if l.img != nil {
+				b := &bytes.Buffer{}
+				if err := png.Encode(b, l.img); err != nil {
+					return canvasLayers{}, err
+				}
+				ld.Image = b.Bytes()
+				ld.Resampling = l.resampling
+			}
+			data.Layers = append(data.Layers, ld)
+		}
+	}
+	return data, nil
+}
+
+func (enc *canvasEncoder) encodeMetadata(meta *Metadata) int {
+	if meta == nil {
+		return 0
+	} else if i, ok := enc.metadata[meta]; ok {
+		return i
+	}
+
+	data := metadataData{
+		ID:      meta.ID,
+		Classes: meta.Classes,
+		Link:    meta.Link,
+	}
+	for key, val := range meta.Data {
+		data.Data = append(data.Data, [2]string{key, val})
+	}
+	sort.Slice(data.Data, func(i, j int) bool {
+		return data.Data[i][0] < data.Data[j][0]
+	})
+	enc.Metadata = append(enc.Metadata, data)
+	enc.metadata[meta] = len(enc.Metadata)
+	return len(enc.Metadata)
+}
+
+func (enc *canvasEncoder) encodeStyle(style Style) (*styleData, error) {
+	var err error
+	data := &styleData{
+		StrokeWidth: style.StrokeWidth,
+		DashOffset:  style.DashOffset,
+		Dashes:      style.Dashes,
+		FillRule:    style.FillRule,
+	}
+	if data.Fill, err = encodePaint(style.Fill); err != nil {
+		return nil, err
+	} else if data.Stroke, err = encodePaint(style.Stroke); err != nil {
+		return nil, err
+	} else if data.Capper, err = encodeCapper(style.StrokeCapper); err != nil {
+		return nil, err
+	} else if data.Joiner.Type, err = encodeJoiner(style.StrokeJoiner); err != nil {
+		return nil, err
+	}
+
+	var gap Joiner
+	switch joiner := style.StrokeJoiner.(type) {
+	case MiterJoiner:
+		gap, data.Joiner.Limit = joiner.GapJoiner, joiner.Limit
+	case ArcsJoiner:
+		gap, data.Joiner.Limit = joiner.GapJoiner, joiner.Limit
+	}
+	if data.Joiner.Gap, err = encodeJoiner(gap); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func encodeCapper(capper Capper) (string, error) {
+	switch capper.(type) {
+	case nil:
+		return "", nil
+	case ButtCapper:
+		return "butt", nil
+	case RoundCapper:
+		return "round", nil
+	case SquareCapper:
+		return "square", nil
+	}
+	return "", fmt.Errorf("cannot serialize capper %T", capper)
+}
+
+func encodeJoiner(joiner Joiner) (string, error) {
+	switch joiner.(type) {
+	case nil:
+		return "", nil
+	case BevelJoiner:
+		return "bevel", nil
+	case RoundJoiner:
+		return "round", nil
+	case MiterJoiner:
+		return "miter", nil
+	case ArcsJoiner:
+		return "arcs", nil
+	}
+	return "", fmt.Errorf("cannot serialize joiner %T", joiner)
+}
+
+func encodePaint(paint Paint) (paintData, error) {
+	data := paintData{Color: paint.Color}
+	switch gradient := paint.Gradient.(type) {
+	case nil:
+	case *LinearGradient:
+		data.Gradient = &gradientData{
+			Type:  "linear",
+			Stops: gradient.Grad,
+			Start: gradient.Start,
+			End:   gradient.End,
+		}
+	case *RadialGradient:
+		data.Gradient = &gradientData{
+			Type:  "radial",
+			Stops: gradient.Grad,
+			Start: gradient.C0,
+			End:   gradient.C1,
+			R0:    gradient.R0,
+			R1:    gradient.R1,
+		}
+	default:
+		return paintData{}, fmt.Errorf("cannot serialize gradient %T", gradient)
+	}
+
+	if paint.Pattern != nil {
+		hatch, ok := paint.Pattern.(*HatchPattern)
+		if !ok {
+			return paintData{}, fmt.Errorf("cannot serialize pattern %T", paint.Pattern)
+		}
+
+		fill, err := encodePaint(hatch.Fill)
+		if err != nil {
+			return paintData{}, err
+		}
+		data.Pattern = &patternData{
+			Fill:      fill,
+			Thickness: hatch.Thickness,
+			Cell:      hatch.cell,
+		}
+		switch hatch.kind {
+		case lineHatch:
+			data.Pattern.Type = "line"
+		case crossHatch:
+			data.Pattern.Type = "cross"
+		case shapeHatch:
+			data.Pattern.Type = "shape"
+			data.Pattern.Shape = hatch.shape.d
+			data.Pattern.Distance = hatch.distance
+		default:
+			return paintData{}, errors.New("cannot serialize hatch pattern with custom hatcher")
+		}
+	}
+	return data, nil
+}
+
+func (enc *canvasEncoder) encodeText(t *Text) (*textData, error) {
+	data := &textData{
+		WritingMode:     t.WritingMode,
+		TextOrientation: t.TextOrientation,
+		Width:           t.Width,
+		Height:          t.Height,
+		Text:            t.Text,
+		OverflowsX:      t.OverflowsX,
+		OverflowsY:      t.OverflowsY,
+	}
+	for _, line := range t.lines {
+		ld := textLineData{Y: line.y}
+		for _, span := range line.spans {
+			face, err := enc.encodeFontFace(span.Face)
+			if err != nil {
+				return nil, err
+			}
+			sd := textSpanData{
+				X:         span.X,
+				Width:     span.Width,
+				Face:      face,
+				Text:      span.Text,
+				Direction: span.Direction,
+				Rotation:  span.Rotation,
+				Level:     span.Level,
+			}
+			for _, glyph := range span.Glyphs {
+				sd.Glyphs = append(sd.Glyphs, glyphData{
+					ID:       glyph.ID,
+					Cluster:  glyph.Cluster,
+					XAdvance: glyph.XAdvance,
+					YAdvance: glyph.YAdvance,
+					XOffset:  glyph.XOffset,
+					YOffset:  glyph.YOffset,
+					Text:     glyph.Text,
+					Size:     glyph.Size,
+					Script:   glyph.Script,
+					Vertical: glyph.Vertical,
+				})
+			}
+			for _, obj := range span.Objects {
+				c, err := enc.encodeCanvas(obj.Canvas)
+				if err != nil {
+					return nil, err
+				}
+				sd.Objects = append(sd.Objects, textObjectData{
+					Canvas: c,
+					X:      obj.X,
+					Y:      obj.Y,
+					Width:  obj.Width,
+					Height: obj.Height,
+					VAlign: obj.VAlign,
+				})
+			}
+			ld.Spans = append(ld.Spans, sd)
+		}
+		data.Lines = append(data.Lines, ld)
+	}
+	return data, nil
+}
+
+func (enc *canvasEncoder) encodeFontFace(face *FontFace) (int, error) {
+	if face == nil {
+		return 0, nil
+	} else if i, ok := enc.faces[face]; ok {
+		return i, nil
+	}
+
+	fill, err := encodePaint(face.Fill)
+	if err != nil {
+		return 0, err
+	}
+	data := fontFaceData{
+		Font:       enc.encodeFont(face.Font),
+		Size:       face.Size,
+		Style:      face.Style,
+		Variant:    face.Variant,
+		Fill:       fill,
+		Hinting:    face.Hinting,
+		FauxBold:   face.FauxBold,
+		FauxItalic: face.FauxItalic,
+		XOffset:    face.XOffset,
+		YOffset:    face.YOffset,
+		Language:   face.Language,
+		Script:     face.Script,
+		Direction:  face.Direction,
+		MmPerEm:    face.MmPerEm,
+	}
+	for _, deco := range face.Deco {
+		dd, err := encodeFontDecorator(deco)
+		if err != nil {
+			return 0, err
+		}
+		data.Deco = append(data.Deco, dd)
+	}
+	enc.Faces = append(enc.Faces, data)
+	enc.faces[face] = len(enc.Faces)
+	return len(enc.Faces), nil
+}
+
+func (enc *canvasEncoder) encodeFont(f *Font) int {
+	if f == nil {
+		return 0
+	} else if i, ok := enc.fonts[f]; ok {
+		return i
+	}
+
+	b := writeSFNT(f.SFNT)
+	hash := sha256.Sum256(b)
+	key := fontKey{hex.EncodeToString(hash[:]), f.name, f.style, f.variations, f.features}
+	if i, ok := enc.fontKeys[key]; ok {
+		enc.fonts[f] = i
+		return i
+	}
+
+	data := fontData{
+		Hash:       key.hash,
+		Name:       f.name,
+		Style:      f.style,
+		Variations: f.variations,
+		Features:   f.features,
+	}
+	if !enc.fontFiles[key.hash] {
+		data.Data = b
+		enc.fontFiles[key.hash] = true
+	}
+	enc.Fonts = append(enc.Fonts, data)
+	enc.fonts[f] = len(enc.Fonts)
+	enc.fontKeys[key] = len(enc.Fonts)
+	return len(enc.Fonts)
+}
+
+// writeSFNT writes the font like SFNT.Write, but keeps the modification time of the head table instead of setting the current time, so that the output and its hash are deterministic.
+func writeSFNT(sfnt *font.SFNT) []byte {
+	b := sfnt.Write()
+	head := sfnt.Tables["head"]
+	if len(b) < 12 || len(head) < 36 {
+		return b
+	}
+
+	numTables := int(binary.BigEndian.Uint16(b[4:]))
+	for i := 0; i < numTables && 12+16*(i+1) <= len(b); i++ {
+		record := b[12+16*i : 12+16*(i+1)]
+		if string(record[:4]) != "head" {
+			continue
+		}
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		if uint32(len(b)) < offset+length || length < 36 {
+			break
+		}
+		table := b[offset : offset+length]
+		copy(table[28:36], head[28:36])
+
+		// recalculate checksums with a zero checksum adjustment
+		binary.BigEndian.PutUint32(table[8:], 0)
+		binary.BigEndian.PutUint32(record[4:], sfntChecksum(table))
+		binary.BigEndian.PutUint32(table[8:], 0xB1B0AFBA-sfntChecksum(b))
+		break
+	}
+	return b
+}
+
+// sfntChecksum returns the sum of the big-endian 32-bit words of b, padded with zeros.
+func sfntChecksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+func encodeFontDecorator(deco FontDecorator) (decoData, error) {
+	var data decoData
+	var fill Paint
+	switch deco := deco.(type) {
+	case underline:
+		data.Type, fill, data.Outline = "underline", deco.Fill, deco.Outline
+	case overline:
+		data.Type, fill, data.Outline = "overline", deco.Fill, deco.Outline
+	case strikethrough:
+		data.Type, fill = "strikethrough", deco.Fill
+	case doubleUnderline:
+		data.Type, fill, data.Outline = "doubleUnderline", deco.Fill, deco.Outline
+	case dottedUnderline:
+		data.Type, fill, data.Outline = "dottedUnderline", deco.Fill, deco.Outline
+	case dashedUnderline:
+		data.Type, fill, data.Outline = "dashedUnderline", deco.Fill, deco.Outline
+	case wavyUnderline:
+		data.Type, fill, data.Outline = "wavyUnderline", deco.Fill, deco.Outline
+	case sineUnderline:
+		data.Type, fill, data.Outline = "sineUnderline", deco.Fill, deco.Outline
+	case sawtoothUnderline:
+		data.Type, fill, data.Outline = "sawtoothUnderline", deco.Fill, deco.Outline
+	case fontStroke:
+		data.Type, fill, data.Width = "stroke", deco.Fill, deco.Width
+	default:
+		return decoData{}, fmt.Errorf("cannot serialize font decorator %T", deco)
+	}
+
+	var err error
+	data.Fill, err = encodePaint(fill)
+	return data, err
+}
+
+////////////////////////////////////////////////////////////////
+
+type canvasDecoder struct {
+	*canvasData
+	fonts    []*Font
+	faces    []*FontFace
+	metadata []*Metadata
+}
+
+func (c *Canvas) decode(data *canvasData) error {
+	if data.Version != canvasEncodingVersion {
+		return fmt.Errorf("unsupported canvas encoding version %d", data.Version)
+	}
+
+	dec := &canvasDecoder{canvasData: data}
+	files := map[string][]byte{}
+	for _, fd := range data.Fonts {
+		if len(fd.Data) == 0 {
+			if fd.Data = files[fd.Hash]; fd.Data == nil {
+				return fmt.Errorf("font '%s': missing data", fd.Name)
+			}
+		} else {
+			hash := sha256.Sum256(fd.Data)
+			if hex.EncodeToString(hash[:]) != fd.Hash {
+				return fmt.Errorf("font '%s': hash mismatch", fd.Name)
+			}
+			files[fd.Hash] = fd.Data
+		}
+		f, err := LoadFont(fd.Data, 0, fd.Style)
+		if err != nil {
+			return fmt.Errorf("font '%s': %w", fd.Name, err)
+		}
+		f.name = fd.Name
+		f.variations = fd.Variations
+		f.features = fd.Features
+		dec.fonts = append(dec.fonts, f)
+	}
+	for _, fd := range data.Faces {
+		face, err := dec.decodeFontFace(fd)
+		if err != nil {
+			return err
+		}
+		dec.faces = append(dec.faces, face)
+	}
+	for _, md := range data.Metadata {
+		meta := Metadata{
+			ID:      md.ID,
+			Classes: md.Classes,
+			Link:    md.Link,
+		}
+		if 0 < len(md.Data) {
+			meta.Data = make(map[string]string, len(md.Data))
+			for _, kv := range md.Data {
+				meta.Data[kv[0]] = kv[1]
+			}
+		}
+		dec.metadata = append(dec.metadata, &meta)
+	}
+
+	decoded, err := dec.decodeCanvas(data.Canvas)
+	if err != nil {
+		return err
+	}
+	*c = *decoded
+	return nil
+}
+
+func (dec *canvasDecoder) decodeCanvas(data canvasLayers) (*Canvas, error) {
+	c := New(data.W, data.H)
+	for _, ld := range data.Layers {
+		c.zindex = ld.ZIndex
+		if ld.Metadata < 0 || len(dec.metadata) < ld.Metadata {
+			return nil, errors.New("invalid metadata reference")
+		} else if ld.Metadata == 0 {
+			c.metadata = nil
+		} else {
+			c.metadata = dec.metadata[ld.Metadata-1]
+		}
+
+		if ld.Style != nil {
+			style, err := decodeStyle(*ld.Style)
+			if err != nil {
+				return nil, err
+			}
+			path, err := decodePath(ld.Path)
+			if err != nil {
+				return nil, err
+			}
+			c.RenderPath(path, style, ld.M)
+		} else if ld.Text != nil {
+			text, err := dec.decodeText(*ld.Text)
+			if err != nil {
+				return nil, err
+			}
+			c.RenderText(text, ld.M)
+		} else if ld.Image != nil {
+			img, err := png.Decode(bytes.NewReader(ld.Image))
+			if err != nil {
+				return nil, err
+			}
+			c.resampling = ld.Resampling
+			c.RenderImage(img, ld.M)
+		} else {
+			return nil, errors.New("invalid layer")
+		}
+	}
+	c.zindex = 0
+	c.metadata = nil
+	c.resampling = BicubicResampling
+	return c, nil
+}
+
+// decodePath returns the path of the given path data, after checking that it starts with a move command and that each command has its values and ends with its trailing command.
+func decodePath(d []float64) (*Path, error) {
+	for i := 0; i < len(d); {
+		cmd := d[i]
+		switch cmd {
+		case MoveToCmd, LineToCmd, QuadToCmd, CubeToCmd, ArcToCmd, CloseCmd:
+		default:
+			return nil, errors.New("invalid path")
+		}
+		n := cmdLen(cmd)
+		if i == 0 && cmd != MoveToCmd || len(d) < i+n || d[i+n-1] != cmd {
+			return nil, errors.New("invalid path")
+		}
+		i += n
+	}
+	return &Path{d}, nil
+}
+
+func decodeStyle(data styleData) (Style, error) {
+	style := Style{
+		StrokeWidth: data.StrokeWidth,
+		DashOffset:  data.DashOffset,
+		Dashes:      data.Dashes,
+		FillRule:    data.FillRule,
+	}
+	if style.Dashes == nil {
+		style.Dashes = []float64{}
+	}
+
+	var err error
+	if style.Fill, err = decodePaint(data.Fill); err != nil {
+		return Style{}, err
+	} else if style.Stroke, err = decodePaint(data.Stroke); err != nil {
+		return Style{}, err
+	}
+
+	switch data.Capper {
+	case "":
+	case "butt":
+		style.StrokeCapper = ButtCap
+	case "round":
+		style.StrokeCapper = RoundCap
+	case "square":
+		style.StrokeCapper = SquareCap
+	default:
+		return Style{}, fmt.Errorf("unknown capper '%s'", data.Capper)
+	}
+
+	gap, err := decodeJoiner(data.Joiner.Gap)
+	if err != nil {
+		return Style{}, err
+	}
+	switch data.Joiner.Type {
+	case "miter":
+		style.StrokeJoiner = MiterJoiner{gap, data.Joiner.Limit}
+	case "arcs":
+		style.StrokeJoiner = ArcsJoiner{gap, data.Joiner.Limit}
+	default:
+		if style.StrokeJoiner, err = decodeJoiner(data.Joiner.Type); err != nil {
+			return Style{}, err
+		}
+	}
+	return style, nil
+}
+
+func decodeJoiner(name string) (Joiner, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "bevel":
+		return BevelJoin, nil
+	case "round":
+		return RoundJoin, nil
+	}
+	return nil, fmt.Errorf("unknown joiner '%s'", name)
+}
+
+func decodePaint(data paintData) (Paint, error) {
+	paint := Paint{Color: data.Color}
+	if data.Gradient != nil {
+		grad := Grad(data.Gradient.Stops)
+		switch data.Gradient.Type {
+		case "linear":
+			paint.Gradient = grad.ToLinear(data.Gradient.Start, data.Gradient.End)
+		case "radial":
+			paint.Gradient = grad.ToRadial(data.Gradient.Start, data.Gradient.R0, data.Gradient.End, data.Gradient.R1)
+		default:
+			return Paint{}, fmt.Errorf("unknown gradient '%s'", data.Gradient.Type)
+		}
+	}
+	if data.Pattern != nil {
+		fill, err := decodePaint(data.Pattern.Fill)
+		if err != nil {
+			return Paint{}, err
+		}
+		switch data.Pattern.Type {
+		case "line":
+			paint.Pattern = newLineHatch(fill, data.Pattern.Thickness, data.Pattern.Cell)
+		case "cross":
+			paint.Pattern = newCrossHatch(fill, data.Pattern.Thickness, data.Pattern.Cell)
+		case "shape":
+			shape, err := decodePath(data.Pattern.Shape)
+			if err != nil {
+				return Paint{}, err
+			}
+			paint.Pattern = NewShapeHatch(fill, shape, data.Pattern.Distance, data.Pattern.Thickness)
+		default:
+			return Paint{}, fmt.Errorf("unknown pattern '%s'", data.Pattern.Type)
+		}
+	}
+	return paint, nil
+}
+
+func (dec *canvasDecoder) decodeFontFace(data fontFaceData) (*FontFace, error) {
+	if data.Font < 0 || len(dec.fonts) < data.Font {
+		return nil, errors.New("invalid font reference")
+	}
+
+	fill, err := decodePaint(data.Fill)
+	if err != nil {
+		return nil, err
+	}
+	face := &FontFace{
+		Size:       data.Size,
+		Style:      data.Style,
+		Variant:    data.Variant,
+		Fill:       fill,
+		Hinting:    data.Hinting,
+		FauxBold:   data.FauxBold,
+		FauxItalic: data.FauxItalic,
+		XOffset:    data.XOffset,
+		YOffset:    data.YOffset,
+		Language:   data.Language,
+		Script:     data.Script,
+		Direction:  data.Direction,
+		MmPerEm:    data.MmPerEm,
+	}
+	if data.Font != 0 {
+		face.Font = dec.fonts[data.Font-1]
+	}
+	for _, dd := range data.Deco {
+		deco, err := decodeFontDecorator(dd)
+		if err != nil {
+			return nil, err
+		}
+		face.Deco = append(face.Deco, deco)
+	}
+	return face, nil
+}
+
+func decodeFontDecorator(data decoData) (FontDecorator, error) {
+	fill, err := decodePaint(data.Fill)
+	if err != nil {
+		return nil, err
+	}
+	switch data.Type {
+	case "underline":
+		return underline{fill, data.Outline}, nil
+	case "overline":
+		return overline{fill, data.Outline}, nil
+	case "strikethrough":
+		return strikethrough{fill}, nil
+	case "doubleUnderline":
+		return doubleUnderline{fill, data.Outline}, nil
+	case "dottedUnderline":
+		return dottedUnderline{fill, data.Outline}, nil
+	case "dashedUnderline":
+		return dashedUnderline{fill, data.Outline}, nil
+	case "wavyUnderline":
+		return wavyUnderline{fill, data.Outline}, nil
+	case "sineUnderline":
+		return sineUnderline{fill, data.Outline}, nil
+	case "sawtoothUnderline":
+		return sawtoothUnderline{fill, data.Outline}, nil
+	case "stroke":
+		return fontStroke{fill, data.Width}, nil
+	}
+	return nil, fmt.Errorf("unknown font decorator '%s'", data.Type)
+}
+
+func (dec *canvasDecoder) decodeText(data textData) (*Text, error) {
+	t := &Text{
+		fonts:           map[*Font]bool{},
+		WritingMode:     data.WritingMode,
+		TextOrientation: data.TextOrientation,
+		Width:           data.Width,
+		Height:          data.Height,
+		Text:            data.Text,
+		OverflowsX:      data.OverflowsX,
+		OverflowsY:      data.OverflowsY,
+	}
+	for _, ld := range data.Lines {
+		l := line{y: ld.Y}
+		for _, sd := range ld.Spans {
+			if sd.Face < 0 || len(dec.faces) < sd.Face {
+				return nil, errors.New("invalid font face reference")
+			}
+			span := TextSpan{
+				X:         sd.X,
+				Width:     sd.Width,
+				Text:      sd.Text,
+				Direction: sd.Direction,
+				Rotation:  sd.Rotation,
+				Level:     sd.Level,
+			}
+			var sfnt *font.SFNT
+			if sd.Face != 0 {
+				span.Face = dec.faces[sd.Face-1]
+				if span.Face.Font != nil {
+					sfnt = span.Face.Font.SFNT
+					if len(sd.Objects) == 0 {
+						t.fonts[span.Face.Font] = true
+					}
+				}
+			}
+			for _, gd := range sd.Glyphs {
+				span.Glyphs = append(span.Glyphs, text.Glyph{
+					SFNT:     sfnt,
+					Size:     gd.Size,
+					Script:   gd.Script,
+					Vertical: gd.Vertical,
+					ID:       gd.ID,
+					Cluster:  gd.Cluster,
+					XAdvance: gd.XAdvance,
+					YAdvance: gd.YAdvance,
+					XOffset:  gd.XOffset,
+					YOffset:  gd.YOffset,
+					Text:     gd.Text,
+				})
+			}
+			for _, od := range sd.Objects {
+				c, err := dec.decodeCanvas(od.Canvas)
+				if err != nil {
+					return nil, err
+				}
+				span.Objects = append(span.Objects, TextSpanObject{
+					Canvas: c,
+					X:      od.X,
+					Y:      od.Y,
+					Width:  od.Width,
+					Height: od.Height,
+					VAlign: od.VAlign,
+				})
+			}
+			l.spans = append(l.spans, span)
+		}
+		t.lines = append(t.lines, l)
+	}
+	return t, nil
+}
@@ -0,0 +1,188 @@
+package canvas
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/font"
+	"github.com/tdewolff/test"
+)
+
+func TestCanvasEncoding(t *testing.T) {
+	family := NewFontFamily("dejavu-serif")
+	if err := family.LoadFontFile("resources/DejaVuSerif.ttf", FontRegular); err != nil {
+		test.Error(t, err)
+	}
+	face := family.Face(12.0*ptPerMm, Red, FontRegular, FontNormal, FontUnderline)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(1, 0, color.NRGBA{255, 0, 0, 128})
+
+	c := New(100, 100)
+	ctx := NewContext(c)
+	grad := NewLinearGradient(Point{0.0, 0.0}, Point{10.0, 0.0})
+	grad.Add(0.0, Red)
+	grad.Add(1.0, Blue)
+	ctx.SetFill(grad)
+	ctx.SetStrokeColor(Black)
+	ctx.SetStrokeWidth(0.5)
+	ctx.SetStrokeJoiner(ArcsClipJoin)
+	ctx.SetDashes(1.0, 2.0, 1.0)
+	ctx.DrawPath(10.0, 10.0, MustParseSVGPath("M0 0L10 0A5 5 0 0 1 10 10z"))
+
+	ctx.SetZIndex(-1)
+	ctx.SetMetadata(Metadata{ID: "a", Classes: []string{"b"}, Data: map[string]string{"c": "d", "e": "f"}})
+	ctx.SetFill(NewLineHatch(Green, 45.0, 2.0, 0.1))
+	ctx.SetStroke(NewRadialGradient(Point{0.0, 0.0}, 1.0, Point{1.0, 1.0}, 5.0))
+	ctx.SetDashes(0.0)
+	ctx.DrawPath(50.0, 50.0, Circle(5.0))
+	ctx.SetImageResampling(NearestResampling)
+	ctx.DrawImage(20.0, 20.0, img, DPMM(1.0))
+
+	ctx.SetZIndex(1)
+	ctx.SetMetadata(Metadata{})
+	ctx.DrawText(5.0, 5.0, NewTextLine(face, "test\nline", Left))
+	ctx.DrawText(5.0, 50.0, NewTextLine(face, "same font", Left))
+
+	b, err := c.MarshalBinary()
+	test.Error(t, err)
+	test.That(t, bytes.Count(b, []byte("DejaVu")) < 10) // font stored once
+
+	c2 := &Canvas{}
+	test.Error(t, c2.UnmarshalBinary(b))
+	b2, err := c2.MarshalBinary()
+	test.Error(t, err)
+	test.Bytes(t, b2, b)
+
+	j, err := c.MarshalJSON()
+	test.Error(t, err)
+	c3 := &Canvas{}
+	test.Error(t, c3.UnmarshalJSON(j))
+	b3, err := c3.MarshalBinary()
+	test.Error(t, err)
+	test.Bytes(t, b3, b)
+
+	test.Float(t, c3.W, 100.0)
+	test.T(t, len(c3.layers[-1]), 2)
+	test.T(t, len(c3.layers[0]), 1)
+	test.T(t, len(c3.layers[1]), 2)
+	test.T(t, c3.Bounds(), c.Bounds())
+	test.T(t, c3.LayerMetadata(LayerRef{-1, 1}), c.LayerMetadata(LayerRef{-1, 1}))
+	test.That(t, c3.layers[-1][0].metadata == c3.layers[-1][1].metadata, "metadata must be shared")
+	test.T(t, c3.layers[0][0].path, c.layers[0][0].path)
+	test.T(t, c3.layers[0][0].style.StrokeJoiner, ArcsClipJoin)
+	test.T(t, c3.layers[0][0].style.Fill.Gradient, c.layers[0][0].style.Fill.Gradient)
+	test.T(t, c3.layers[-1][1].img.At(1, 0), img.At(1, 0))
+	test.T(t, c3.layers[-1][1].resampling, NearestResampling)
+
+	text, text3 := c.layers[1][0].text, c3.layers[1][0].text
+	test.T(t, len(text3.Fonts()), 1)
+	test.That(t, text3.Fonts()[0] == c3.layers[1][1].text.Fonts()[0], "fonts must be shared")
+	test.T(t, text3.Fonts()[0].Name(), text.Fonts()[0].Name())
+	test.T(t, text3.Bounds(), text.Bounds())
+	r, r3 := New(100, 100), New(100, 100)
+	text.RenderTo(r, Identity, DefaultResolution)
+	text3.RenderTo(r3, Identity, DefaultResolution)
+	test.That(t, 0 < len(r.layers[0]))
+	test.T(t, len(r3.layers[0]), len(r.layers[0]))
+	for i := range r.layers[0] {
+		test.T(t, r3.layers[0][i].path, r.layers[0][i].path)
+	}
+
+	// stroke profiles are stored as outlines
+	c.Reset()
+	style := DefaultStyle
+	style.Stroke = Paint{Color: Red}
+	style.StrokeProfile = func(t float64) float64 { return t }
+	c.RenderPath(Rectangle(1.0, 1.0), style, Identity)
+	j, err = c.MarshalJSON()
+	test.Error(t, err)
+	c3 = &Canvas{}
+	test.Error(t, c3.UnmarshalJSON(j))
+	test.T(t, len(c3.layers[1]), 2)
+	test.T(t, c3.layers[1][1].path, c.layers[1][1].path)
+
+	// unserializable
+	c.Reset()
+	ctx.SetFill(NewHatchPattern(Black, 0.0, Identity, func(x0, y0, x1, y1 float64) *Path { return &Path{} }))
+	ctx.DrawPath(0.0, 0.0, Rectangle(1.0, 1.0))
+	_, err = c.MarshalBinary()
+	test.That(t, err != nil, "custom hatcher must not be serializable")
+
+	test.That(t, c2.UnmarshalBinary([]byte("invalid")) != nil)
+}
+
+func TestCanvasEncodingFontData(t *testing.T) {
+	serif := NewFontFamily("serif")
+	test.Error(t, serif.LoadFontFile("resources/DejaVuSerif.ttf", FontRegular))
+	serif2 := NewFontFamily("serif")
+	test.Error(t, serif2.LoadFontFile("resources/DejaVuSerif.ttf", FontRegular))
+	other := NewFontFamily("other")
+	test.Error(t, other.LoadFontFile("resources/DejaVuSerif.ttf", FontRegular))
+
+	c := New(100, 100)
+	ctx := NewContext(c)
+	ctx.DrawText(5.0, 5.0, NewTextLine(serif.Face(12.0), "serif", Left))
+	ctx.DrawText(5.0, 25.0, NewTextLine(serif2.Face(12.0), "same font", Left))
+	ctx.DrawText(5.0, 50.0, NewTextLine(other.Face(12.0), "other family", Left))
+
+	data, err := c.encode()
+	test.Error(t, err)
+	test.T(t, len(data.Fonts), 2)
+	test.T(t, data.Fonts[0].Hash, data.Fonts[1].Hash)
+	test.That(t, 0 < len(data.Fonts[0].Data), "font data must be stored")
+	test.T(t, len(data.Fonts[1].Data), 0)
+
+	j, err := c.MarshalJSON()
+	test.Error(t, err)
+	c2 := &Canvas{}
+	test.Error(t, c2.UnmarshalJSON(j))
+	fonts := c2.layers[0][2].text.Fonts()
+	test.T(t, len(fonts), 1)
+	test.T(t, fonts[0].Name(), "other")
+}
+
+func TestCanvasEncodingInvalidPath(t *testing.T) {
+	c := New(100, 100)
+	ctx := NewContext(c)
+	ctx.DrawPath(0.0, 0.0, MustParseSVGPath("M1 0L1 1"))
+	j, err := c.MarshalJSON()
+	test.Error(t, err)
+	test.That(t, bytes.Contains(j, []byte(`"path":[1,1,0,1,2,1,1,2]`)), "unexpected path encoding")
+
+	c.Reset()
+	ctx.SetFill(NewShapeHatch(Black, MustParseSVGPath("M1 0L1 1"), 2.0, 0.1))
+	ctx.DrawPath(0.0, 0.0, Rectangle(1.0, 1.0))
+	jShape, err := c.MarshalJSON()
+	test.Error(t, err)
+	test.That(t, bytes.Contains(jShape, []byte(`"shape":[1,1,0,1,2,1,1,2]`)), "unexpected shape encoding")
+
+	for _, d := range []string{
+		`[1,0,0,1,32,1]`,          // truncated close command
+		`[1,0,0,1,2,1,1]`,         // missing trailing command
+		`[1,0,0,1,2,1,1,4]`,       // wrong trailing command
+		`[2,1,1,2]`,               // no move command
+		`[1,0,0,1,5,1,1,5]`,       // unknown command
+		`[1,0,0,1,16,1,1,0,0,16]`, // truncated arc command
+	} {
+		c2 := &Canvas{}
+		err = c2.UnmarshalJSON(bytes.Replace(j, []byte(`[1,1,0,1,2,1,1,2]`), []byte(d), 1))
+		test.T(t, err, errors.New("invalid path"), d)
+		err = c2.UnmarshalJSON(bytes.Replace(jShape, []byte(`[1,1,0,1,2,1,1,2]`), []byte(d), 1))
+		test.T(t, err, errors.New("invalid path"), d)
+	}
+}
+
+func TestWriteSFNT(t *testing.T) {
+	f, err := LoadFontFile("resources/DejaVuSerif.ttf", FontRegular)
+	test.Error(t, err)
+
+	b := writeSFNT(f.SFNT)
+	sfnt, err := font.ParseSFNT(b, 0)
+	test.Error(t, err)
+	test.Bytes(t, sfnt.Tables["head"][28:36], f.SFNT.Tables["head"][28:36]) // modified
+	test.T(t, sfntChecksum(b), uint32(0xB1B0AFBA))
+}
@@ -65,6 +65,193 @@ func TestCanvas(t *testing.T) {
 	// TODO: test EPS when fully supported
 }
 
+func TestContextStrokeProfile(t *testing.T) {
+	c := New(100, 100)
+	ctx := NewContext(c)
+	ctx.SetFillColor(Red)
+	ctx.SetStrokeColor(Blue)
+	ctx.SetStrokeWidth(2.0)
+	ctx.SetStrokeProfile(func(t float64) float64 { return 1.0 - t })
+	ctx.DrawPath(0.0, 0.0, MustParseSVGPath("M0 0L10 0"))
+
+	layers := c.layers[0]
+	test.T(t, len(layers), 2)
+	test.T(t, layers[0].style.Fill, Paint{Color: Red})
+	test.T(t, layers[0].style.HasStroke(), false)
+	test.T(t, layers[1].path, MustParseSVGPath("M0 -1L10 0L0 1z"))
+	test.T(t, layers[1].style.Fill, Paint{Color: Blue})
+	test.T(t, layers[1].style.HasStroke(), false)
+}
+
+func TestCanvasStrokeProfile(t *testing.T) {
+	style := DefaultStyle
+	style.Stroke = Paint{Color: Blue}
+	style.StrokeWidth = 2.0
+	style.StrokeProfile = func(t float64) float64 { return 1.0 - t }
+	style.Dashes = []float64{1.0}
+
+	c := New(100, 100)
+	c.RenderPath(MustParseSVGPath("M0 0L10 0"), style, Identity)
+	c2 := New(100, 100)
+	c.RenderTo(c2)
+
+	layers := c2.layers[0]
+	test.T(t, len(layers), 2)
+	test.T(t, layers[0].style.HasStroke(), false)
+	test.T(t, layers[1].path, MustParseSVGPath("M0 -1L10 0L0 1z"))
+	test.T(t, layers[1].style.Fill, Paint{Color: Blue})
+	test.T(t, layers[1].style.HasStroke(), false)
+}
+
+func TestCanvasRenderAlongTo(t *testing.T) {
+	tick := New(1, 1)
+	ctx := NewContext(tick)
+	ctx.DrawPath(0.0, 0.0, MustParseSVGPath("M0 -1L0 1"))
+
+	c := New(100, 100)
+	tick.RenderAlongTo(c, MustParseSVGPath("M0 0L10 0L10 10"), 5.0, 10.0)
+
+	layers := c.layers[0]
+	test.T(t, len(layers), 2)
+	test.T(t, layers[0].path.Copy().Transform(layers[0].m), MustParseSVGPath("M5 -1L5 1"))
+	test.T(t, layers[1].path.Copy().Transform(layers[1].m), MustParseSVGPath("M11 5L9 5"))
+}
+
+func TestCanvasLayers(t *testing.T) {
+	c := New(100, 100)
+	test.T(t, c.Bounds(), Rect{})
+	test.T(t, len(c.LayersAt(5.0, 5.0)), 0)
+
+	ctx := NewContext(c)
+	ctx.SetStrokeColor(Transparent)
+	ctx.DrawPath(0.0, 0.0, Rectangle(10.0, 10.0))
+	ctx.DrawPath(5.0, 5.0, Rectangle(10.0, 10.0))
+	ctx.DrawPath(0.0, 20.0, MustParseSVGPath("L10 0")) // no area
+	c.SetZIndex(-1)
+	ctx.DrawPath(20.0, 0.0, Rectangle(10.0, 10.0))
+	test.T(t, c.Bounds(), Rect{0.0, 0.0, 30.0, 15.0})
+	test.T(t, c.LayersAt(7.0, 7.0), []LayerRef{{0, 0}, {0, 1}})
+	test.T(t, c.LayersAt(25.0, 5.0), []LayerRef{{-1, 0}})
+	test.T(t, len(c.LayersAt(5.0, 20.0)), 0)
+	test.T(t, c.LayersIn(Rect{12.0, 0.0, 22.0, 6.0}), []LayerRef{{-1, 0}, {0, 1}})
+	test.T(t, c.LayerBounds(LayerRef{0, 1}), Rect{5.0, 5.0, 15.0, 15.0})
+
+	ctx.SetStrokeColor(Black)
+	ctx.SetStrokeWidth(2.0)
+	ctx.DrawPath(0.0, 20.0, MustParseSVGPath("L10 0"))
+	test.T(t, c.Bounds(), Rect{-1.0, 0.0, 30.0, 21.0})
+	test.T(t, c.LayersAt(5.0, 20.5), []LayerRef{{-1, 1}})
+
+	c.Transform(Identity.Translate(100.0, 0.0))
+	test.T(t, c.LayersAt(107.0, 7.0), []LayerRef{{0, 0}, {0, 1}})
+}
+
+func TestCanvasHitTest(t *testing.T) {
+	c := New(100, 100)
+	ctx := NewContext(c)
+	ctx.SetFillColor(Red)
+	ctx.SetStrokeColor(Transparent)
+	ctx.DrawPath(0.0, 0.0, MustParseSVGPath("L10 0L10 10L0 10zM2 2L2 8L8 8L8 2z")) // with hole
+	ctx.DrawPath(5.0, 5.0, Rectangle(10.0, 10.0))
+
+	ctx.SetFillColor(Transparent)
+	ctx.SetStrokeColor(Black)
+	ctx.SetStrokeWidth(2.0)
+	ctx.DrawPath(0.0, 20.0, MustParseSVGPath("L10 0"))
+
+	ctx.Push()
+	ctx.SetFillColor(Blue)
+	ctx.SetStrokeColor(Transparent)
+	ctx.Rotate(90.0)
+	ctx.DrawPath(30.0, -10.0, Rectangle(10.0, 5.0)) // at (5,30)-(10,40)
+	ctx.Pop()
+
+	test.T(t, c.HitTest(1.0, 1.0, 0.0), []LayerRef{{0, 0}})
+	test.T(t, c.HitTest(4.0, 4.0, 0.0), []LayerRef{})
+	test.T(t, c.HitTest(4.0, 3.0, 1.5), []LayerRef{{0, 0}})
+	test.T(t, c.HitTest(9.0, 9.0, 0.0), []LayerRef{{0, 1}, {0, 0}})
+	test.T(t, c.HitTest(5.0, 20.5, 0.0), []LayerRef{{0, 2}})
+	test.T(t, c.HitTest(5.0, 22.0, 0.0), []LayerRef{})
+	test.T(t, c.HitTest(5.0, 22.0, 1.5), []LayerRef{{0, 2}})
+	test.T(t, c.HitTest(7.0, 35.0, 0.0), []LayerRef{{0, 3}})
+	test.T(t, c.HitTest(12.0, 35.0, 0.0), []LayerRef{})
+
+	ctx.DrawImage(50.0, 50.0, image.NewRGBA(image.Rect(0, 0, 10, 10)), DPMM(1.0))
+	test.T(t, c.HitTest(55.0, 55.0, 0.0), []LayerRef{{0, 4}})
+	test.T(t, c.HitTest(61.0, 55.0, 0.0), []LayerRef{})
+}
+
+type metadataRenderer struct {
+	*Canvas
+	metadata []Metadata
+}
+
+func (r *metadataRenderer) SetMetadata(meta Metadata) {
+	r.metadata = append(r.metadata, meta)
+}
+
+func TestCanvasMetadata(t *testing.T) {
+	meta := Metadata{ID: "a", Classes: []string{"b"}, Link: "#c", Data: map[string]string{"d": "e"}}
+
+	c := New(100, 100)
+	ctx := NewContext(c)
+	ctx.SetMetadata(meta)
+	ctx.DrawPath(0.0, 0.0, Rectangle(10.0, 10.0))
+	ctx.DrawPath(20.0, 0.0, Rectangle(10.0, 10.0))
+	ctx.SetMetadata(Metadata{Data: map[string]string{}})
+	ctx.DrawPath(40.0, 0.0, Rectangle(10.0, 10.0))
+
+	test.T(t, c.LayerMetadata(LayerRef{0, 0}), meta)
+	test.T(t, c.LayerMetadata(LayerRef{0, 1}), meta)
+	test.T(t, c.LayerMetadata(LayerRef{0, 2}).Empty(), true)
+	test.T(t, c.LayerMetadata(LayerRef{1, 0}).Empty(), true)
+
+	// changing the caller's metadata afterwards does not affect drawn layers
+	classes, data := meta.Classes, meta.Data
+	meta.Classes, meta.Data = []string{"b"}, map[string]string{"d": "e"}
+	classes[0] = "x"
+	data["d"] = "x"
+	data["y"] = "z"
+	test.T(t, c.LayerMetadata(LayerRef{0, 0}), meta)
+
+	r := &metadataRenderer{Canvas: New(100, 100)}
+	c.RenderTo(r)
+	test.T(t, r.metadata, []Metadata{meta, {}})
+}
+
+type resamplingRenderer struct {
+	*Canvas
+	resamplings []ImageResampling
+}
+
+func (r *resamplingRenderer) SetImageResampling(resampling ImageResampling) {
+	r.resamplings = append(r.resamplings, resampling)
+}
+
+func TestCanvasImageResampling(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+
+	c := New(100, 100)
+	ctx := NewContext(c)
+	ctx.DrawImage(0.0, 0.0, img, DPMM(1.0))
+	ctx.Push()
+	ctx.SetImageResampling(NearestResampling)
+	ctx.DrawImage(10.0, 0.0, img, DPMM(1.0))
+	ctx.FitImage(img, Rect{20.0, 0.0, 30.0, 10.0}, ImageFill)
+	ctx.Pop()
+	ctx.DrawImage(40.0, 0.0, img, DPMM(1.0))
+
+	test.T(t, c.layers[0][0].resampling, BicubicResampling)
+	test.T(t, c.layers[0][1].resampling, NearestResampling)
+	test.T(t, c.layers[0][2].resampling, NearestResampling)
+	test.T(t, c.layers[0][3].resampling, BicubicResampling)
+
+	r := &resamplingRenderer{Canvas: New(100, 100)}
+	c.RenderTo(r)
+	test.T(t, r.resamplings, []ImageResampling{NearestResampling, BicubicResampling})
+	test.String(t, LanczosResampling.String(), "Lanczos")
+}
+
 func TestCanvasFit(t *testing.T) {
 	c := New(100, 100)
 	c.Fit(10)
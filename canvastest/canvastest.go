@@ -0,0 +1,256 @@
+// Package canvastest provides visual comparison of canvases for testing, so that tests can compare rasterized output perceptually instead of byte-by-byte. Small changes such as a different anti-aliasing are accepted within the tolerances of Options, while real changes are reported together with a diff mask image and a structural diff of the display lists.
+package canvastest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/renderers/rasterizer"
+)
+
+// Options are the rasterization settings and the tolerances that decide whether two images match.
+type Options struct {
+	Resolution canvas.Resolution
+	ColorSpace canvas.ColorSpace
+
+	Tolerance    float64 // maximum per-pixel difference in [0,1] of any color channel that is considered equal
+	MaxDiffRatio float64 // maximum ratio of pixels that differ by more than Tolerance
+	MinSSIM      float64 // minimum structural similarity index in [-1,1]
+
+	Update bool // write the golden file instead of comparing, see AssertGolden
+}
+
+// DefaultOptions are the default options, which accept anti-aliasing differences but reject any visible change.
+var DefaultOptions = Options{
+	Resolution:   canvas.DPMM(5.0),
+	ColorSpace:   canvas.DefaultColorSpace,
+	Tolerance:    0.25,
+	MaxDiffRatio: 0.01,
+	MinSSIM:      0.98,
+}
+
+// Result is the result of comparing two images.
+type Result struct {
+	Width, Height int
+	DiffPixels    int     // number of pixels that differ by more than Tolerance
+	MaxDiff       float64 // maximum per-pixel difference in [0,1]
+	MeanDiff      float64 // mean per-pixel difference in [0,1]
+	SSIM          float64 // mean structural similarity index of the luminance in [-1,1], where 1 is identical
+	Match         bool    // true if the images match within the tolerances
+
+	// Mask shows the first image in faded gray, where pixels that differ within the tolerance are yellow and pixels that differ by more than the tolerance are red.
+	Mask *image.RGBA
+}
+
+// DiffRatio returns the ratio of pixels that differ by more than the tolerance.
+func (res Result) DiffRatio() float64 {
+	if res.Width == 0 || res.Height == 0 {
+		return 0.0
+	}
+	return float64(res.DiffPixels) / float64(res.Width*res.Height)
+}
+
+func (res Result) String() string {
+	return fmt.Sprintf("%dx%d: %d different pixels (%.3g%%), max diff %.3g, mean diff %.3g, SSIM %.5g", res.Width, res.Height, res.DiffPixels, 100.0*res.DiffRatio(), res.MaxDiff, res.MeanDiff, res.SSIM)
+}
+
+// Draw rasterizes a canvas using the resolution and color space of the options.
+func Draw(c *canvas.Canvas, opts *Options) *image.RGBA {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	return rasterizer.Draw(c, opts.Resolution, opts.ColorSpace)
+}
+
+// Compare rasterizes two canvases and compares them, see CompareImages.
+func Compare(a, b *canvas.Canvas, opts *Options) Result {
+	return CompareImages(Draw(a, opts), Draw(b, opts), opts)
+}
+
+// CompareImages compares two images perceptually. Pixels are compared after compositing them on a white background, and images of different sizes are compared over the largest size where pixels outside an image are white.
+func CompareImages(a, b image.Image, opts *Options) Result {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+
+	boundsA, boundsB := a.Bounds(), b.Bounds()
+	w := max(boundsA.Dx(), boundsB.Dx())
+	h := max(boundsA.Dy(), boundsB.Dy())
+	res := Result{
+		Width:  w,
+		Height: h,
+		Mask:   image.NewRGBA(image.Rect(0, 0, w, h)),
+	}
+
+	lumA := make([]float64, w*h)
+	lumB := make([]float64, w*h)
+	sumDiff := 0.0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ca := whitePixel(a, boundsA.Min.X+x, boundsA.Min.Y+y)
+			cb := whitePixel(b, boundsB.Min.X+x, boundsB.Min.Y+y)
+			lumA[y*w+x] = luminance(ca)
+			lumB[y*w+x] = luminance(cb)
+
+			diff := math.Max(math.Abs(ca[0]-cb[0]), math.Max(math.Abs(ca[1]-cb[1]), math.Abs(ca[2]-cb[2])))
+			sumDiff += diff
+			res.MaxDiff = math.Max(res.MaxDiff, diff)
+
+			gray := uint8(191.0 + 64.0*lumA[y*w+x] + 0.5)
+			mask := color.RGBA{gray, gray, gray, 255}
+			if opts.Tolerance < diff {
+				res.DiffPixels++
+				mask = color.RGBA{255, 0, 0, 255}
+			} else if 0.0 < diff {
+				mask = color.RGBA{255, 224, 0, 255}
+			}
+			res.Mask.SetRGBA(x, y, mask)
+		}
+	}
+	if 0 < w*h {
+		res.MeanDiff = sumDiff / float64(w*h)
+	}
+	res.SSIM = ssim(lumA, lumB, w, h)
+	res.Match = res.DiffRatio() <= opts.MaxDiffRatio && opts.MinSSIM <= res.SSIM
+	return res
+}
+
+// whitePixel returns the non-premultiplied RGB values in [0,1] of a pixel composited on a white background, pixels outside the image are white.
+func whitePixel(img image.Image, x, y int) [3]float64 {
+	if !(image.Point{x, y}).In(img.Bounds()) {
+		return [3]float64{1.0, 1.0, 1.0}
+	}
+	r, g, b, a := img.At(x, y).RGBA()
+	white := float64(0xffff - a)
+	return [3]float64{
+		(float64(r) + white) / 0xffff,
+		(float64(g) + white) / 0xffff,
+		(float64(b) + white) / 0xffff,
+	}
+}
+
+// luminance returns the relative luminance of an RGB color, see ITU-R BT.709.
+func luminance(c [3]float64) float64 {
+	return 0.2126*c[0] + 0.7152*c[1] + 0.0722*c[2]
+}
+
+// ssimWindow and ssimStride are the size and step of the sliding windows for SSIM.
+const ssimWindow = 8
+const ssimStride = 4
+
+// ssim returns the mean structural similarity index over sliding windows of the luminance of two images. See Z. Wang, et al., "Image quality assessment: From error visibility to structural similarity", IEEE Transactions on Image Processing, 2004, DOI: 10.1109/TIP.2003.819861.
+func ssim(a, b []float64, w, h int) float64 {
+	const c1 = 0.01 * 0.01 // (k1*L)^2 with L=1
+	const c2 = 0.03 * 0.03 // (k2*L)^2 with L=1
+	if w == 0 || h == 0 {
+		return 1.0
+	}
+
+	ww, wh := min(ssimWindow, w), min(ssimWindow, h)
+	sum, n := 0.0, 0
+	for y0 := 0; y0+wh <= h; y0 += ssimStride {
+		for x0 := 0; x0+ww <= w; x0 += ssimStride {
+			var meanA, meanB float64
+			for y := y0; y < y0+wh; y++ {
+				for x := x0; x < x0+ww; x++ {
+					meanA += a[y*w+x]
+					meanB += b[y*w+x]
+				}
+			}
+			size := float64(ww * wh)
+			meanA /= size
+			meanB /= size
+
+			var varA, varB, cov float64
+			for y := y0; y < y0+wh; y++ {
+				for x := x0; x < x0+ww; x++ {
+					da, db := a[y*w+x]-meanA, b[y*w+x]-meanB
+					varA += da * da
+					varB += db * db
+					cov += da * db
+				}
+			}
+			varA /= size - 1.0
+			varB /= size - 1.0
+			cov /= size - 1.0
+			if size == 1.0 {
+				varA, varB, cov = 0.0, 0.0, 0.0
+			}
+
+			sum += (2.0*meanA*meanB + c1) * (2.0*cov + c2) / ((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+////////////////////////////////////////////////////////////////
+
+// AssertEqual fails the test if the two canvases do not match visually within the tolerances of the options. On failure it reports the comparison result and the structural diff of the display lists.
+func AssertEqual(t testing.TB, a, b *canvas.Canvas, opts *Options) {
+	t.Helper()
+	if res := Compare(a, b, opts); !res.Match {
+		msg := &strings.Builder{}
+		fmt.Fprintf(msg, "canvases do not match: %v", res)
+		for _, diff := range DiffDisplayLists(a, b) {
+			fmt.Fprintf(msg, "\n  %v", diff)
+		}
+		t.Error(msg.String())
+	}
+}
+
+// AssertGolden fails the test if the canvas does not match the golden PNG file visually within the tolerances of the options. When Options.Update is set, the golden file is written instead. On failure, the diff mask is written next to the golden file with the .diff.png extension.
+func AssertGolden(t testing.TB, c *canvas.Canvas, filename string, opts *Options) {
+	t.Helper()
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+
+	img := Draw(c, opts)
+	if opts.Update {
+		if err := writePNG(filename, img); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	golden, err := readPNG(filename)
+	if err != nil {
+		t.Fatalf("failed to read golden file, set Options.Update to create it: %v", err)
+	}
+	if res := CompareImages(img, golden, opts); !res.Match {
+		diffname := strings.TrimSuffix(filename, ".png") + ".diff.png"
+		if err := writePNG(diffname, res.Mask); err != nil {
+			t.Errorf("failed to write diff mask: %v", err)
+		}
+		t.Errorf("canvas does not match golden file '%s': %v, see '%s'", filename, res, diffname)
+	}
+}
+
+func readPNG(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(filename string, img image.Image) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
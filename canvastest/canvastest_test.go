@@ -0,0 +1,105 @@
+package canvastest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func drawShapes(dx float64, fill canvas.Paint) *canvas.Canvas {
+	c := canvas.New(20.0, 20.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFill(fill)
+	ctx.DrawPath(2.0+dx, 2.0, canvas.Circle(5.0).Translate(5.0, 5.0))
+	ctx.SetFillColor(canvas.Blue)
+	ctx.DrawPath(10.0, 10.0, canvas.Rectangle(8.0, 8.0))
+	return c
+}
+
+func TestCompare(t *testing.T) {
+	a := drawShapes(0.0, canvas.Paint{Color: canvas.Red})
+
+	res := Compare(a, drawShapes(0.0, canvas.Paint{Color: canvas.Red}), nil)
+	test.T(t, res.Match, true)
+	test.T(t, res.DiffPixels, 0)
+	test.Float(t, res.SSIM, 1.0)
+	test.T(t, res.Mask.Bounds().Dx(), 100)
+
+	// subpixel shift only changes anti-aliasing
+	res = Compare(a, drawShapes(0.04, canvas.Paint{Color: canvas.Red}), nil)
+	test.T(t, res.Match, true)
+	test.That(t, 0.0 < res.MaxDiff, "expected anti-aliasing differences")
+
+	res = Compare(a, drawShapes(2.0, canvas.Paint{Color: canvas.Red}), nil)
+	test.T(t, res.Match, false)
+	test.That(t, res.SSIM < 0.98, "expected lower SSIM:", res.SSIM)
+
+	res = Compare(a, drawShapes(0.0, canvas.Paint{Color: canvas.Green}), nil)
+	test.T(t, res.Match, false)
+	test.T(t, res.Mask.RGBAAt(35, 35).R, uint8(255))
+}
+
+func TestDiffDisplayLists(t *testing.T) {
+	a := drawShapes(0.0, canvas.Paint{Color: canvas.Red})
+	test.T(t, len(DiffDisplayLists(a, a)), 0)
+
+	diffs := DiffDisplayLists(a, drawShapes(0.0, canvas.Paint{Color: canvas.Green}))
+	test.T(t, len(diffs), 1)
+	test.T(t, diffs[0].Op, Changed)
+	test.T(t, diffs[0].IndexA, 0)
+	test.T(t, diffs[0].Properties, []string{"fill"})
+
+	b := drawShapes(0.0, canvas.Paint{Color: canvas.Red})
+	ctx := canvas.NewContext(b)
+	ctx.SetZIndex(-1)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(1.0, 1.0))
+	diffs = DiffDisplayLists(a, b)
+	test.T(t, len(diffs), 1)
+	test.T(t, diffs[0].Op, Added)
+	test.T(t, diffs[0].IndexB, 0)
+	test.T(t, diffs[0].String(), "+ 0: path M0 0L1 0L1 1L0 1z")
+
+	diffs = DiffDisplayLists(b, a)
+	test.T(t, len(diffs), 1)
+	test.T(t, diffs[0].Op, Removed)
+}
+
+func TestAssertGolden(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "shapes.png")
+	c := drawShapes(0.0, canvas.Paint{Color: canvas.Red})
+
+	opts := DefaultOptions
+	opts.Update = true
+	AssertGolden(t, c, filename, &opts)
+	AssertGolden(t, drawShapes(0.04, canvas.Paint{Color: canvas.Red}), filename, nil)
+
+	tb := &recordingTB{TB: t}
+	AssertGolden(tb, drawShapes(0.0, canvas.Paint{Color: canvas.Green}), filename, nil)
+	test.That(t, strings.Contains(tb.msg, "does not match golden file"), tb.msg)
+	_, err := os.Stat(filepath.Join(filepath.Dir(filename), "shapes.diff.png"))
+	test.Error(t, err)
+
+	tb = &recordingTB{TB: t}
+	AssertEqual(tb, c, drawShapes(0.0, canvas.Paint{Color: canvas.Green}), nil)
+	test.That(t, strings.Contains(tb.msg, "~ 0→0: path"), tb.msg)
+}
+
+type recordingTB struct {
+	testing.TB
+	msg string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Error(args ...any) {
+	tb.msg += strings.TrimSpace(fmt.Sprintln(args...))
+}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.msg += fmt.Sprintf(format, args...)
+}
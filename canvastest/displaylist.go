@@ -0,0 +1,254 @@
+package canvastest
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"strings"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Item is a drawing operation of a display list, which is either a path, text, or image.
+type Item struct {
+	Path   *canvas.Path
+	Style  canvas.Style // only for paths
+	Text   *canvas.Text
+	Image  image.Image
+	Matrix canvas.Matrix
+}
+
+func (item Item) String() string {
+	if item.Path != nil {
+		return fmt.Sprintf("path %v", item.Path)
+	} else if item.Text != nil {
+		return fmt.Sprintf("text %q", item.Text.Text)
+	}
+	size := item.Image.Bounds().Size()
+	return fmt.Sprintf("image %dx%d", size.X, size.Y)
+}
+
+// differences returns the properties in which two items differ, or nil if they are equal.
+func (item Item) differences(other Item) []string {
+	if (item.Path != nil) != (other.Path != nil) || (item.Text != nil) != (other.Text != nil) {
+		return []string{"type"}
+	}
+
+	var diffs []string
+	if item.Path != nil {
+		if !item.Path.Equals(other.Path) {
+			diffs = append(diffs, "path")
+		}
+		diffs = append(diffs, styleDifferences(item.Style, other.Style)...)
+	} else if item.Text != nil {
+		if item.Text.Text != other.Text.Text {
+			diffs = append(diffs, "text")
+		} else if !item.Text.Bounds().Equals(other.Text.Bounds()) {
+			diffs = append(diffs, "text layout")
+		}
+	} else if !imageEqual(item.Image, other.Image) {
+		diffs = append(diffs, "image")
+	}
+	if !item.Matrix.Equals(other.Matrix) {
+		diffs = append(diffs, "matrix")
+	}
+	return diffs
+}
+
+func styleDifferences(a, b canvas.Style) []string {
+	var diffs []string
+	if !paintEqual(a.Fill, b.Fill) {
+		diffs = append(diffs, "fill")
+	}
+	if !paintEqual(a.Stroke, b.Stroke) {
+		diffs = append(diffs, "stroke")
+	}
+	if a.HasStroke() || b.HasStroke() {
+		if !canvas.Equal(a.StrokeWidth, b.StrokeWidth) {
+			diffs = append(diffs, "stroke width")
+		}
+		if !reflect.DeepEqual(a.StrokeCapper, b.StrokeCapper) {
+			diffs = append(diffs, "stroke capper")
+		}
+		if !reflect.DeepEqual(a.StrokeJoiner, b.StrokeJoiner) {
+			diffs = append(diffs, "stroke joiner")
+		}
+		if (a.StrokeProfile != nil) != (b.StrokeProfile != nil) {
+			diffs = append(diffs, "stroke profile")
+		}
+		if !canvas.Equal(a.DashOffset, b.DashOffset) || !floatsEqual(a.Dashes, b.Dashes) {
+			diffs = append(diffs, "dashes")
+		}
+	}
+	if a.FillRule != b.FillRule {
+		diffs = append(diffs, "fill rule")
+	}
+	return diffs
+}
+
+func paintEqual(a, b canvas.Paint) bool {
+	return !a.Has() && !b.Has() || a.Equal(b)
+}
+
+func floatsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !canvas.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func imageEqual(a, b image.Image) bool {
+	bounds := a.Bounds()
+	if bounds.Size() != b.Bounds().Size() {
+		return false
+	}
+	offset := b.Bounds().Min.Sub(bounds.Min)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r0, g0, b0, a0 := a.At(x, y).RGBA()
+			r1, g1, b1, a1 := b.At(x+offset.X, y+offset.Y).RGBA()
+			if r0 != r1 || g0 != g1 || b0 != b1 || a0 != a1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// displayList records the drawing operations of a canvas.
+type displayList struct {
+	w, h  float64
+	items []Item
+}
+
+func (r *displayList) Size() (float64, float64) {
+	return r.w, r.h
+}
+
+func (r *displayList) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	r.items = append(r.items, Item{Path: path, Style: style, Matrix: m})
+}
+
+func (r *displayList) RenderText(text *canvas.Text, m canvas.Matrix) {
+	r.items = append(r.items, Item{Text: text, Matrix: m})
+}
+
+func (r *displayList) RenderImage(img image.Image, m canvas.Matrix) {
+	r.items = append(r.items, Item{Image: img, Matrix: m})
+}
+
+// DisplayList returns the drawing operations of the canvas in the order they are rendered, that is by z-index and then in the order of drawing.
+func DisplayList(c *canvas.Canvas) []Item {
+	r := &displayList{w: c.W, h: c.H}
+	c.RenderTo(r)
+	return r.items
+}
+
+// DiffOp is the kind of a display list difference.
+type DiffOp int
+
+// see DiffOp
+const (
+	Removed DiffOp = iota
+	Added
+	Changed
+)
+
+func (op DiffOp) String() string {
+	switch op {
+	case Removed:
+		return "removed"
+	case Added:
+		return "added"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("DiffOp(%d)", int(op))
+}
+
+// Diff is a difference between two display lists. IndexA and IndexB are the indices of the item into the first and second display list respectively, and are -1 for added and removed items respectively.
+type Diff struct {
+	Op             DiffOp
+	IndexA, IndexB int
+	Item           Item     // item of the first display list, or of the second for added items
+	Properties     []string // properties that changed
+}
+
+func (diff Diff) String() string {
+	switch diff.Op {
+	case Removed:
+		return fmt.Sprintf("- %d: %v", diff.IndexA, diff.Item)
+	case Added:
+		return fmt.Sprintf("+ %d: %v", diff.IndexB, diff.Item)
+	}
+	return fmt.Sprintf("~ %d→%d: %v: %s", diff.IndexA, diff.IndexB, diff.Item, strings.Join(diff.Properties, ", "))
+}
+
+// DiffDisplayLists returns the structural differences between the display lists of two canvases, see DisplayList. Items are aligned by the longest common subsequence of equal items, and unaligned items of the same type between two aligned items are reported as changed.
+func DiffDisplayLists(a, b *canvas.Canvas) []Diff {
+	itemsA, itemsB := DisplayList(a), DisplayList(b)
+	n, m := len(itemsA), len(itemsB)
+
+	// lcs[i][j] is the length of the longest common subsequence of itemsA[i:] and itemsB[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; 0 <= i; i-- {
+		for j := m - 1; 0 <= j; j-- {
+			if itemsA[i].differences(itemsB[j]) == nil {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diffs []Diff
+	var removed, added []int
+	flush := func() {
+		// pair up removed and added items of the same type as changed, in order
+		paired := make([]bool, len(added))
+	RemovedLoop:
+		for _, i := range removed {
+			for k, j := range added {
+				if !paired[k] {
+					if props := itemsA[i].differences(itemsB[j]); props[0] != "type" {
+						diffs = append(diffs, Diff{Changed, i, j, itemsA[i], props})
+						paired[k] = true
+						continue RemovedLoop
+					}
+				}
+			}
+			diffs = append(diffs, Diff{Removed, i, -1, itemsA[i], nil})
+		}
+		for k, j := range added {
+			if !paired[k] {
+				diffs = append(diffs, Diff{Added, -1, j, itemsB[j], nil})
+			}
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		if i < n && j < m && itemsA[i].differences(itemsB[j]) == nil {
+			flush()
+			i++
+			j++
+		} else if j == m || i < n && lcs[i+1][j] >= lcs[i][j+1] {
+			removed = append(removed, i)
+			i++
+		} else {
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+	return diffs
+}
@@ -58,6 +58,7 @@ func (cmd *Extract) Run() error {
 	if err != nil {
 		return err
 	}
+	defer printWarnings(pdf)
 
 	if cmd.Info {
 		fmt.Println("File name:", filepath.Base(cmd.Input))
@@ -147,6 +148,7 @@ func (cmd *Replace) Run() error {
 		fr.Close()
 		return err
 	}
+	defer printWarnings(pdf)
 
 	var obj any
 	names, objects := getObjects(pdf, cmd.Page)
@@ -267,6 +269,12 @@ func (cmd *Replace) Run() error {
 	return pdfWriter.Close()
 }
 
+func printWarnings(pdf *pdfReader) {
+	for _, warning := range pdf.Warnings() {
+		fmt.Fprintln(os.Stderr, "WARNING:", warning)
+	}
+}
+
 type textState struct {
 	fonts    map[pdfName]pdfFont
 	fontName pdfName
@@ -27,6 +27,13 @@ type pdfReader struct {
 	startxref int
 	eol       []byte
 	cache     map[pdfRef]any
+
+	// recovery from broken files
+	repaired bool
+	spans    []pdfObjectSpan
+	scanned  map[pdfRef]pdfObject
+	trailers []pdfDict
+	warnings []string
 }
 
 func NewPDFReader(reader io.Reader, password string) (*pdfReader, error) {
@@ -43,11 +50,35 @@ func NewPDFReader(reader io.Reader, password string) (*pdfReader, error) {
 		cache:   map[pdfRef]any{},
 	}
 
-	// get startxref
+	startxref, err := r.readStartXRef()
+	if err == nil {
+		r.trailer, err = r.readTrailer(startxref)
+	}
+	if err != nil {
+		r.warnf("%v: rebuilding cross reference table", err)
+		if err := r.repair(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.readEncrypt([]byte(password)); err != nil {
+		return nil, err
+	} else if r.repaired {
+		r.readObjectStreams()
+	}
+	if err := r.readKids(); err != nil {
+		r.warnf("%v: searching for document catalog", err)
+		if err := r.recoverCatalog(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *pdfReader) readStartXRef() (int, error) {
 	var line []byte
 	lrr := newLineReaderReverse(r.data, len(r.data))
 	if line = lrr.Next(); !bytes.Equal(line, []byte("%%EOF")) {
-		return nil, fmt.Errorf("invalid PDF file")
+		return 0, fmt.Errorf("invalid PDF file: missing %%%%EOF")
 	}
 	if r.data[lrr.Pos()] == '\r' && r.data[lrr.Pos()+1] == '\n' {
 		r.eol = r.data[lrr.Pos() : lrr.Pos()+2]
@@ -56,21 +87,11 @@ func NewPDFReader(reader io.Reader, password string) (*pdfReader, error) {
 	}
 	num, _ := strconv.ParseUint(lrr.Next())
 	if num == 0 {
-		return nil, fmt.Errorf("invalid PDF file")
+		return 0, fmt.Errorf("invalid PDF file: bad startxref")
 	} else if line = lrr.Next(); !bytes.Equal(bytes.TrimSpace(line), []byte("startxref")) {
-		return nil, fmt.Errorf("invalid PDF file")
+		return 0, fmt.Errorf("invalid PDF file: missing startxref")
 	}
-	startxref := int(num)
-	//endtrailer := lrr.Pos()
-
-	if r.trailer, err = r.readTrailer(startxref); err != nil {
-		return nil, err
-	} else if err := r.readEncrypt([]byte(password)); err != nil {
-		return nil, err
-	} else if err := r.readKids(); err != nil {
-		return nil, err
-	}
-	return r, nil
+	return int(num), nil
 }
 
 func (r *pdfReader) readCrossReferenceTable() (pdfDict, error) {
@@ -248,6 +269,9 @@ func (r *pdfReader) readCrossReferenceStream() (pdfDict, error) {
 }
 
 func (r *pdfReader) readTrailer(startxref int) (pdfDict, error) {
+	if startxref < 0 || len(r.data) <= startxref {
+		return pdfDict{}, fmt.Errorf("invalid cross reference offset %d", startxref)
+	}
 	r.startxref = startxref
 
 	var trailer pdfDict
@@ -461,8 +485,13 @@ func (r *pdfReader) readObject(ref pdfRef) (any, error) {
 	}
 	obj, ok := r.objects[ref]
 	if !ok {
-		return nil, fmt.Errorf("unknown object %v", ref)
-	} else if obj.free {
+		if obj, ok = r.recoverObject(ref); !ok {
+			return nil, fmt.Errorf("unknown object %v", ref)
+		}
+		r.warnf("object %v: missing from cross reference table", ref)
+		r.objects[ref] = obj
+	}
+	if obj.free {
 		return nil, fmt.Errorf("bad object %v is free", ref)
 	} else if obj.compressed {
 		iobjectStream, err := r.readObject(pdfRef{obj.object, 0})
@@ -509,19 +538,31 @@ func (r *pdfReader) readObject(ref pdfRef) (any, error) {
 		return val, nil
 	}
 	val, err := r.readObjectAt(ref, obj.offset)
+	if err != nil {
+		if obj2, ok := r.recoverObject(ref); ok && obj2.offset != obj.offset {
+			if val2, err2 := r.readObjectAt(ref, obj2.offset); err2 == nil {
+				r.warnf("object %v: bad offset %d in cross reference table, found at %d", ref, obj.offset, obj2.offset)
+				r.objects[ref] = obj2
+				val, err = val2, nil
+			}
+		}
+	}
 	r.cache[ref] = val
 	return val, err
 }
 
 func (r *pdfReader) readObjectAt(ref pdfRef, i int) (any, error) {
 	b := r.data
+	if i < 0 || len(b) <= i {
+		return nil, fmt.Errorf("bad object %v: offset out of range", ref)
+	}
 	val, n, err := pdfReadContentVal(b[i:])
-	if _, ok := val.(int); !ok || err != nil {
+	if object, ok := val.(int); !ok || err != nil || ref != noEncryptRef && uint32(object) != ref[0] {
 		return nil, fmt.Errorf("bad object %v", ref)
 	}
 	i = moveWhiteSpace(b, i+n)
 	val, n, err = pdfReadContentVal(b[i:])
-	if _, ok := val.(int); !ok || err != nil {
+	if generation, ok := val.(int); !ok || err != nil || ref != noEncryptRef && uint32(generation) != ref[1] {
 		return nil, fmt.Errorf("bad object %v", ref)
 	}
 	i = moveWhiteSpace(b, i+n)
@@ -539,8 +580,8 @@ func (r *pdfReader) readObjectAt(ref pdfRef, i int) (any, error) {
 	}
 	i = moveWhiteSpace(b, i+n)
 
-	if len(b) <= i+6 || !bytes.Equal(b[i:i+6], []byte("endobj")) {
-		return nil, fmt.Errorf("bad object %v", ref)
+	if len(b) < i+6 || !bytes.Equal(b[i:i+6], []byte("endobj")) {
+		r.warnf("object %v: missing endobj", ref)
 	}
 	return val, nil
 }
@@ -714,10 +755,23 @@ func pdfReadVal(r *pdfReader, ref pdfRef, b []byte) (any, int, error) {
 			}
 
 			length, err := r.GetInt(dict["Length"])
-			if err != nil {
-				return nil, 0, fmt.Errorf("bad stream length: %w", err)
-			} else if len(b) <= i+length {
-				return nil, 0, fmt.Errorf("bad stream")
+			if err != nil || length < 0 || len(b) <= i+length || !hasEndstream(b, i+length) {
+				// recover from a missing or wrong length by searching for endstream
+				end := bytes.Index(b[i:], []byte("endstream"))
+				if end == -1 {
+					if err != nil {
+						return nil, 0, fmt.Errorf("bad stream length: %w", err)
+					}
+					return nil, 0, fmt.Errorf("bad stream")
+				}
+				if 0 < end && b[i+end-1] == '\n' {
+					end--
+				}
+				if 0 < end && b[i+end-1] == '\r' {
+					end--
+				}
+				r.warnf("object %v: bad stream length, using %d", ref, end)
+				length = end
 			}
 
 			var filters []pdfName
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/tdewolff/parse/v2/strconv"
+)
+
+// pdfObjectSpan is the location of an object found by scanning the file for obj and endobj markers.
+type pdfObjectSpan struct {
+	ref    pdfRef
+	offset int // start of the object number
+	body   int // start of the object's value after obj
+	head   int // end of the object's dictionary, ie. before stream or endobj
+	end    int // end of the object after endobj
+}
+
+// warnf records a problem in the PDF file that was recovered from.
+func (r *pdfReader) warnf(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+// Warnings returns the problems in the PDF file that were recovered from while reading.
+func (r *pdfReader) Warnings() []string {
+	return r.warnings
+}
+
+func indexFrom(b []byte, i int, s string) int {
+	if len(b) <= i {
+		return len(b)
+	} else if j := bytes.Index(b[i:], []byte(s)); j != -1 {
+		return i + j
+	}
+	return len(b)
+}
+
+func hasEndstream(b []byte, i int) bool {
+	i = moveWhiteSpace(b, i)
+	return i+9 <= len(b) && bytes.Equal(b[i:i+9], []byte("endstream"))
+}
+
+// parseObjectHeader parses "N G obj" backwards where i is the position of obj. It returns the reference and the position of the object number.
+func parseObjectHeader(b []byte, i int) (pdfRef, int, bool) {
+	if i == 0 || !isWhiteSpace(b[i-1]) || i+3 < len(b) && isRegular(b[i+3]) {
+		return pdfRef{}, 0, false
+	}
+
+	nums := [2]uint64{}
+	j := i - 1
+	for k := 1; 0 <= k; k-- {
+		for 0 <= j && isWhiteSpace(b[j]) {
+			j--
+		}
+		end := j + 1
+		for 0 <= j && '0' <= b[j] && b[j] <= '9' {
+			j--
+		}
+		if j+1 == end || 10 < end-j-1 || 0 <= j && isRegular(b[j]) {
+			return pdfRef{}, 0, false
+		}
+		nums[k], _ = strconv.ParseUint(b[j+1 : end])
+	}
+	if 1<<31 <= nums[0] || 65535 < nums[1] {
+		return pdfRef{}, 0, false
+	}
+	return pdfRef{uint32(nums[0]), uint32(nums[1])}, j + 1, true
+}
+
+// scan finds all objects and trailers in the file by looking for obj, endobj, and trailer markers, ignoring the cross-reference tables. Stream data is skipped so that markers in binary content are not picked up. Later definitions of an object override earlier ones, as is the case for incremental updates. Cross-reference streams are parsed as trailers.
+func (r *pdfReader) scan() {
+	if r.scanned != nil {
+		return
+	}
+	r.scanned = map[pdfRef]pdfObject{}
+
+	b := r.data
+	i := 0
+	iObj, iTrailer := -1, -1
+	for i < len(b) {
+		if iObj < i {
+			iObj = indexFrom(b, i, "obj")
+		}
+		if iTrailer < i {
+			iTrailer = indexFrom(b, i, "trailer")
+		}
+
+		if iTrailer < iObj {
+			i = iTrailer + 7
+			if 0 < iTrailer && isRegular(b[iTrailer-1]) || i < len(b) && isRegular(b[i]) {
+				continue
+			}
+			j := moveWhiteSpace(b, i)
+			if val, n, err := pdfReadVal(r, noEncryptRef, b[j:]); err == nil {
+				if trailer, ok := val.(pdfDict); ok {
+					r.trailers = append(r.trailers, trailer)
+				}
+				i = j + n
+			}
+			continue
+		} else if iObj == len(b) {
+			break
+		}
+
+		i = iObj + 3
+		ref, offset, ok := parseObjectHeader(b, iObj)
+		if !ok {
+			continue
+		}
+		span := pdfObjectSpan{ref: ref, offset: offset, body: i}
+
+		// skip stream data
+		span.head = indexFrom(b, i, "endobj")
+		if iStream := indexFrom(b, i, "stream"); iStream < span.head {
+			span.head = iStream
+			if i = indexFrom(b, iStream+6, "endstream") + 9; len(b) < i {
+				i = len(b)
+			}
+		}
+
+		// an object without endobj ends where the next object starts
+		end := indexFrom(b, i, "endobj")
+		for j := indexFrom(b, i, "obj"); j < end; j = indexFrom(b, j+3, "obj") {
+			if _, next, ok := parseObjectHeader(b, j); ok {
+				r.warnf("object %v at %d: missing endobj", ref, offset)
+				end = next
+				break
+			}
+		}
+		if end == len(b) {
+			r.warnf("object %v at %d: truncated", ref, offset)
+			break
+		} else if bytes.HasPrefix(b[end:], []byte("endobj")) {
+			end += 6
+		}
+		span.end = end
+		i = end
+
+		if bytes.Contains(b[span.body:span.head], []byte("/XRef")) {
+			if val, _, err := pdfReadVal(r, noEncryptRef, b[moveWhiteSpace(b, span.body):span.head]); err == nil {
+				if trailer, ok := val.(pdfDict); ok && trailer["Type"] == pdfName("XRef") {
+					r.trailers = append(r.trailers, trailer)
+					continue
+				}
+			}
+		}
+		r.spans = append(r.spans, span)
+		r.scanned[ref] = pdfObject{offset: offset}
+	}
+}
+
+// recoverObject finds an object by scanning the file, for when it is missing from the cross-reference table or its offset is wrong.
+func (r *pdfReader) recoverObject(ref pdfRef) (pdfObject, bool) {
+	r.scan()
+	obj, ok := r.scanned[ref]
+	return obj, ok
+}
+
+// repair rebuilds the cross-reference table and the trailer from the objects and trailers found by scanning the file. Later trailers override the entries of earlier ones.
+func (r *pdfReader) repair() error {
+	r.scan()
+	if len(r.scanned) == 0 {
+		return fmt.Errorf("invalid PDF file: no objects found")
+	}
+
+	size := uint32(0)
+	r.objects = map[pdfRef]pdfObject{}
+	for ref, obj := range r.scanned {
+		r.objects[ref] = obj
+		if size <= ref[0] {
+			size = ref[0] + 1
+		}
+	}
+
+	r.trailer = pdfDict{}
+	for _, trailer := range r.trailers {
+		for key, val := range trailer {
+			r.trailer[key] = val
+		}
+	}
+	for _, key := range []string{"Prev", "XRefStm", "Type", "Index", "W", "Length", "Filter", "DecodeParms"} {
+		delete(r.trailer, key)
+	}
+	r.trailer["Size"] = int(size)
+
+	// data after the last object is dropped when writing
+	r.startxref = 0
+	for _, span := range r.spans {
+		if r.startxref < span.end {
+			r.startxref = span.end
+		}
+	}
+	for r.startxref < len(r.data) && isWhiteSpace(r.data[r.startxref]) {
+		r.startxref++
+	}
+	r.eol = []byte("\n")
+	r.cache = map[pdfRef]any{}
+	r.repaired = true
+	return nil
+}
+
+// isLatest returns true if the span is the last definition of its object in the file.
+func (r *pdfReader) isLatest(span pdfObjectSpan) bool {
+	return r.scanned[span.ref].offset == span.offset
+}
+
+// readObjectStreams adds the compressed objects of all object streams found by scanning the file. It must be called after the encryption has been read, since the streams may be encrypted.
+func (r *pdfReader) readObjectStreams() {
+	for _, span := range r.spans {
+		if span.ref[1] != 0 || !r.isLatest(span) || !bytes.Contains(r.data[span.body:span.head], []byte("/ObjStm")) {
+			continue
+		}
+		stream, err := r.GetStream(span.ref)
+		if err != nil || stream.dict["Type"] != pdfName("ObjStm") {
+			r.warnf("object %v: bad object stream", span.ref)
+			continue
+		}
+		n, _ := r.GetInt(stream.dict["N"])
+
+		b, i := stream.data, 0
+		for index := 0; index < n; index++ {
+			val, k, err := pdfReadContentVal(b[i:])
+			object, ok := val.(int)
+			if err != nil || !ok || object < 0 {
+				r.warnf("object %v: bad object stream", span.ref)
+				break
+			}
+			i = moveWhiteSpace(b, i+k)
+			if _, k, err = pdfReadContentVal(b[i:]); err != nil {
+				r.warnf("object %v: bad object stream", span.ref)
+				break
+			}
+			i = moveWhiteSpace(b, i+k)
+
+			// uncompressed objects take precedence
+			ref := pdfRef{uint32(object), 0}
+			if obj, ok := r.objects[ref]; !ok || obj.compressed {
+				r.objects[ref] = pdfObject{compressed: true, offset: index, object: span.ref[0]}
+				if r.trailer["Size"].(int) <= object {
+					r.trailer["Size"] = object + 1
+				}
+			}
+		}
+	}
+	r.cache = map[pdfRef]any{}
+}
+
+// recoverCatalog searches all objects for a valid document catalog, preferring the one defined last in the file, for when the trailer's Root entry is missing or invalid.
+func (r *pdfReader) recoverCatalog() error {
+	r.scan()
+
+	type candidate struct {
+		ref pdfRef
+		pos int
+	}
+	candidates := []candidate{}
+	for _, span := range r.spans {
+		if r.isLatest(span) && bytes.Contains(r.data[span.body:span.head], []byte("/Catalog")) {
+			candidates = append(candidates, candidate{span.ref, span.offset})
+		}
+	}
+	for ref, obj := range r.objects {
+		if obj.compressed {
+			candidates = append(candidates, candidate{ref, r.objects[pdfRef{obj.object, 0}].offset})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].pos == candidates[j].pos {
+			return candidates[j].ref[0] < candidates[i].ref[0]
+		}
+		return candidates[j].pos < candidates[i].pos
+	})
+
+	for _, c := range candidates {
+		if root, err := r.GetDict(c.ref); err != nil || root["Type"] != pdfName("Catalog") {
+			continue
+		}
+		r.trailer["Root"] = c.ref
+		r.kids = r.kids[:0]
+		if err := r.readKids(); err == nil {
+			r.warnf("using document catalog %v", c.ref)
+			return nil
+		}
+	}
+	r.kids = r.kids[:0]
+	return fmt.Errorf("bad /Catalog object: no document catalog found")
+}
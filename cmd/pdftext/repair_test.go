@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+var testObjects = []string{
+	"1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n",
+	"2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n",
+	"3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n",
+	"4 0 obj\n<< /Length 9 >>\nstream\nBT ET q Q\nendstream\nendobj\n",
+}
+
+// testPDF builds a PDF file of the objects with a cross-reference table of the first n objects, where offset modifies their offsets.
+func testPDF(objects []string, n int, offset func(int, int) int, trailer string) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("%PDF-1.7\n")
+	offsets := []int{}
+	for _, obj := range objects {
+		offsets = append(offsets, b.Len())
+		b.WriteString(obj)
+	}
+	startxref := b.Len()
+	fmt.Fprintf(b, "xref\n0 %d\n0000000000 65535 f \n", n+1)
+	for i, pos := range offsets[:n] {
+		fmt.Fprintf(b, "%010d 00000 n \n", offset(i, pos))
+	}
+	fmt.Fprintf(b, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, startxref)
+	return b.Bytes()
+}
+
+func identityOffset(i, pos int) int {
+	return pos
+}
+
+func TestReaderValid(t *testing.T) {
+	data := testPDF(testObjects, 4, identityOffset, "<< /Size 5 /Root 1 0 R >>")
+	r, err := NewPDFReader(bytes.NewReader(data), "")
+	test.Error(t, err)
+	test.T(t, len(r.Warnings()), 0)
+	test.T(t, len(r.GetPages()), 1)
+
+	_, contents, err := r.GetPage(0)
+	test.Error(t, err)
+	test.String(t, string(contents), "BT ET q Q")
+}
+
+func TestReaderRepair(t *testing.T) {
+	var tests = []struct {
+		name string
+		data []byte
+	}{
+		{"wrong offsets", testPDF(testObjects, 4, func(i, pos int) int { return pos + 3 }, "<< /Size 5 /Root 1 0 R >>")},
+		{"offsets out of range", testPDF(testObjects, 4, func(i, pos int) int { return 1 << 20 }, "<< /Size 5 /Root 1 0 R >>")},
+		{"missing xref entries", testPDF(testObjects, 2, identityOffset, "<< /Size 3 /Root 1 0 R >>")},
+		{"missing root", testPDF(testObjects, 4, identityOffset, "<< /Size 5 >>")},
+		{"bad startxref", regexp.MustCompile(`startxref\n\d+`).ReplaceAll(testPDF(testObjects, 4, identityOffset, "<< /Size 5 /Root 1 0 R >>"), []byte("startxref\n123"))},
+		{"truncated", []byte("%PDF-1.7\n" + strings.Join(testObjects, "") + "trailer\n<< /Root 1 0 R >>\n5 0 obj\n<< /Len")},
+		{"no xref", []byte("%PDF-1.7\n" + strings.Join(testObjects, "") + "%%EOF\n")},
+		{"bad stream length", testPDF(append(testObjects[:3:3], "4 0 obj\n<< /Length 99 >>\nstream\nBT ET q Q\nendstream\nendobj\n"), 4, identityOffset, "<< /Size 5 /Root 1 0 R >>")},
+		{"missing endobj", []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\n" + strings.Join(testObjects[1:], ""))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewPDFReader(bytes.NewReader(tt.data), "")
+			test.Error(t, err)
+			test.T(t, len(r.GetPages()), 1)
+
+			_, contents, err := r.GetPage(0)
+			test.Error(t, err)
+			test.String(t, string(contents), "BT ET q Q")
+			test.That(t, 0 < len(r.Warnings()), "expected warnings")
+		})
+	}
+}
+
+func TestReaderRepairWrite(t *testing.T) {
+	data := []byte("%PDF-1.7\n" + strings.Join(testObjects, "") + "trailer\n<< /Root 1 0 R >>\n5 0 obj\n<< /Len")
+	r, err := NewPDFReader(bytes.NewReader(data), "")
+	test.Error(t, err)
+
+	w := &bytes.Buffer{}
+	test.Error(t, NewPDFWriter(w, r).Close())
+
+	r, err = NewPDFReader(w, "")
+	test.Error(t, err)
+	test.T(t, len(r.Warnings()), 0)
+	test.T(t, len(r.GetPages()), 1)
+}
+
+func TestReaderBroken(t *testing.T) {
+	_, err := NewPDFReader(bytes.NewReader([]byte("%PDF-1.7\ngarbage\n")), "")
+	test.That(t, err != nil, "expected error")
+}
@@ -129,6 +129,37 @@ func (g Grad) At(t float64) color.RGBA {
 	return g[len(g)-1].Color
 }
 
+// At64 returns the color at position t ∈ [0,1] with 16-bit precision.
+func (g Grad) At64(t float64) color.RGBA64 {
+	return g.at64(t, nil)
+}
+
+// at64 returns the color at position t ∈ [0,1] with 16-bit precision, using the colors of the stops converted by linear64 if not nil.
+func (g Grad) at64(t float64, colors []color.RGBA64) color.RGBA64 {
+	if len(g) == 0 {
+		return color.RGBA64{}
+	}
+	colorAt := func(i int) color.RGBA64 {
+		if colors != nil {
+			return colors[i]
+		}
+		return rgba64(g[i].Color)
+	}
+	if len(g) == 1 || t <= g[0].Offset {
+		return colorAt(0)
+	} else if g[len(g)-1].Offset <= t {
+		return colorAt(len(g) - 1)
+	}
+	for i, after := range g[1:] {
+		if t < after.Offset {
+			before := g[i]
+			t = (t - before.Offset) / (after.Offset - before.Offset)
+			return colorLerp64(colorAt(i), colorAt(i+1), t)
+		}
+	}
+	return colorAt(len(g) - 1)
+}
+
 func (g Grad) ToLinear(start, end Point) *LinearGradient {
 	grad := NewLinearGradient(start, end)
 	grad.Grad = g
@@ -141,14 +172,25 @@ func (g Grad) ToRadial(c0 Point, r0 float64, c1 Point, r1 float64) *RadialGradie
 	return grad
 }
 
-func (g Grad) SetColorSpace(colorSpace ColorSpace) {
+// SetColorSpace returns a copy of the gradient with the colors of its stops converted to the linear color space.
+func (g Grad) SetColorSpace(colorSpace ColorSpace) Grad {
 	if _, ok := colorSpace.(LinearColorSpace); ok {
-		return
+		return g
 	}
 	g = slices.Clone(g)
 	for i := range g {
 		g[i].Color = colorSpace.ToLinear(g[i].Color)
 	}
+	return g
+}
+
+// linear64 returns the colors of the stops converted to the linear color space with 16-bit precision.
+func (g Grad) linear64(colorSpace ColorSpace) []color.RGBA64 {
+	colors := make([]color.RGBA64, len(g))
+	for i, stop := range g {
+		colors[i] = ToLinear64(colorSpace, stop.Color)
+	}
+	return colors
 }
 
 func colorLerp(c0, c1 color.RGBA, t float64) color.RGBA {
@@ -167,12 +209,34 @@ func lerp(a, b, t uint32) uint8 {
 	return uint8(((0xffff-t)*a + t*b) >> 24)
 }
 
+func colorLerp64(c0, c1 color.RGBA64, t float64) color.RGBA64 {
+	r0, g0, b0, a0 := c0.RGBA()
+	r1, g1, b1, a1 := c1.RGBA()
+	T := uint32(t*65535.0 + 0.5)
+	return color.RGBA64{
+		lerp64(r0, r1, T),
+		lerp64(g0, g1, T),
+		lerp64(b0, b1, T),
+		lerp64(a0, a1, T),
+	}
+}
+
+func lerp64(a, b, t uint32) uint16 {
+	return uint16(((0xffff-t)*a + t*b + 0x7fff) / 0xffff)
+}
+
+func rgba64(col color.Color) color.RGBA64 {
+	R, G, B, A := col.RGBA()
+	return color.RGBA64{uint16(R), uint16(G), uint16(B), uint16(A)}
+}
+
 // LinearGradient is a linear gradient pattern between the given start and end points. The color at offset 0 corresponds to the start position, and offset 1 to the end position. Start and end points are in the canvas's coordinate system.
 type LinearGradient struct {
 	Grad
 	Start, End Point
 	d          Point
 	d2         float64
+	linear     []color.RGBA64 // colors of the stops in the linear color space, see SetColorSpace
 }
 
 // NewLinearGradient returns a new linear gradient pattern.
@@ -186,10 +250,12 @@ func NewLinearGradient(start, end Point) *LinearGradient {
 	}
 }
 
-// SetColorSpace sets the color space. Automatically called by the rasterizer.
+// SetColorSpace returns a copy of the gradient with the colors of its stops converted to the linear color space, with 16-bit precision for At64. Automatically called by the rasterizer.
 func (g *LinearGradient) SetColorSpace(colorSpace ColorSpace) Gradient {
-	g.Grad.SetColorSpace(colorSpace)
-	return g
+	grad := *g
+	grad.Grad = g.Grad.SetColorSpace(colorSpace)
+	grad.linear = g.Grad.linear64(colorSpace)
+	return &grad
 }
 
 // At returns the color at position (x,y).
@@ -208,6 +274,22 @@ func (g *LinearGradient) At(x, y float64) color.RGBA {
 	return g.Grad.At(t)
 }
 
+// At64 returns the color at position (x,y) with 16-bit precision.
+func (g *LinearGradient) At64(x, y float64) color.RGBA64 {
+	if len(g.Grad) == 0 {
+		return color.RGBA64{}
+	}
+
+	p := Point{x, y}.Sub(g.Start)
+	if Equal(g.d.Y, 0.0) && !Equal(g.d.X, 0.0) {
+		return g.Grad.at64(p.X/g.d.X, g.linear) // horizontal
+	} else if !Equal(g.d.Y, 0.0) && Equal(g.d.X, 0.0) {
+		return g.Grad.at64(p.Y/g.d.Y, g.linear) // vertical
+	}
+	t := p.Dot(g.d) / g.d2
+	return g.Grad.at64(t, g.linear)
+}
+
 // RadialGradient is a radial gradient pattern between two circles defined by their center points and radii. Color stop at offset 0 corresponds to the first circle and offset 1 to the second circle.
 type RadialGradient struct {
 	Grad
@@ -215,6 +297,7 @@ type RadialGradient struct {
 	R0, R1 float64
 	cd     Point
 	dr, a  float64
+	linear []color.RGBA64 // colors of the stops in the linear color space, see SetColorSpace
 }
 
 // NewRadialGradient returns a new radial gradient pattern.
@@ -232,10 +315,12 @@ func NewRadialGradient(c0 Point, r0 float64, c1 Point, r1 float64) *RadialGradie
 	}
 }
 
-// SetColorSpace sets the color space. Automatically called by the rasterizer.
+// SetColorSpace returns a copy of the gradient with the colors of its stops converted to the linear color space, with 16-bit precision for At64. Automatically called by the rasterizer.
 func (g *RadialGradient) SetColorSpace(colorSpace ColorSpace) Gradient {
-	g.Grad.SetColorSpace(colorSpace)
-	return g
+	grad := *g
+	grad.Grad = g.Grad.SetColorSpace(colorSpace)
+	grad.linear = g.Grad.linear64(colorSpace)
+	return &grad
 }
 
 // At returns the color at position (x,y).
@@ -243,7 +328,19 @@ func (g *RadialGradient) At(x, y float64) color.RGBA {
 	if len(g.Grad) == 0 {
 		return Transparent
 	}
+	return g.Grad.At(g.offset(x, y))
+}
 
+// At64 returns the color at position (x,y) with 16-bit precision.
+func (g *RadialGradient) At64(x, y float64) color.RGBA64 {
+	if len(g.Grad) == 0 {
+		return color.RGBA64{}
+	}
+	return g.Grad.at64(g.offset(x, y), g.linear)
+}
+
+// offset returns the gradient offset at position (x,y).
+func (g *RadialGradient) offset(x, y float64) float64 {
 	// see reference implementation of pixman-radial-gradient
 	// https://github.com/servo/pixman/blob/master/pixman/pixman-radial-gradient.c#L161
 	pd := Point{x, y}.Sub(g.C0)
@@ -260,17 +357,17 @@ func (g *RadialGradient) At(x, y float64) color.RGBA {
 
 	// Pick the largest valid t (t1 >= t0 from solveQuadraticFormula).
 	if valid(t1) {
-		return g.Grad.At(t1)
+		return t1
 	}
 	if valid(t0) {
-		return g.Grad.At(t0)
+		return t0
 	}
 
 	// No valid t in [0,1]. Extend boundary colors.
 	if hasPositive(t0) || hasPositive(t1) {
-		return g.Grad.At(1)
+		return 1.0
 	}
-	return g.Grad.At(0)
+	return 0.0
 }
 
 // ColorSpace defines the color space within the RGB color model. All colors passed to this library are assumed to be in the sRGB color space, which is a ubiquitous assumption in most software. This works great for most applications, but fails when blending semi-transparent layers. See an elaborate explanation at https://blog.johnnovak.net/2016/09/21/what-every-coder-should-know-about-gamma/, which goes into depth of the problems of using sRGB for blending and the need for gamma correction. In short, we need to transform the colors, which are in the sRGB color space, to the linear color space, perform blending, and then transform them back to the sRGB color space.
@@ -280,6 +377,44 @@ type ColorSpace interface {
 	FromLinear(color.Color) color.RGBA
 }
 
+// ColorSpace64 is a color space that can convert colors with 16-bit precision, which is used when rendering to images with a high bit depth.
+type ColorSpace64 interface {
+	ColorSpace
+	ToLinear64(color.Color) color.RGBA64
+	FromLinear64(color.Color) color.RGBA64
+}
+
+// ToLinear64 converts a color to the linear color space with 16-bit precision if the color space supports it, see ColorSpace64.
+func ToLinear64(colorSpace ColorSpace, col color.Color) color.RGBA64 {
+	if cs, ok := colorSpace.(ColorSpace64); ok {
+		return cs.ToLinear64(col)
+	}
+	return rgba64(colorSpace.ToLinear(col))
+}
+
+// FromLinear64 converts a color from the linear color space with 16-bit precision if the color space supports it, see ColorSpace64.
+func FromLinear64(colorSpace ColorSpace, col color.Color) color.RGBA64 {
+	if cs, ok := colorSpace.(ColorSpace64); ok {
+		return cs.FromLinear64(col)
+	}
+	return rgba64(colorSpace.FromLinear(col))
+}
+
+// mapColor64 applies f to the non-premultiplied color channels in [0,1] of a color with 16-bit precision.
+func mapColor64(col color.Color, f func(float64) float64) color.RGBA64 {
+	R, G, B, A := col.RGBA()
+	if A == 0 {
+		return color.RGBA64{}
+	}
+	a := float64(A)
+	return color.RGBA64{
+		uint16(math.Min(math.Max(f(float64(R)/a), 0.0), 1.0)*a + 0.5),
+		uint16(math.Min(math.Max(f(float64(G)/a), 0.0), 1.0)*a + 0.5),
+		uint16(math.Min(math.Max(f(float64(B)/a), 0.0), 1.0)*a + 0.5),
+		uint16(A),
+	}
+}
+
 // DefaultColorSpace is set to LinearColorSpace to match other renderers.
 var DefaultColorSpace ColorSpace = LinearColorSpace{}
 
@@ -304,6 +439,16 @@ func (LinearColorSpace) FromLinear(col color.Color) color.RGBA {
 	return color.RGBA{uint8(R >> 8), uint8(G >> 8), uint8(B >> 8), uint8(A >> 8)}
 }
 
+// ToLinear64 encodes color to color space with 16-bit precision.
+func (LinearColorSpace) ToLinear64(col color.Color) color.RGBA64 {
+	return rgba64(col)
+}
+
+// FromLinear64 decodes color from color space with 16-bit precision.
+func (LinearColorSpace) FromLinear64(col color.Color) color.RGBA64 {
+	return rgba64(col)
+}
+
 // GammaColorSpace assumes that input colors and output images are gamma-corrected with the given gamma value. The sRGB space uses a gamma=2.4 for most of the curve, but will on average have a gamma=2.2 best approximating the sRGB curve. See https://en.wikipedia.org/wiki/SRGB#The_sRGB_transfer_function_(%22gamma%22). According to https://www.puredevsoftware.com/blog/2019/01/22/sub-pixel-gamma-correct-font-rendering/, a gamma=1.43 is recommended for fonts.
 type GammaColorSpace struct {
 	Gamma float64
@@ -339,6 +484,20 @@ func (cs GammaColorSpace) FromLinear(col color.Color) color.RGBA {
 	}
 }
 
+// ToLinear64 encodes color to color space with 16-bit precision.
+func (cs GammaColorSpace) ToLinear64(col color.Color) color.RGBA64 {
+	return mapColor64(col, func(c float64) float64 {
+		return math.Pow(c, cs.Gamma)
+	})
+}
+
+// FromLinear64 decodes color from color space with 16-bit precision.
+func (cs GammaColorSpace) FromLinear64(col color.Color) color.RGBA64 {
+	return mapColor64(col, func(c float64) float64 {
+		return math.Pow(c, 1.0/cs.Gamma)
+	})
+}
+
 // SRGBColorSpace assumes that input colors and output images are in the sRGB color space (ubiquitous in almost all applications), which implies that for blending we need to convert to the linear color space, do blending, and then convert back to the sRGB color space. This will give technically correct blending, but may differ from common PDF viewer and browsers (which are wrong).
 type SRGBColorSpace struct{}
 
@@ -392,3 +551,25 @@ func (SRGBColorSpace) FromLinear(col color.Color) color.RGBA {
 		uint8(a*255.0 + 0.5),
 	}
 }
+
+// ToLinear64 encodes color to color space with 16-bit precision.
+func (SRGBColorSpace) ToLinear64(col color.Color) color.RGBA64 {
+	return mapColor64(col, func(c float64) float64 {
+		// Formula from EXT_sRGB.
+		if c <= 0.04045 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	})
+}
+
+// FromLinear64 decodes color from color space with 16-bit precision.
+func (SRGBColorSpace) FromLinear64(col color.Color) color.RGBA64 {
+	return mapColor64(col, func(c float64) float64 {
+		// Formula from EXT_sRGB.
+		if c < 0.0031308 {
+			return 12.92 * c
+		}
+		return 1.055*math.Pow(c, 1.0/2.4) - 0.055
+	})
+}
@@ -284,3 +284,50 @@ func TestRadialGradientAt(t *testing.T) {
 func formatRGBA(c color.RGBA) string {
 	return fmt.Sprintf("RGBA{%d, %d, %d, %d}", c.R, c.G, c.B, c.A)
 }
+
+func TestGradAt64(t *testing.T) {
+	g := NewLinearGradient(Point{0.0, 0.0}, Point{1.0, 0.0})
+	g.Add(0.0, color.RGBA{0, 0, 0, 255})
+	g.Add(1.0, color.RGBA{255, 255, 255, 255})
+
+	if c := g.At64(0.5, 0.0); c != (color.RGBA64{0x8000, 0x8000, 0x8000, 0xffff}) {
+		t.Errorf("expected 16-bit interpolation, got %v", c)
+	}
+	for _, x := range []float64{-1.0, 0.0, 0.25, 0.7, 1.0, 2.0} {
+		c8, c64 := g.At(x, 0.0), g.At64(x, 0.0)
+		if d := int(c8.R) - int(c64.R>>8); d < -1 || 1 < d {
+			t.Errorf("At and At64 differ at %v: %v != %v", x, c8, c64)
+		}
+	}
+
+	r := NewRadialGradient(Point{0.0, 0.0}, 0.0, Point{0.0, 0.0}, 10.0)
+	r.Add(0.0, color.RGBA{255, 0, 0, 255})
+	r.Add(1.0, color.RGBA{0, 0, 255, 255})
+	if c := r.At64(5.0, 0.0); c != (color.RGBA64{0x7fff, 0, 0x8000, 0xffff}) {
+		t.Errorf("unexpected radial gradient color %v", c)
+	}
+}
+
+func TestColorSpace64(t *testing.T) {
+	for _, cs := range []ColorSpace{LinearColorSpace{}, GammaColorSpace{2.2}, SRGBColorSpace{}} {
+		t.Run(fmt.Sprintf("%T", cs), func(t *testing.T) {
+			for _, col := range []color.Color{Black, White, Red, color.RGBA{10, 20, 30, 255}, color.RGBA{50, 25, 0, 128}, Transparent} {
+				c8 := cs.ToLinear(col)
+				c64 := ToLinear64(cs, col)
+				R, G, B, A := c8.RGBA()
+				for i, v := range [][2]uint32{{R, uint32(c64.R)}, {G, uint32(c64.G)}, {B, uint32(c64.B)}, {A, uint32(c64.A)}} {
+					if d := int(v[0]>>8) - int(v[1]>>8); d < -1 || 1 < d {
+						t.Errorf("channel %d of %v: ToLinear %v != ToLinear64 %v", i, col, c8, c64)
+					}
+				}
+
+				// 16-bit roundtrip
+				R, G, B, A = col.RGBA()
+				back := FromLinear64(cs, c64)
+				if A == 0xffff && (uint32(back.R)>>8 != R>>8 || uint32(back.G)>>8 != G>>8 || uint32(back.B)>>8 != B>>8 || uint32(back.A) != A) {
+					t.Errorf("roundtrip of %v: %v", col, back)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package canvas
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Page is a page of a document, which is a canvas with its own size together with a name and metadata. Renderers that support it use the name for the page's label or outline entry, and the metadata identifier as the page's anchor.
+type Page struct {
+	*Canvas
+	Name     string
+	Metadata Metadata
+}
+
+// Document is an ordered list of pages that can be written as a multi-page document such as PDF or PostScript, or as a file per page for single-page formats. Pages may have different sizes.
+type Document struct {
+	Pages []*Page
+}
+
+// NewDocument returns a new empty document.
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// NewPage appends a new page with width and height in millimeters and returns its canvas.
+func (doc *Document) NewPage(width, height float64) *Canvas {
+	c := New(width, height)
+	doc.Pages = append(doc.Pages, &Page{Canvas: c})
+	return c
+}
+
+// AddPage appends an existing canvas as a page with the given name and returns the page, which remains valid when more pages are added.
+func (doc *Document) AddPage(c *Canvas, name string) *Page {
+	page := &Page{Canvas: c, Name: name}
+	doc.Pages = append(doc.Pages, page)
+	return page
+}
+
+// Len returns the number of pages.
+func (doc *Document) Len() int {
+	return len(doc.Pages)
+}
+
+// DocumentWriter can write a document to a writer.
+type DocumentWriter func(w io.Writer, doc *Document) error
+
+// Write writes the document to an io.Writer using the given writer. See renderers/ for an overview of implementations of canvas.DocumentWriter.
+func (doc *Document) Write(w io.Writer, writer DocumentWriter) error {
+	return writer(w, doc)
+}
+
+// WriteFile writes the document to a file using the given writer. See renderers/ for an overview of implementations of canvas.DocumentWriter.
+func (doc *Document) WriteFile(filename string, writer DocumentWriter) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err = writer(f, doc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteFiles writes each page to a separate file using the given (single-page) writer. The filename is a format string for the page number starting at one if it contains a % verb, such as page%03d.png, otherwise the page number is inserted before the extension, such as page-1.png.
+func (doc *Document) WriteFiles(filename string, writer Writer) error {
+	for i, page := range doc.Pages {
+		if err := page.WriteFile(PageFilename(filename, i+1), writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PageFilename returns the filename of the page with the given number, see Document.WriteFiles.
+func PageFilename(filename string, number int) string {
+	if strings.Contains(filename, "%") {
+		return fmt.Sprintf(filename, number)
+	}
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filename, ext), number, ext)
+}
@@ -0,0 +1,41 @@
+package canvas
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestDocument(t *testing.T) {
+	doc := NewDocument()
+	c := doc.NewPage(210.0, 297.0)
+	test.T(t, doc.Len(), 1)
+	test.That(t, doc.Pages[0].Canvas == c)
+
+	page := doc.AddPage(New(100.0, 50.0), "second")
+	doc.NewPage(100.0, 50.0)
+	doc.NewPage(100.0, 50.0)
+	page.Metadata.ID = "p2" // page stays valid after adding pages
+	test.T(t, doc.Len(), 4)
+	test.T(t, doc.Pages[1].Name, "second")
+	test.T(t, doc.Pages[1].Metadata.ID, "p2")
+
+	buf := &bytes.Buffer{}
+	err := doc.Write(buf, func(w io.Writer, doc *Document) error {
+		for _, page := range doc.Pages {
+			w.Write([]byte(page.Name + ";"))
+		}
+		return nil
+	})
+	test.Error(t, err)
+	test.String(t, buf.String(), ";second;;;")
+}
+
+func TestPageFilename(t *testing.T) {
+	test.String(t, PageFilename("out.png", 1), "out-1.png")
+	test.String(t, PageFilename("dir/out.tar.svg", 12), "dir/out.tar-12.svg")
+	test.String(t, PageFilename("out", 2), "out-2")
+	test.String(t, PageFilename("page%03d.png", 7), "page007.png")
+}
@@ -233,6 +233,9 @@ type Font struct {
 	shaper     text.Shaper
 	variations string
 	features   string
+
+	hinterOnce sync.Once
+	fontHinter *fontHinter
 }
 
 // LoadLocalFont loads a font from the system's fonts.
@@ -612,7 +615,7 @@ type FontFace struct {
 
 	Fill    Paint
 	Deco    []FontDecorator
-	Hinting font.Hinting
+	Hinting font.Hinting // grid-fits glyphs vertically when rasterizing, using TrueType instructions or the autohinter for other fonts
 
 	// faux styles for bold, italic, and sub- and superscript
 	FauxBold, FauxItalic float64
@@ -734,19 +737,31 @@ func (face *FontFace) heights(mode WritingMode) (float64, float64, float64, floa
 
 // ToPath converts a string to its glyph paths.
 func (face *FontFace) ToPath(s string) (*Path, float64) {
-	return face.toPath(face.Glyphs(s), face.PPEM(DefaultResolution))
+	return face.toPath(face.Glyphs(s), 0.0)
 }
 
-func (face *FontFace) toPath(glyphs []text.Glyph, ppem uint16) (*Path, float64) {
+// toPath converts glyphs to their paths, which are grid-fitted vertically when ppem (pixels per EM) is non-zero, see FontFace.Hinting.
+func (face *FontFace) toPath(glyphs []text.Glyph, ppem float64) (*Path, float64) {
 	p := &Path{}
 	f := face.MmPerEm
 	x, y := face.XOffset, face.YOffset
 	for _, glyph := range glyphs {
-		_ = face.Font.GlyphPath(p, glyph.ID, ppem, f*float64(x+glyph.XOffset), f*float64(y+glyph.YOffset), f, font.NoHinting)
+		face.glyphPath(p, glyph.ID, ppem, f*float64(x+glyph.XOffset), f*float64(y+glyph.YOffset))
 		x += glyph.XAdvance
 		y += glyph.YAdvance
 	}
+	return face.fauxStyle(p), face.MmPerEm * float64(x)
+}
+
+// GlyphToPath converts a single glyph to its path with its origin at (0,0), including the faux bold and italic styles but not the offsets of the font face. This allows renderers to cache glyph outlines, see ToPath for converting a string. If ppem (pixels per EM) is non-zero, the glyph is grid-fitted vertically for rasterization at that size, unless hinting is disabled for the font face.
+func (face *FontFace) GlyphToPath(id uint16, ppem float64) *Path {
+	p := &Path{}
+	face.glyphPath(p, id, ppem, 0.0, 0.0)
+	return face.fauxStyle(p)
+}
 
+// fauxStyle applies the faux bold and italic styles to a path of glyphs.
+func (face *FontFace) fauxStyle(p *Path) *Path {
 	if face.FauxBold != 0.0 {
 		d := face.FauxBold * face.Size
 		if face.Font.IsTrueType {
@@ -763,7 +778,7 @@ func (face *FontFace) toPath(glyphs []text.Glyph, ppem uint16) (*Path, float64)
 	if face.FauxItalic != 0.0 {
 		p = p.Transform(Identity.Shear(face.FauxItalic, 0.0))
 	}
-	return p, face.MmPerEm * float64(x)
+	return p
 }
 
 // Decorate will return the decoration path over a given width in millimeters.
@@ -777,7 +792,7 @@ func (face *FontFace) RenderTo(r Renderer, m Matrix, s string, resolution Resolu
 }
 
 func (face *FontFace) renderTo(r Renderer, m Matrix, glyphs []text.Glyph, ppem uint16) {
-	p, width := face.toPath(glyphs, ppem)
+	p, width := face.toPath(glyphs, 0.0)
 	if ppem != 0 && face.Hinting != font.NoHinting && !m.HasRotation() {
 		// grid-align vertically on pixel raster, this improves font sharpness
 		dpmm := float64(ppem) / face.MmPerEm / float64(face.Font.Head.UnitsPerEm)
@@ -0,0 +1,242 @@
+package canvas
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tdewolff/font"
+)
+
+// blueZone is a vertical zone in font units of which the edges are aligned together, such as the baseline or x-height including the overshoot of round glyphs. The flat position is aligned to the pixel grid.
+type blueZone struct {
+	flat, overshoot float64
+}
+
+// top returns true if the zone aligns the top edges of glyphs.
+func (zone blueZone) top() bool {
+	return zone.flat < zone.overshoot
+}
+
+// autohintEdge is a horizontal edge of a glyph outline in font units, where top is true if the ink is below the edge. The fitted position is in pixels.
+type autohintEdge struct {
+	y, x0, x1 float64
+	top       bool
+	fit       float64
+	fixed     bool
+}
+
+// autohinter grid-fits glyph outlines without hinting instructions, such as CFF outlines. It detects the horizontal edges of a glyph, aligns edges in blue zones to the pixel grid, keeps the width of horizontal stems a whole number of pixels, and interpolates all other points between the fitted edges.
+type autohinter struct {
+	upem    float64
+	blues   []blueZone
+	fuzz    float64 // tolerance for edges to be inside a blue zone
+	maxStem float64 // maximum height of horizontal stems
+}
+
+func newAutohinter(sfnt *font.SFNT) *autohinter {
+	upem := float64(sfnt.Head.UnitsPerEm)
+	a := &autohinter{
+		upem:    upem,
+		maxStem: 0.12 * upem,
+	}
+	if sfnt.IsCFF {
+		if private, err := sfnt.CFF.PrivateDICT(0); err == nil && 2 <= len(private.BlueValues) {
+			// blue values are delta encoded, the first pair is the baseline zone and others are top zones
+			blues := cumulativeSum(private.BlueValues)
+			for i := 0; i+1 < len(blues); i += 2 {
+				if i == 0 {
+					a.blues = append(a.blues, blueZone{blues[1], blues[0]})
+				} else {
+					a.blues = append(a.blues, blueZone{blues[i], blues[i+1]})
+				}
+			}
+			others := cumulativeSum(private.OtherBlues)
+			for i := 0; i+1 < len(others); i += 2 {
+				a.blues = append(a.blues, blueZone{others[i+1], others[i]})
+			}
+			a.fuzz = math.Max(private.BlueFuzz, upem/100.0)
+			if 0.0 < private.StdHW {
+				a.maxStem = 1.5 * private.StdHW
+			}
+		}
+	}
+	if len(a.blues) == 0 {
+		// derive blue zones from the font metrics
+		overshoot := upem / 64.0
+		a.blues = append(a.blues, blueZone{0.0, -overshoot})
+		if descender := float64(sfnt.Hhea.Descender); descender < 0.0 {
+			a.blues = append(a.blues, blueZone{descender, descender - overshoot})
+		}
+		if xHeight := float64(sfnt.OS2.SxHeight); 0.0 < xHeight {
+			a.blues = append(a.blues, blueZone{xHeight, xHeight + overshoot})
+		}
+		if capHeight := float64(sfnt.OS2.SCapHeight); 0.0 < capHeight {
+			a.blues = append(a.blues, blueZone{capHeight, capHeight + overshoot})
+		}
+		a.fuzz = overshoot / 2.0
+	}
+	return a
+}
+
+func cumulativeSum(vs []float64) []float64 {
+	sums := make([]float64, len(vs))
+	sum := 0.0
+	for i, v := range vs {
+		sum += v
+		sums[i] = sum
+	}
+	return sums
+}
+
+// edges returns the horizontal edges of a glyph outline in font units. These are horizontal lines and the ends of curves with a horizontal tangent.
+func (a *autohinter) edges(p *Path) []autohintEdge {
+	// the ink is to the left of the direction of travel for counter-clockwise outlines
+	area := 0.0
+	for scanner := p.Scanner(); scanner.Scan(); {
+		if scanner.Cmd() != MoveToCmd {
+			start, end := scanner.Start(), scanner.End()
+			area += start.X*end.Y - end.X*start.Y
+		}
+	}
+	orientation := 1.0
+	if area < 0.0 {
+		orientation = -1.0
+	}
+
+	edges := []autohintEdge{}
+	add := func(y, x0, x1 float64) {
+		if x0 == x1 {
+			return
+		}
+		edges = append(edges, autohintEdge{
+			y:   y,
+			x0:  math.Min(x0, x1),
+			x1:  math.Max(x0, x1),
+			top: (x1-x0)*orientation < 0.0,
+		})
+	}
+	horizontal := func(a, b Point) bool {
+		return math.Abs(b.Y-a.Y) <= 0.05*math.Abs(b.X-a.X)
+	}
+	for scanner := p.Scanner(); scanner.Scan(); {
+		start, end := scanner.Start(), scanner.End()
+		switch scanner.Cmd() {
+		case LineToCmd, CloseCmd:
+			if horizontal(start, end) {
+				add((start.Y+end.Y)/2.0, start.X, end.X)
+			}
+		case QuadToCmd, CubeToCmd:
+			cp1, cp2 := scanner.CP1(), scanner.CP2()
+			if scanner.Cmd() == QuadToCmd {
+				cp2 = cp1
+			}
+			if horizontal(start, cp1) {
+				add(start.Y, start.X, cp1.X)
+			}
+			if horizontal(cp2, end) {
+				add(end.Y, cp2.X, end.X)
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].y < edges[j].y
+	})
+
+	// merge edges at the same height and in the same direction
+	merged := edges[:0]
+	for _, edge := range edges {
+		k := len(merged) - 1
+		for 0 <= k && edge.y-merged[k].y <= a.upem/250.0 && merged[k].top != edge.top {
+			k--
+		}
+		if 0 <= k && edge.y-merged[k].y <= a.upem/250.0 {
+			merged[k].x0 = math.Min(merged[k].x0, edge.x0)
+			merged[k].x1 = math.Max(merged[k].x1, edge.x1)
+		} else {
+			merged = append(merged, edge)
+		}
+	}
+	return merged
+}
+
+// hint returns a function that maps the vertical coordinates of a glyph outline in font units, so that its edges are aligned to the pixel grid for the given pixels per EM.
+func (a *autohinter) hint(p *Path, ppem float64) func(float64) float64 {
+	s := ppem / a.upem // pixels per font unit
+	edges := a.edges(p)
+
+	// align edges in blue zones
+	for i, edge := range edges {
+		for _, zone := range a.blues {
+			lo, hi := math.Min(zone.flat, zone.overshoot), math.Max(zone.flat, zone.overshoot)
+			if edge.top == zone.top() && lo-a.fuzz <= edge.y && edge.y <= hi+a.fuzz {
+				// overshoots of less than half a pixel are suppressed
+				edges[i].fit = math.Round(zone.flat*s) + math.Round((edge.y-zone.flat)*s)
+				edges[i].fixed = true
+				break
+			}
+		}
+	}
+
+	// keep horizontal stems a whole number of pixels, anchored at an aligned edge if possible
+	stem := func(i int) int {
+		bottom, k := edges[i], -1
+		if bottom.top {
+			return -1
+		}
+		for j := i + 1; j < len(edges) && edges[j].y-bottom.y <= a.maxStem; j++ {
+			if edges[j].top && bottom.y < edges[j].y && edges[j].x0 < bottom.x1 && bottom.x0 < edges[j].x1 {
+				k = j
+				break
+			}
+		}
+		return k
+	}
+	for pass := 0; pass < 2; pass++ {
+		for i := range edges {
+			j := stem(i)
+			if j == -1 || edges[i].fixed && edges[j].fixed {
+				continue
+			}
+			w := math.Max(1.0, math.Round((edges[j].y-edges[i].y)*s))
+			if edges[i].fixed {
+				edges[j].fit, edges[j].fixed = edges[i].fit+w, true
+			} else if edges[j].fixed {
+				edges[i].fit, edges[i].fixed = edges[j].fit-w, true
+			} else if pass == 1 {
+				center := (edges[i].y + edges[j].y) / 2.0 * s
+				edges[i].fit = math.Round(center - w/2.0)
+				edges[j].fit = edges[i].fit + w
+				edges[i].fixed, edges[j].fixed = true, true
+			}
+		}
+	}
+
+	// round remaining edges and keep the order of edges
+	orig, fit := []float64{}, []float64{}
+	for _, edge := range edges {
+		if !edge.fixed {
+			edge.fit = math.Round(edge.y * s)
+		}
+		if n := len(orig); 0 < n && orig[n-1] == edge.y {
+			continue
+		} else if 0 < n {
+			edge.fit = math.Max(edge.fit, fit[n-1]*s)
+		}
+		orig = append(orig, edge.y)
+		fit = append(fit, edge.fit/s)
+	}
+
+	return func(y float64) float64 {
+		n := len(orig)
+		if n == 0 {
+			return y
+		} else if y <= orig[0] {
+			return y + fit[0] - orig[0]
+		} else if orig[n-1] <= y {
+			return y + fit[n-1] - orig[n-1]
+		}
+		i := sort.SearchFloat64s(orig, y)
+		t := (y - orig[i-1]) / (orig[i] - orig[i-1])
+		return fit[i-1] + t*(fit[i]-fit[i-1])
+	}
+}
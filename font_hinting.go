@@ -0,0 +1,1808 @@
+package canvas
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/tdewolff/font"
+)
+
+// fontHinter holds the hinting state of a font, which is created when glyphs are first rasterized at a known size. TrueType fonts with instructions are hinted by executing their bytecode, other fonts (such as CFF) and glyphs that fail to execute are hinted by the autohinter.
+type fontHinter struct {
+	tt   *ttHinter // nil if the font has no TrueType instructions
+	auto *autohinter
+}
+
+// hinter returns the hinting state of the font, creating it when first called.
+func (f *Font) hinter() *fontHinter {
+	f.hinterOnce.Do(func() {
+		f.fontHinter = &fontHinter{
+			auto: newAutohinter(f.SFNT),
+		}
+		if f.IsTrueType && (0 < len(f.Tables["fpgm"]) || 0 < len(f.Tables["prep"]) || 0 < f.Maxp.MaxSizeOfInstructions) {
+			if tt, err := newTTHinter(f.SFNT); err == nil {
+				f.fontHinter.tt = tt
+			}
+		}
+	})
+	return f.fontHinter
+}
+
+// hintedGlyphPath appends the glyph outline to p, grid-fitted vertically for the given pixels per EM, with its origin at (x,y) and scaled by f from font units.
+func (f *Font) hintedGlyphPath(p *Path, id uint16, ppem, x, y, scale float64) error {
+	hinter := f.hinter()
+	if hinter.tt != nil {
+		if g, err := hinter.tt.hint(id, ppem); err == nil {
+			g.toPath(p, x, y, scale)
+			return nil
+		}
+	}
+
+	q := &Path{}
+	if err := f.GlyphPath(q, id, 0, 0.0, 0.0, 1.0, font.NoHinting); err != nil {
+		return err
+	}
+	remap := hinter.auto.hint(q, ppem)
+	q = q.TransformFunc(func(qx, qy float64) (float64, float64) {
+		return x + scale*qx, y + scale*remap(qy)
+	})
+	p.d = append(p.d, q.d...)
+	return nil
+}
+
+// glyphPath appends the glyph outline to p with its origin at (x,y). It is grid-fitted when ppem (pixels per EM) is non-zero and hinting is enabled for the font face.
+func (face *FontFace) glyphPath(p *Path, id uint16, ppem, x, y float64) {
+	if ppem != 0.0 && face.Hinting != font.NoHinting {
+		if err := face.Font.hintedGlyphPath(p, id, ppem, x, y, face.MmPerEm); err == nil {
+			return
+		}
+	}
+	_ = face.Font.GlyphPath(p, id, 0, x, y, face.MmPerEm, font.NoHinting)
+}
+
+// hintingPPEM returns the exact pixels per EM of the font face at the given resolution, or zero if the resolution is unknown.
+func (face *FontFace) hintingPPEM(resolution Resolution) float64 {
+	return resolution.DPMM() * face.MmPerEm * float64(face.Font.Head.UnitsPerEm)
+}
+
+////////////////////////////////////////////////////////////////
+
+const (
+	ttMaxSteps     = 1000000 // maximum number of executed instructions per program
+	ttMaxCallDepth = 64
+	ttMaxSizes     = 16 // maximum number of cached sizes
+)
+
+// ttPoint is a point in 26.6 fixed-point pixels, or in font units for the original outline.
+type ttPoint struct {
+	X, Y int32
+}
+
+// point flags
+const (
+	ttOnCurve uint8 = 1 << iota
+	ttTouchedX
+	ttTouchedY
+)
+
+// ttZone holds the points of the twilight zone or the glyph zone. The current points are being grid-fitted, the original points are scaled but unhinted, and the font-unit points are used to measure original distances.
+type ttZone struct {
+	cur, orig, orus []ttPoint
+	flags           []uint8
+	ends            []int // index of the last point of each contour
+}
+
+func newTTZone(n int) *ttZone {
+	return &ttZone{
+		cur:   make([]ttPoint, n),
+		orig:  make([]ttPoint, n),
+		orus:  make([]ttPoint, n),
+		flags: make([]uint8, n),
+	}
+}
+
+func (z *ttZone) copy() *ttZone {
+	return &ttZone{
+		cur:   append([]ttPoint{}, z.cur...),
+		orig:  append([]ttPoint{}, z.orig...),
+		orus:  append([]ttPoint{}, z.orus...),
+		flags: append([]uint8{}, z.flags...),
+		ends:  append([]int{}, z.ends...),
+	}
+}
+
+// ttGraphicsState is the graphics state of the TrueType interpreter. Vectors are unit vectors in 2.14 fixed-point, distances are in 26.6 fixed-point pixels.
+type ttGraphicsState struct {
+	pv, fv, dv       [2]int32 // projection, freedom, and dual projection vector
+	rp, zp           [3]int32 // reference points and zone pointers
+	loop             int32
+	minDist          int32
+	cvtCutIn         int32
+	singleWidthCutIn int32
+	singleWidth      int32
+	deltaBase        int32
+	deltaShift       int32
+	roundPeriod      int32 // zero if rounding is off
+	roundPhase       int32
+	roundThreshold   int32
+	autoFlip         bool
+	instructControl  int32
+}
+
+var ttDefaultGraphicsState = ttGraphicsState{
+	pv:             [2]int32{0x4000, 0},
+	fv:             [2]int32{0x4000, 0},
+	dv:             [2]int32{0x4000, 0},
+	zp:             [3]int32{1, 1, 1},
+	loop:           1,
+	minDist:        64,
+	cvtCutIn:       68, // 17/16 pixels
+	deltaBase:      9,
+	deltaShift:     3,
+	roundPeriod:    64,
+	roundThreshold: 32,
+	autoFlip:       true,
+}
+
+// ttSize is the state of the TrueType interpreter after running the control value program for a given scale.
+type ttSize struct {
+	cvt, storage []int32
+	twilight     *ttZone
+	gs           ttGraphicsState
+}
+
+// ttFrame is a function call frame of the TrueType interpreter.
+type ttFrame struct {
+	program []byte // caller's program
+	pc      int    // caller's program counter
+	body    []byte // function body including ENDF
+	count   int32  // number of remaining calls for LOOPCALL
+}
+
+// ttHinter is a TrueType bytecode interpreter that grid-fits glyph outlines by executing the font program (fpgm), the control value program (prep), and the glyph programs. It follows the behaviour of the v35 interpreter of FreeType.
+type ttHinter struct {
+	sync.Mutex
+	sfnt      *font.SFNT
+	upem      int32
+	cvtUnits  []int16 // control values in font units
+	functions map[int32][]byte
+	idefs     map[uint8][]byte
+	maxStack  int
+	sizes     map[int32]*ttSize
+
+	// execution state
+	scale   int32 // pixels per EM in 26.6
+	ppem    int32
+	cvt     []int32
+	storage []int32
+	gs      ttGraphicsState
+	zones   [2]*ttZone // twilight and glyph zone
+	stack   []int32
+	steps   int
+	inPrep  bool
+	err     error
+}
+
+func newTTHinter(sfnt *font.SFNT) (*ttHinter, error) {
+	h := &ttHinter{
+		sfnt:      sfnt,
+		upem:      int32(sfnt.Head.UnitsPerEm),
+		functions: map[int32][]byte{},
+		idefs:     map[uint8][]byte{},
+		maxStack:  max(int(sfnt.Maxp.MaxStackElements), 256) + 32,
+		sizes:     map[int32]*ttSize{},
+	}
+	if h.upem == 0 {
+		return nil, fmt.Errorf("truetype hinting: bad units per EM")
+	}
+	cvt := sfnt.Tables["cvt "]
+	h.cvtUnits = make([]int16, len(cvt)/2)
+	for i := range h.cvtUnits {
+		h.cvtUnits[i] = int16(binary.BigEndian.Uint16(cvt[2*i:]))
+	}
+
+	// run the font program, which defines functions and instructions
+	h.gs = ttDefaultGraphicsState
+	h.zones = [2]*ttZone{newTTZone(0), newTTZone(0)}
+	h.storage = make([]int32, sfnt.Maxp.MaxStorage)
+	if err := h.exec(sfnt.Tables["fpgm"]); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// size returns the interpreter state after running the control value program for the given scale.
+func (h *ttHinter) size(scale int32) (*ttSize, error) {
+	if size, ok := h.sizes[scale]; ok {
+		return size, nil
+	}
+
+	h.scale = scale
+	h.ppem = (scale + 32) >> 6
+	h.cvt = make([]int32, len(h.cvtUnits))
+	for i, v := range h.cvtUnits {
+		h.cvt[i] = h.scaleUnits(int32(v))
+	}
+	h.storage = make([]int32, h.sfnt.Maxp.MaxStorage)
+	h.zones = [2]*ttZone{newTTZone(int(h.sfnt.Maxp.MaxTwilightPoints)), newTTZone(0)}
+	h.gs = ttDefaultGraphicsState
+
+	h.inPrep = true
+	err := h.exec(h.sfnt.Tables["prep"])
+	h.inPrep = false
+	if err != nil {
+		return nil, err
+	}
+
+	// the control value program may not change the following state for glyph programs
+	gs := h.gs
+	gs.pv, gs.fv, gs.dv = ttDefaultGraphicsState.pv, ttDefaultGraphicsState.fv, ttDefaultGraphicsState.dv
+	gs.rp, gs.zp = ttDefaultGraphicsState.rp, ttDefaultGraphicsState.zp
+	gs.loop = ttDefaultGraphicsState.loop
+
+	if ttMaxSizes <= len(h.sizes) {
+		clear(h.sizes)
+	}
+	size := &ttSize{
+		cvt:      h.cvt,
+		storage:  h.storage,
+		twilight: h.zones[0],
+		gs:       gs,
+	}
+	h.sizes[scale] = size
+	return size, nil
+}
+
+// scaleUnits scales a distance in font units to 26.6 pixels.
+func (h *ttHinter) scaleUnits(v int32) int32 {
+	return int32(ttMulDiv(int64(v), int64(h.scale), int64(h.upem)))
+}
+
+// ttGlyph is a hinted glyph outline. Points are in 26.6 pixels, and the unhinted points are in font units.
+type ttGlyph struct {
+	points   []ttPoint
+	ux, uy   []float64
+	onCurve  []bool
+	ends     []int // index of the last point of each contour
+	scale    int32 // pixels per EM in 26.6
+	upem     int32
+	phantoms [4]ttPoint // hinted phantom points
+}
+
+// hint returns the glyph outline grid-fitted for the given pixels per EM.
+func (h *ttHinter) hint(id uint16, ppem float64) (*ttGlyph, error) {
+	scale := int32(ppem*64.0 + 0.5)
+	if scale <= 0 {
+		return nil, fmt.Errorf("truetype hinting: bad size")
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	size, err := h.size(scale)
+	if err != nil {
+		return nil, err
+	}
+
+	// glyph programs start from the state after the control value program
+	h.scale = scale
+	h.ppem = (scale + 32) >> 6
+	h.cvt = append(h.cvt[:0:0], size.cvt...)
+	h.storage = append(h.storage[:0:0], size.storage...)
+	h.zones[0] = size.twilight.copy()
+	h.steps = 0
+	g, err := h.load(id, size, 0)
+	if err != nil {
+		return nil, err
+	}
+	g.scale, g.upem = scale, h.upem
+	return g, nil
+}
+
+// load loads and hints a simple or composite glyph.
+func (h *ttHinter) load(id uint16, size *ttSize, depth int) (*ttGlyph, error) {
+	b := h.sfnt.Glyf.Get(id)
+	if b == nil {
+		return nil, fmt.Errorf("truetype hinting: bad glyph ID %v", id)
+	} else if 0 < len(b) && len(b) < 10 {
+		return nil, fmt.Errorf("truetype hinting: bad glyph %v", id)
+	} else if len(b) == 0 || 0 <= int16(binary.BigEndian.Uint16(b)) {
+		contour, err := h.sfnt.Glyf.Contour(id)
+		if err != nil {
+			return nil, err
+		}
+
+		n := len(contour.XCoordinates)
+		z := newTTZone(n + 4)
+		for i := 0; i < n; i++ {
+			z.orus[i] = ttPoint{int32(contour.XCoordinates[i]), int32(contour.YCoordinates[i])}
+			if contour.OnCurve[i] {
+				z.flags[i] = ttOnCurve
+			}
+		}
+		z.ends = make([]int, len(contour.EndPoints))
+		for i, end := range contour.EndPoints {
+			z.ends[i] = int(end)
+		}
+		var xMin, yMax int16
+		if 0 < len(b) {
+			xMin = int16(binary.BigEndian.Uint16(b[2:]))
+			yMax = int16(binary.BigEndian.Uint16(b[8:]))
+		}
+		copy(z.orus[n:], h.phantoms(id, xMin, yMax))
+		for i, p := range z.orus {
+			z.orig[i] = ttPoint{h.scaleUnits(p.X), h.scaleUnits(p.Y)}
+		}
+
+		// shift the outline so that the first phantom point is on the grid
+		dx := ttRound(z.orig[n].X) - z.orig[n].X
+		for i := range z.orig {
+			z.orig[i].X += dx
+		}
+		copy(z.cur, z.orig)
+		z.cur[n+1].X = ttRound(z.cur[n+1].X)
+		z.cur[n+3].Y = ttRound(z.cur[n+3].Y)
+
+		if err := h.runGlyph(contour.Instructions, z, size); err != nil {
+			return nil, err
+		}
+
+		g := &ttGlyph{
+			points:  z.cur[:n],
+			ux:      make([]float64, n),
+			uy:      make([]float64, n),
+			onCurve: make([]bool, n),
+			ends:    z.ends,
+		}
+		for i := 0; i < n; i++ {
+			g.ux[i], g.uy[i] = float64(z.orus[i].X), float64(z.orus[i].Y)
+			g.onCurve[i] = contour.OnCurve[i]
+		}
+		copy(g.phantoms[:], z.cur[n:])
+		return g, nil
+	}
+	return h.loadComposite(id, b, size, depth)
+}
+
+// loadComposite loads and hints a composite glyph, where each component is hinted separately before the instructions of the composite glyph are run.
+func (h *ttHinter) loadComposite(id uint16, b []byte, size *ttSize, depth int) (*ttGlyph, error) {
+	if 7 < depth {
+		return nil, fmt.Errorf("truetype hinting: composite glyphs too deeply nested")
+	}
+
+	g := &ttGlyph{}
+	xMin := int16(binary.BigEndian.Uint16(b[2:]))
+	yMax := int16(binary.BigEndian.Uint16(b[8:]))
+	var phantoms [4]ttPoint
+	for i, p := range h.phantoms(id, xMin, yMax) {
+		phantoms[i] = ttPoint{h.scaleUnits(p.X), h.scaleUnits(p.Y)}
+	}
+	phantoms[1].X = ttRound(phantoms[1].X)
+	phantoms[3].Y = ttRound(phantoms[3].Y)
+
+	pos := 10
+	hasInstructions := false
+	for {
+		if len(b) < pos+4 {
+			return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+		}
+		flags := binary.BigEndian.Uint16(b[pos:])
+		subID := binary.BigEndian.Uint16(b[pos+2:])
+		pos += 4
+
+		var arg1, arg2 int32
+		if flags&0x0001 != 0 { // ARG_1_AND_2_ARE_WORDS
+			if len(b) < pos+4 {
+				return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+			}
+			if flags&0x0002 != 0 { // ARGS_ARE_XY_VALUES
+				arg1, arg2 = int32(int16(binary.BigEndian.Uint16(b[pos:]))), int32(int16(binary.BigEndian.Uint16(b[pos+2:])))
+			} else {
+				arg1, arg2 = int32(binary.BigEndian.Uint16(b[pos:])), int32(binary.BigEndian.Uint16(b[pos+2:]))
+			}
+			pos += 4
+		} else {
+			if len(b) < pos+2 {
+				return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+			}
+			if flags&0x0002 != 0 {
+				arg1, arg2 = int32(int8(b[pos])), int32(int8(b[pos+1]))
+			} else {
+				arg1, arg2 = int32(b[pos]), int32(b[pos+1])
+			}
+			pos += 2
+		}
+
+		// transformation in 2.14 fixed-point
+		t := [4]int32{0x4000, 0, 0, 0x4000}
+		transform := false
+		readF2Dot14 := func() int32 {
+			v := int32(int16(binary.BigEndian.Uint16(b[pos:])))
+			pos += 2
+			return v
+		}
+		if flags&0x0008 != 0 { // WE_HAVE_A_SCALE
+			if len(b) < pos+2 {
+				return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+			}
+			t[0] = readF2Dot14()
+			t[3] = t[0]
+			transform = true
+		} else if flags&0x0040 != 0 { // WE_HAVE_AN_X_AND_Y_SCALE
+			if len(b) < pos+4 {
+				return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+			}
+			t[0], t[3] = readF2Dot14(), readF2Dot14()
+			transform = true
+		} else if flags&0x0080 != 0 { // WE_HAVE_A_TWO_BY_TWO
+			if len(b) < pos+8 {
+				return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+			}
+			t[0], t[1], t[2], t[3] = readF2Dot14(), readF2Dot14(), readF2Dot14(), readF2Dot14()
+			transform = true
+		}
+
+		sub, err := h.load(subID, size, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if transform {
+			for i, p := range sub.points {
+				sub.points[i] = ttPoint{ttMulF2Dot14(p.X, t[0]) + ttMulF2Dot14(p.Y, t[2]), ttMulF2Dot14(p.X, t[1]) + ttMulF2Dot14(p.Y, t[3])}
+				ux, uy := sub.ux[i], sub.uy[i]
+				sub.ux[i] = (ux*float64(t[0]) + uy*float64(t[2])) / 0x4000
+				sub.uy[i] = (ux*float64(t[1]) + uy*float64(t[3])) / 0x4000
+			}
+		}
+
+		var dx, dy int32
+		var udx, udy float64
+		if flags&0x0002 != 0 {
+			udx, udy = float64(arg1), float64(arg2)
+			dx, dy = h.scaleUnits(arg1), h.scaleUnits(arg2)
+			if flags&0x0004 != 0 { // ROUND_XY_TO_GRID
+				dx, dy = ttRound(dx), ttRound(dy)
+			}
+		} else {
+			// match a point of the composite glyph to a point of the component
+			if len(g.points) <= int(arg1) || len(sub.points) <= int(arg2) {
+				return nil, fmt.Errorf("truetype hinting: bad point matching in composite glyph %v", id)
+			}
+			p, q := g.points[arg1], sub.points[arg2]
+			dx, dy = p.X-q.X, p.Y-q.Y
+			udx, udy = g.ux[arg1]-sub.ux[arg2], g.uy[arg1]-sub.uy[arg2]
+		}
+
+		offset := len(g.points)
+		for i, p := range sub.points {
+			g.points = append(g.points, ttPoint{p.X + dx, p.Y + dy})
+			g.ux = append(g.ux, sub.ux[i]+udx)
+			g.uy = append(g.uy, sub.uy[i]+udy)
+		}
+		g.onCurve = append(g.onCurve, sub.onCurve...)
+		for _, end := range sub.ends {
+			g.ends = append(g.ends, offset+end)
+		}
+		if flags&0x0200 != 0 { // USE_MY_METRICS
+			phantoms = sub.phantoms
+		}
+		if flags&0x0100 != 0 { // WE_HAVE_INSTRUCTIONS
+			hasInstructions = true
+		}
+		if flags&0x0020 == 0 { // MORE_COMPONENTS
+			break
+		}
+	}
+	g.phantoms = phantoms
+
+	if hasInstructions {
+		if len(b) < pos+2 {
+			return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+		}
+		n := int(binary.BigEndian.Uint16(b[pos:]))
+		pos += 2
+		if len(b) < pos+n {
+			return nil, fmt.Errorf("truetype hinting: bad composite glyph %v", id)
+		}
+		instructions := b[pos : pos+n]
+
+		// the hinted points of the components are the original points of the composite glyph
+		z := newTTZone(len(g.points) + 4)
+		copy(z.cur, g.points)
+		copy(z.cur[len(g.points):], phantoms[:])
+		copy(z.orig, z.cur)
+		copy(z.orus, z.cur)
+		for i, on := range g.onCurve {
+			if on {
+				z.flags[i] = ttOnCurve
+			}
+		}
+		z.ends = g.ends
+		if err := h.runGlyph(instructions, z, size); err != nil {
+			return nil, err
+		}
+		g.points = z.cur[:len(g.points)]
+		copy(g.phantoms[:], z.cur[len(g.points):])
+	}
+	return g, nil
+}
+
+// phantoms returns the phantom points of a glyph in font units, which are the horizontal origin and advance, and the vertical origin and advance.
+func (h *ttHinter) phantoms(id uint16, xMin, yMax int16) []ttPoint {
+	x := int32(xMin) - int32(h.sfnt.Hmtx.LeftSideBearing(id))
+	advance := int32(h.sfnt.Hmtx.Advance(id))
+	top := int32(h.sfnt.Hhea.Ascender)
+	bottom := int32(h.sfnt.Hhea.Descender)
+	if h.sfnt.Vmtx != nil {
+		top = int32(yMax) + int32(h.sfnt.Vmtx.TopSideBearing(id))
+		bottom = top - int32(h.sfnt.Vmtx.Advance(id))
+	}
+	return []ttPoint{{x, 0}, {x + advance, 0}, {advance / 2, top}, {advance / 2, bottom}}
+}
+
+// runGlyph runs a glyph program on the glyph zone.
+func (h *ttHinter) runGlyph(program []byte, z *ttZone, size *ttSize) error {
+	if len(program) == 0 || size.gs.instructControl&1 != 0 {
+		return nil
+	}
+	h.gs = size.gs
+	if size.gs.instructControl&2 != 0 {
+		h.gs = ttDefaultGraphicsState
+		h.gs.instructControl = size.gs.instructControl
+	}
+	h.zones[1] = z
+	return h.exec(program)
+}
+
+// toPath appends the glyph outline to p with its origin at (x,y) and scaled by f from font units. Only the vertical coordinates are hinted.
+func (g *ttGlyph) toPath(p *Path, x, y, f float64) {
+	px := func(i int) float64 {
+		return x + f*g.ux[i]
+	}
+	py := func(i int) float64 {
+		return y + f*float64(g.points[i].Y)*float64(g.upem)/float64(g.scale)
+	}
+	mid := func(i, j int) (float64, float64) {
+		return (px(i) + px(j)) / 2.0, (py(i) + py(j)) / 2.0
+	}
+
+	start := 0
+	for _, end := range g.ends {
+		if end < start || len(g.points) <= end {
+			break
+		}
+
+		// start at the first on-curve point, or between the last and first point if all are off the curve
+		first, allOff := -1, true
+		for i := start; i <= end; i++ {
+			if g.onCurve[i] {
+				first, allOff = i, false
+				break
+			}
+		}
+		var sx, sy float64
+		if allOff {
+			sx, sy = mid(end, start)
+		} else {
+			sx, sy = px(first), py(first)
+		}
+		p.MoveTo(sx, sy)
+
+		n := end - start + 1
+		off := -1 // previous off-curve point
+		for k := 1; k <= n; k++ {
+			i := start + k - 1
+			if !allOff {
+				i = start + (first-start+k)%n
+			}
+			if g.onCurve[i] {
+				if off == -1 {
+					p.LineTo(px(i), py(i))
+				} else {
+					p.QuadTo(px(off), py(off), px(i), py(i))
+				}
+				off = -1
+			} else {
+				if off != -1 {
+					mx, my := mid(off, i)
+					p.QuadTo(px(off), py(off), mx, my)
+				}
+				off = i
+			}
+		}
+		if off != -1 {
+			p.QuadTo(px(off), py(off), sx, sy)
+		}
+		p.Close()
+		start = end + 1
+	}
+}
+
+////////////////////////////////////////////////////////////////
+
+// ttMulDiv returns a*b/c rounded half away from zero.
+func ttMulDiv(a, b, c int64) int64 {
+	if c == 0 {
+		return 0
+	}
+	sign := int64(1)
+	if a < 0 {
+		a, sign = -a, -sign
+	}
+	if b < 0 {
+		b, sign = -b, -sign
+	}
+	if c < 0 {
+		c, sign = -c, -sign
+	}
+	return sign * ((a*b + c/2) / c)
+}
+
+// ttMulF2Dot14 returns v multiplied by a 2.14 fixed-point number, rounded half away from zero.
+func ttMulF2Dot14(v, f int32) int32 {
+	return int32(ttMulDiv(int64(v), int64(f), 0x4000))
+}
+
+// ttDot returns the dot product of (x,y) and a 2.14 unit vector, rounded half away from zero.
+func ttDot(x, y int32, v [2]int32) int32 {
+	d := int64(x)*int64(v[0]) + int64(y)*int64(v[1])
+	if d < 0 {
+		return -int32((-d + 0x2000) >> 14)
+	}
+	return int32((d + 0x2000) >> 14)
+}
+
+func ttAbs[T int32 | int64](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// ttRound rounds a 26.6 value to the grid.
+func ttRound(v int32) int32 {
+	return (v + 32) &^ 63
+}
+
+// ttNormalize returns the 2.14 unit vector in the direction of (x,y).
+func ttNormalize(x, y int32) [2]int32 {
+	if x == 0 && y == 0 {
+		return [2]int32{0x4000, 0}
+	}
+	fx, fy := float64(x), float64(y)
+	d := 0x4000 / math.Hypot(fx, fy)
+	return [2]int32{int32(roundHalfAway(fx * d)), int32(roundHalfAway(fy * d))}
+}
+
+func roundHalfAway(v float64) float64 {
+	if v < 0.0 {
+		return -float64(int64(-v + 0.5))
+	}
+	return float64(int64(v + 0.5))
+}
+
+// ttNext returns the position of the instruction after the one at pc, skipping over pushed data.
+func ttNext(program []byte, pc int) int {
+	op := program[pc]
+	switch {
+	case op == 0x40: // NPUSHB
+		if pc+1 < len(program) {
+			return pc + 2 + int(program[pc+1])
+		}
+	case op == 0x41: // NPUSHW
+		if pc+1 < len(program) {
+			return pc + 2 + 2*int(program[pc+1])
+		}
+	case 0xB0 <= op && op <= 0xB7: // PUSHB
+		return pc + 1 + int(op-0xAF)
+	case 0xB8 <= op && op <= 0xBF: // PUSHW
+		return pc + 1 + 2*int(op-0xB7)
+	}
+	return pc + 1
+}
+
+////////////////////////////////////////////////////////////////
+
+func (h *ttHinter) fail(format string, args ...any) {
+	if h.err == nil {
+		h.err = fmt.Errorf("truetype hinting: "+format, args...)
+	}
+}
+
+func (h *ttHinter) push(v int32) {
+	if h.maxStack <= len(h.stack) {
+		h.fail("stack overflow")
+		return
+	}
+	h.stack = append(h.stack, v)
+}
+
+func (h *ttHinter) pop() int32 {
+	if len(h.stack) == 0 {
+		h.fail("stack underflow")
+		return 0
+	}
+	v := h.stack[len(h.stack)-1]
+	h.stack = h.stack[:len(h.stack)-1]
+	return v
+}
+
+// zone returns the zone of zone pointer i and checks that it contains point p.
+func (h *ttHinter) zone(i int, p int32) *ttZone {
+	z := h.zones[h.gs.zp[i]]
+	if p < 0 || len(z.cur) <= int(p) {
+		h.fail("point %v out of range", p)
+		return nil
+	}
+	return z
+}
+
+// project returns the distance between p and q along the projection vector.
+func (h *ttHinter) project(p, q ttPoint) int32 {
+	return ttDot(p.X-q.X, p.Y-q.Y, h.gs.pv)
+}
+
+// dualProject returns the distance between p and q along the dual projection vector.
+func (h *ttHinter) dualProject(p, q ttPoint) int32 {
+	return ttDot(p.X-q.X, p.Y-q.Y, h.gs.dv)
+}
+
+// origDist returns the original distance between point i in zone pointer 1 and point j in zone pointer 0, which is measured in font units when both are in the glyph zone.
+func (h *ttHinter) origDist(zi *ttZone, i int32, zj *ttZone, j int32) int32 {
+	if h.gs.zp[0] == 0 || h.gs.zp[1] == 0 {
+		return h.dualProject(zi.orig[i], zj.orig[j])
+	}
+	return h.scaleUnits(h.dualProject(zi.orus[i], zj.orus[j]))
+}
+
+// fDotP returns the dot product of the freedom and projection vector, which is never too small to divide by.
+func (h *ttHinter) fDotP() int64 {
+	fv, pv := h.gs.fv, h.gs.pv
+	d := (int64(fv[0])*int64(pv[0]) + int64(fv[1])*int64(pv[1])) >> 14
+	if -0x400 < d && d < 0x400 {
+		d = 0x4000
+	}
+	return d
+}
+
+// move moves a point along the freedom vector so that its projection changes by d, and marks it as touched.
+func (h *ttHinter) move(z *ttZone, i int32, d int32) {
+	fv, fdotp := h.gs.fv, h.fDotP()
+	if fv[0] != 0 {
+		z.cur[i].X += int32(ttMulDiv(int64(d), int64(fv[0]), fdotp))
+		z.flags[i] |= ttTouchedX
+	}
+	if fv[1] != 0 {
+		z.cur[i].Y += int32(ttMulDiv(int64(d), int64(fv[1]), fdotp))
+		z.flags[i] |= ttTouchedY
+	}
+}
+
+// moveOrig moves an original point along the freedom vector so that its projection changes by d.
+func (h *ttHinter) moveOrig(z *ttZone, i int32, d int32) {
+	fv, fdotp := h.gs.fv, h.fDotP()
+	z.orig[i].X += int32(ttMulDiv(int64(d), int64(fv[0]), fdotp))
+	z.orig[i].Y += int32(ttMulDiv(int64(d), int64(fv[1]), fdotp))
+}
+
+// shift shifts a point by (dx,dy) along the axes of the freedom vector.
+func (h *ttHinter) shift(z *ttZone, i int, dx, dy int32, touch bool) {
+	if h.gs.fv[0] != 0 {
+		z.cur[i].X += dx
+		if touch {
+			z.flags[i] |= ttTouchedX
+		}
+	}
+	if h.gs.fv[1] != 0 {
+		z.cur[i].Y += dy
+		if touch {
+			z.flags[i] |= ttTouchedY
+		}
+	}
+}
+
+// round rounds a distance using the round state.
+func (h *ttHinter) round(d int32) int32 {
+	period, phase, threshold := h.gs.roundPeriod, h.gs.roundPhase, h.gs.roundThreshold
+	if period == 0 {
+		return d
+	}
+	floor := func(v int32) int32 {
+		m := v % period
+		if m < 0 {
+			m += period
+		}
+		return v - m
+	}
+	if 0 <= d {
+		v := floor(d-phase+threshold) + phase
+		if v < 0 {
+			v = phase
+		}
+		return v
+	}
+	v := -floor(threshold-phase-d) - phase
+	if 0 < v {
+		v = -phase
+	}
+	return v
+}
+
+// setSuperRound sets the round state from the SROUND and S45ROUND selector, where gridPeriod is 1 or sqrt(2)/2 pixels in 2.14 fixed-point.
+func (h *ttHinter) setSuperRound(gridPeriod, selector int32) {
+	var period int32
+	switch selector & 0xC0 {
+	case 0x00:
+		period = gridPeriod / 2
+	case 0x80:
+		period = gridPeriod * 2
+	default:
+		period = gridPeriod
+	}
+
+	var phase int32
+	switch selector & 0x30 {
+	case 0x10:
+		phase = period / 4
+	case 0x20:
+		phase = period / 2
+	case 0x30:
+		phase = period * 3 / 4
+	}
+
+	threshold := period - 1
+	if selector&0x0F != 0 {
+		threshold = (selector&0x0F - 4) * period / 8
+	}
+	h.gs.roundPeriod = max(period>>8, 1)
+	h.gs.roundPhase = phase >> 8
+	h.gs.roundThreshold = threshold >> 8
+}
+
+// lineVector returns the 2.14 unit vector from point p2 in zone pointer 2 to point p1 in zone pointer 1, optionally rotated by 90 degrees counter-clockwise.
+func (h *ttHinter) lineVector(p1, p2 int32, perpendicular bool) ([2]int32, bool) {
+	z1, z2 := h.zone(1, p1), h.zone(2, p2)
+	if z1 == nil || z2 == nil {
+		return [2]int32{}, false
+	}
+	dx := z1.cur[p1].X - z2.cur[p2].X
+	dy := z1.cur[p1].Y - z2.cur[p2].Y
+	if dx == 0 && dy == 0 {
+		dx, perpendicular = 0x4000, false
+	}
+	if perpendicular {
+		dx, dy = -dy, dx
+	}
+	return ttNormalize(dx, dy), true
+}
+
+// displacement returns the displacement of the reference point used by SHP, SHC, and SHZ along the freedom vector.
+func (h *ttHinter) displacement(opcode byte) (*ttZone, int32, int32, int32) {
+	zi, rp := 1, h.gs.rp[2]
+	if opcode&1 != 0 {
+		zi, rp = 0, h.gs.rp[1]
+	}
+	z := h.zone(zi, rp)
+	if z == nil {
+		return nil, 0, 0, 0
+	}
+	d := int64(h.project(z.cur[rp], z.orig[rp]))
+	fdotp := h.fDotP()
+	return z, rp, int32(ttMulDiv(d, int64(h.gs.fv[0]), fdotp)), int32(ttMulDiv(d, int64(h.gs.fv[1]), fdotp))
+}
+
+// exec executes a program.
+func (h *ttHinter) exec(program []byte) error {
+	h.err = nil
+	h.stack = h.stack[:0]
+	frames := []ttFrame{}
+	pc := 0
+	for h.err == nil {
+		if len(program) <= pc {
+			if len(frames) != 0 {
+				h.fail("missing ENDF")
+				break
+			}
+			return nil
+		}
+		h.steps++
+		if ttMaxSteps < h.steps {
+			h.fail("too many instructions")
+			break
+		}
+
+		op := program[pc]
+		pc++
+		switch {
+		case op <= 0x05: // SVTCA, SPVTCA, SFVTCA
+			v := [2]int32{0, 0x4000}
+			if op&1 != 0 {
+				v = [2]int32{0x4000, 0}
+			}
+			if op <= 0x03 {
+				h.gs.pv, h.gs.dv = v, v
+			}
+			if op <= 0x01 || 0x04 <= op {
+				h.gs.fv = v
+			}
+		case op <= 0x09: // SPVTL, SFVTL
+			p2, p1 := h.pop(), h.pop()
+			if v, ok := h.lineVector(p1, p2, op&1 != 0); ok {
+				if op <= 0x07 {
+					h.gs.pv, h.gs.dv = v, v
+				} else {
+					h.gs.fv = v
+				}
+			}
+		case op == 0x0A || op == 0x0B: // SPVFS, SFVFS
+			y, x := h.pop(), h.pop()
+			v := ttNormalize(int32(int16(x)), int32(int16(y)))
+			if op == 0x0A {
+				h.gs.pv, h.gs.dv = v, v
+			} else {
+				h.gs.fv = v
+			}
+		case op == 0x0C: // GPV
+			h.push(h.gs.pv[0])
+			h.push(h.gs.pv[1])
+		case op == 0x0D: // GFV
+			h.push(h.gs.fv[0])
+			h.push(h.gs.fv[1])
+		case op == 0x0E: // SFVTPV
+			h.gs.fv = h.gs.pv
+		case op == 0x0F: // ISECT
+			b1, b0, a1, a0, p := h.pop(), h.pop(), h.pop(), h.pop(), h.pop()
+			za0, za1, zb0, zb1, zp := h.zone(1, a0), h.zone(1, a1), h.zone(0, b0), h.zone(0, b1), h.zone(2, p)
+			if za0 == nil || za1 == nil || zb0 == nil || zb1 == nil || zp == nil {
+				break
+			}
+			pa0, pa1, pb0, pb1 := za0.cur[a0], za1.cur[a1], zb0.cur[b0], zb1.cur[b1]
+			dbx, dby := int64(pb1.X-pb0.X), int64(pb1.Y-pb0.Y)
+			dax, day := int64(pa1.X-pa0.X), int64(pa1.Y-pa0.Y)
+			dx, dy := int64(pb0.X-pa0.X), int64(pb0.Y-pa0.Y)
+			discriminant := ttMulDiv(dax, -dby, 64) + ttMulDiv(day, dbx, 64)
+			dot := ttMulDiv(dax, dbx, 64) + ttMulDiv(day, dby, 64)
+			if ttAbs(discriminant)*19 > ttAbs(dot) {
+				v := ttMulDiv(dx, -dby, 64) + ttMulDiv(dy, dbx, 64)
+				zp.cur[p].X = pa0.X + int32(ttMulDiv(v, dax, discriminant))
+				zp.cur[p].Y = pa0.Y + int32(ttMulDiv(v, day, discriminant))
+			} else {
+				// lines are (nearly) parallel, take the middle of both lines
+				zp.cur[p].X = (pa0.X + pa1.X + pb0.X + pb1.X) / 4
+				zp.cur[p].Y = (pa0.Y + pa1.Y + pb0.Y + pb1.Y) / 4
+			}
+			zp.flags[p] |= ttTouchedX | ttTouchedY
+		case op <= 0x12: // SRP0, SRP1, SRP2
+			h.gs.rp[op-0x10] = h.pop()
+		case op <= 0x16: // SZP0, SZP1, SZP2, SZPS
+			zi := h.pop()
+			if zi != 0 && zi != 1 {
+				h.fail("bad zone %v", zi)
+			} else if op == 0x16 {
+				h.gs.zp = [3]int32{zi, zi, zi}
+			} else {
+				h.gs.zp[op-0x13] = zi
+			}
+		case op == 0x17: // SLOOP
+			n := h.pop()
+			if n < 0 {
+				h.fail("bad loop count")
+			}
+			h.gs.loop = min(n, 0xFFFF)
+		case op == 0x18: // RTG
+			h.gs.roundPeriod, h.gs.roundPhase, h.gs.roundThreshold = 64, 0, 32
+		case op == 0x19: // RTHG
+			h.gs.roundPeriod, h.gs.roundPhase, h.gs.roundThreshold = 64, 32, 32
+		case op == 0x1A: // SMD
+			h.gs.minDist = h.pop()
+		case op == 0x1B: // ELSE
+			pc = h.skip(program, pc, false)
+		case op == 0x1C: // JMPR
+			pc = h.jump(program, pc, h.pop())
+		case op == 0x1D: // SCVTCI
+			h.gs.cvtCutIn = h.pop()
+		case op == 0x1E: // SSWCI
+			h.gs.singleWidthCutIn = h.pop()
+		case op == 0x1F: // SSW
+			h.gs.singleWidth = h.scaleUnits(h.pop())
+		case op == 0x20: // DUP
+			v := h.pop()
+			h.push(v)
+			h.push(v)
+		case op == 0x21: // POP
+			h.pop()
+		case op == 0x22: // CLEAR
+			h.stack = h.stack[:0]
+		case op == 0x23: // SWAP
+			b, a := h.pop(), h.pop()
+			h.push(b)
+			h.push(a)
+		case op == 0x24: // DEPTH
+			h.push(int32(len(h.stack)))
+		case op == 0x25 || op == 0x26: // CINDEX, MINDEX
+			k := h.pop()
+			if k <= 0 || len(h.stack) < int(k) {
+				h.fail("bad stack index")
+				break
+			}
+			i := len(h.stack) - int(k)
+			v := h.stack[i]
+			if op == 0x26 {
+				h.stack = append(h.stack[:i], h.stack[i+1:]...)
+			}
+			h.push(v)
+		case op == 0x27: // ALIGNPTS
+			p2, p1 := h.pop(), h.pop()
+			z1, z2 := h.zone(1, p1), h.zone(0, p2)
+			if z1 == nil || z2 == nil {
+				break
+			}
+			d := h.project(z2.cur[p2], z1.cur[p1]) / 2
+			h.move(z1, p1, d)
+			h.move(z2, p2, -d)
+		case op == 0x29: // UTP
+			p := h.pop()
+			if z := h.zone(0, p); z != nil {
+				if h.gs.fv[0] != 0 {
+					z.flags[p] &^= ttTouchedX
+				}
+				if h.gs.fv[1] != 0 {
+					z.flags[p] &^= ttTouchedY
+				}
+			}
+		case op == 0x2A || op == 0x2B: // LOOPCALL, CALL
+			f, count := h.pop(), int32(1)
+			if op == 0x2A {
+				count = h.pop()
+			}
+			body, ok := h.functions[f]
+			if !ok {
+				h.fail("undefined function %v", f)
+			} else if ttMaxCallDepth <= len(frames) {
+				h.fail("too many nested calls")
+			} else if 0 < count {
+				frames = append(frames, ttFrame{program, pc, body, count})
+				program, pc = body, 0
+			}
+		case op == 0x2C || op == 0x89: // FDEF, IDEF
+			key := h.pop()
+			if !h.inPrep && h.scale != 0 {
+				h.fail("definition in glyph program")
+				break
+			}
+			start := pc
+			for pc < len(program) && program[pc] != 0x2D {
+				if program[pc] == 0x2C || program[pc] == 0x89 {
+					h.fail("nested definition")
+					break
+				}
+				pc = ttNext(program, pc)
+			}
+			if len(program) <= pc {
+				h.fail("missing ENDF")
+				break
+			}
+			pc++
+			if op == 0x2C {
+				h.functions[key] = program[start:pc]
+			} else {
+				h.idefs[uint8(key)] = program[start:pc]
+			}
+		case op == 0x2D: // ENDF
+			if len(frames) == 0 {
+				h.fail("ENDF outside function")
+				break
+			}
+			frame := &frames[len(frames)-1]
+			if frame.count--; 0 < frame.count {
+				pc = 0
+			} else {
+				program, pc = frame.program, frame.pc
+				frames = frames[:len(frames)-1]
+			}
+		case op == 0x2E || op == 0x2F: // MDAP
+			p := h.pop()
+			z := h.zone(0, p)
+			if z == nil {
+				break
+			}
+			d := int32(0)
+			if op&1 != 0 {
+				v := h.project(z.cur[p], ttPoint{})
+				d = h.round(v) - v
+			}
+			h.move(z, p, d)
+			h.gs.rp[0], h.gs.rp[1] = p, p
+		case op == 0x30 || op == 0x31: // IUP
+			h.iup(op&1 != 0)
+		case op == 0x32 || op == 0x33: // SHP
+			zr, _, dx, dy := h.displacement(op)
+			for ; zr != nil && 0 < h.gs.loop && h.err == nil; h.gs.loop-- {
+				p := h.pop()
+				if z := h.zone(2, p); z != nil {
+					h.shift(z, int(p), dx, dy, true)
+				}
+			}
+			h.gs.loop = 1
+		case op == 0x34 || op == 0x35: // SHC
+			c := h.pop()
+			zr, rp, dx, dy := h.displacement(op)
+			z := h.zones[h.gs.zp[2]]
+			if zr == nil {
+				break
+			} else if c < 0 || len(z.ends) <= int(c) {
+				h.fail("contour %v out of range", c)
+				break
+			}
+			start := 0
+			if 0 < c {
+				start = z.ends[c-1] + 1
+			}
+			for i := start; i <= z.ends[c] && i < len(z.cur); i++ {
+				if zr != z || int(rp) != i {
+					h.shift(z, i, dx, dy, true)
+				}
+			}
+		case op == 0x36 || op == 0x37: // SHZ
+			if zi := h.pop(); zi != 0 && zi != 1 {
+				h.fail("bad zone %v", zi)
+				break
+			}
+			zr, rp, dx, dy := h.displacement(op)
+			z := h.zones[h.gs.zp[2]]
+			if zr == nil {
+				break
+			}
+			n := len(z.cur)
+			if h.gs.zp[2] == 1 {
+				// phantom points are not shifted
+				n = 0
+				if 0 < len(z.ends) {
+					n = z.ends[len(z.ends)-1] + 1
+				}
+			}
+			for i := 0; i < n; i++ {
+				if zr != z || int(rp) != i {
+					h.shift(z, i, dx, dy, false)
+				}
+			}
+		case op == 0x38: // SHPIX
+			d := h.pop()
+			dx, dy := ttMulF2Dot14(d, h.gs.fv[0]), ttMulF2Dot14(d, h.gs.fv[1])
+			for ; 0 < h.gs.loop && h.err == nil; h.gs.loop-- {
+				p := h.pop()
+				if z := h.zone(2, p); z != nil {
+					h.shift(z, int(p), dx, dy, true)
+				}
+			}
+			h.gs.loop = 1
+		case op == 0x39: // IP
+			h.ip()
+		case op == 0x3A || op == 0x3B: // MSIRP
+			d, p := h.pop(), h.pop()
+			z0, z1 := h.zone(0, h.gs.rp[0]), h.zone(1, p)
+			if z0 == nil || z1 == nil {
+				break
+			}
+			rp0 := h.gs.rp[0]
+			if h.gs.zp[1] == 0 {
+				z1.orig[p] = z0.orig[rp0]
+				h.moveOrig(z1, p, d)
+				z1.cur[p] = z1.orig[p]
+			}
+			h.move(z1, p, d-h.project(z1.cur[p], z0.cur[rp0]))
+			h.gs.rp[1], h.gs.rp[2] = rp0, p
+			if op&1 != 0 {
+				h.gs.rp[0] = p
+			}
+		case op == 0x3C: // ALIGNRP
+			z0 := h.zone(0, h.gs.rp[0])
+			for ; z0 != nil && 0 < h.gs.loop && h.err == nil; h.gs.loop-- {
+				p := h.pop()
+				if z := h.zone(1, p); z != nil {
+					h.move(z, p, -h.project(z.cur[p], z0.cur[h.gs.rp[0]]))
+				}
+			}
+			h.gs.loop = 1
+		case op == 0x3D: // RTDG
+			h.gs.roundPeriod, h.gs.roundPhase, h.gs.roundThreshold = 32, 0, 16
+		case op == 0x3E || op == 0x3F: // MIAP
+			i, p := h.pop(), h.pop()
+			z := h.zone(0, p)
+			if z == nil {
+				break
+			} else if i < 0 || len(h.cvt) <= int(i) {
+				h.fail("control value %v out of range", i)
+				break
+			}
+			d := h.cvt[i]
+			if h.gs.zp[0] == 0 {
+				z.orig[p] = ttPoint{ttMulF2Dot14(d, h.gs.fv[0]), ttMulF2Dot14(d, h.gs.fv[1])}
+				z.cur[p] = z.orig[p]
+			}
+			v := h.project(z.cur[p], ttPoint{})
+			if op&1 != 0 {
+				if ttAbs(d-v) > h.gs.cvtCutIn {
+					d = v
+				}
+				d = h.round(d)
+			}
+			h.move(z, p, d-v)
+			h.gs.rp[0], h.gs.rp[1] = p, p
+		case op == 0x40 || op == 0x41 || 0xB0 <= op && op <= 0xBF: // NPUSHB, NPUSHW, PUSHB, PUSHW
+			var n int
+			words := op == 0x41 || 0xB8 <= op
+			if op == 0x40 || op == 0x41 {
+				if len(program) <= pc {
+					h.fail("missing push count")
+					break
+				}
+				n = int(program[pc])
+				pc++
+			} else if words {
+				n = int(op-0xB8) + 1
+			} else {
+				n = int(op-0xB0) + 1
+			}
+			size := n
+			if words {
+				size *= 2
+			}
+			if len(program) < pc+size {
+				h.fail("missing push data")
+				break
+			}
+			for i := 0; i < n; i++ {
+				if words {
+					h.push(int32(int16(binary.BigEndian.Uint16(program[pc:]))))
+					pc += 2
+				} else {
+					h.push(int32(program[pc]))
+					pc++
+				}
+			}
+		case op == 0x42: // WS
+			v, i := h.pop(), h.pop()
+			if 0 <= i && int(i) < len(h.storage) {
+				h.storage[i] = v
+			}
+		case op == 0x43: // RS
+			i, v := h.pop(), int32(0)
+			if 0 <= i && int(i) < len(h.storage) {
+				v = h.storage[i]
+			}
+			h.push(v)
+		case op == 0x44 || op == 0x70: // WCVTP, WCVTF
+			v, i := h.pop(), h.pop()
+			if op == 0x70 {
+				v = h.scaleUnits(v)
+			}
+			if 0 <= i && int(i) < len(h.cvt) {
+				h.cvt[i] = v
+			}
+		case op == 0x45: // RCVT
+			i, v := h.pop(), int32(0)
+			if 0 <= i && int(i) < len(h.cvt) {
+				v = h.cvt[i]
+			}
+			h.push(v)
+		case op == 0x46 || op == 0x47: // GC
+			p := h.pop()
+			if z := h.zone(2, p); z != nil {
+				if op&1 != 0 {
+					h.push(h.dualProject(z.orig[p], ttPoint{}))
+				} else {
+					h.push(h.project(z.cur[p], ttPoint{}))
+				}
+			}
+		case op == 0x48: // SCFS
+			v, p := h.pop(), h.pop()
+			if z := h.zone(2, p); z != nil {
+				h.move(z, p, v-h.project(z.cur[p], ttPoint{}))
+				if h.gs.zp[2] == 0 {
+					z.orig[p] = z.cur[p]
+				}
+			}
+		case op == 0x49 || op == 0x4A: // MD
+			q, p := h.pop(), h.pop()
+			zp, zq := h.zone(0, p), h.zone(1, q)
+			if zp == nil || zq == nil {
+				break
+			}
+			if op&1 != 0 {
+				h.push(h.project(zp.cur[p], zq.cur[q]))
+			} else if h.gs.zp[0] == 0 || h.gs.zp[1] == 0 {
+				h.push(h.dualProject(zp.orig[p], zq.orig[q]))
+			} else {
+				h.push(h.scaleUnits(h.dualProject(zp.orus[p], zq.orus[q])))
+			}
+		case op == 0x4B || op == 0x4C: // MPPEM, MPS
+			h.push(h.ppem)
+		case op == 0x4D: // FLIPON
+			h.gs.autoFlip = true
+		case op == 0x4E: // FLIPOFF
+			h.gs.autoFlip = false
+		case op == 0x4F: // DEBUG
+			h.pop()
+		case 0x50 <= op && op <= 0x55: // LT, LTEQ, GT, GTEQ, EQ, NEQ
+			b, a := h.pop(), h.pop()
+			var c bool
+			switch op {
+			case 0x50:
+				c = a < b
+			case 0x51:
+				c = a <= b
+			case 0x52:
+				c = a > b
+			case 0x53:
+				c = a >= b
+			case 0x54:
+				c = a == b
+			case 0x55:
+				c = a != b
+			}
+			h.pushBool(c)
+		case op == 0x56: // ODD
+			h.pushBool(h.round(h.pop())&127 == 64)
+		case op == 0x57: // EVEN
+			h.pushBool(h.round(h.pop())&127 == 0)
+		case op == 0x58: // IF
+			if h.pop() == 0 {
+				pc = h.skip(program, pc, true)
+			}
+		case op == 0x59: // EIF
+		case op == 0x5A: // AND
+			b, a := h.pop(), h.pop()
+			h.pushBool(a != 0 && b != 0)
+		case op == 0x5B: // OR
+			b, a := h.pop(), h.pop()
+			h.pushBool(a != 0 || b != 0)
+		case op == 0x5C: // NOT
+			h.pushBool(h.pop() == 0)
+		case op == 0x5D || op == 0x71 || op == 0x72: // DELTAP1, DELTAP2, DELTAP3
+			h.delta(op, false)
+		case op == 0x5E: // SDB
+			h.gs.deltaBase = h.pop()
+		case op == 0x5F: // SDS
+			if s := h.pop(); 0 <= s && s <= 6 {
+				h.gs.deltaShift = s
+			} else {
+				h.fail("bad delta shift")
+			}
+		case op == 0x60: // ADD
+			b, a := h.pop(), h.pop()
+			h.push(a + b)
+		case op == 0x61: // SUB
+			b, a := h.pop(), h.pop()
+			h.push(a - b)
+		case op == 0x62: // DIV
+			b, a := h.pop(), h.pop()
+			if b == 0 {
+				h.fail("division by zero")
+				break
+			}
+			h.push(int32(int64(a) * 64 / int64(b)))
+		case op == 0x63: // MUL
+			b, a := h.pop(), h.pop()
+			h.push(int32(ttMulDiv(int64(a), int64(b), 64)))
+		case op == 0x64: // ABS
+			h.push(ttAbs(h.pop()))
+		case op == 0x65: // NEG
+			h.push(-h.pop())
+		case op == 0x66: // FLOOR
+			h.push(h.pop() &^ 63)
+		case op == 0x67: // CEILING
+			h.push((h.pop() + 63) &^ 63)
+		case 0x68 <= op && op <= 0x6B: // ROUND
+			h.push(h.round(h.pop()))
+		case 0x6C <= op && op <= 0x6F: // NROUND
+		case 0x73 <= op && op <= 0x75: // DELTAC1, DELTAC2, DELTAC3
+			h.delta(op, true)
+		case op == 0x76: // SROUND
+			h.setSuperRound(0x4000, h.pop())
+		case op == 0x77: // S45ROUND
+			h.setSuperRound(0x2D41, h.pop())
+		case op == 0x78 || op == 0x79: // JROT, JROF
+			c, offset := h.pop(), h.pop()
+			if (c != 0) == (op == 0x78) {
+				pc = h.jump(program, pc, offset)
+			}
+		case op == 0x7A: // ROFF
+			h.gs.roundPeriod = 0
+		case op == 0x7C: // RUTG
+			h.gs.roundPeriod, h.gs.roundPhase, h.gs.roundThreshold = 64, 0, 63
+		case op == 0x7D: // RDTG
+			h.gs.roundPeriod, h.gs.roundPhase, h.gs.roundThreshold = 64, 0, 0
+		case op == 0x7E || op == 0x7F || op == 0x85 || op == 0x8D: // SANGW, AA, SCANCTRL, SCANTYPE
+			h.pop()
+		case op == 0x80: // FLIPPT
+			z := h.zones[1]
+			for ; 0 < h.gs.loop && h.err == nil; h.gs.loop-- {
+				p := h.pop()
+				if p < 0 || len(z.cur) <= int(p) {
+					h.fail("point %v out of range", p)
+					break
+				}
+				z.flags[p] ^= ttOnCurve
+			}
+			h.gs.loop = 1
+		case op == 0x81 || op == 0x82: // FLIPRGON, FLIPRGOFF
+			hi, lo := h.pop(), h.pop()
+			z := h.zones[1]
+			if lo < 0 || hi < lo || len(z.cur) <= int(hi) {
+				h.fail("point range out of range")
+				break
+			}
+			for i := lo; i <= hi; i++ {
+				if op == 0x81 {
+					z.flags[i] |= ttOnCurve
+				} else {
+					z.flags[i] &^= ttOnCurve
+				}
+			}
+		case op == 0x86 || op == 0x87: // SDPVTL
+			p2, p1 := h.pop(), h.pop()
+			z1, z2 := h.zone(1, p1), h.zone(2, p2)
+			if z1 == nil || z2 == nil {
+				break
+			}
+			perpendicular := op&1 != 0
+			dual := func(a, b ttPoint) [2]int32 {
+				dx, dy := a.X-b.X, a.Y-b.Y
+				rotate := perpendicular
+				if dx == 0 && dy == 0 {
+					dx, rotate = 0x4000, false
+				}
+				if rotate {
+					dx, dy = -dy, dx
+				}
+				return ttNormalize(dx, dy)
+			}
+			h.gs.dv = dual(z1.orig[p1], z2.orig[p2])
+			h.gs.pv = dual(z1.cur[p1], z2.cur[p2])
+		case op == 0x88: // GETINFO
+			selector, v := h.pop(), int32(0)
+			if selector&1 != 0 {
+				v = 35 // interpreter version
+			}
+			if selector&32 != 0 {
+				v |= 1 << 12 // grayscale rendering
+			}
+			h.push(v)
+		case op == 0x8A: // ROLL
+			c, b, a := h.pop(), h.pop(), h.pop()
+			h.push(b)
+			h.push(c)
+			h.push(a)
+		case op == 0x8B: // MAX
+			b, a := h.pop(), h.pop()
+			h.push(max(a, b))
+		case op == 0x8C: // MIN
+			b, a := h.pop(), h.pop()
+			h.push(min(a, b))
+		case op == 0x8E: // INSTCTRL
+			selector, v := h.pop(), h.pop()
+			if !h.inPrep || selector < 1 || 2 < selector {
+				break
+			}
+			bit := int32(1) << (selector - 1)
+			h.gs.instructControl &^= bit
+			if v != 0 {
+				h.gs.instructControl |= bit
+			}
+		case 0xC0 <= op && op <= 0xDF: // MDRP
+			h.mdrp(op)
+		case 0xE0 <= op: // MIRP
+			h.mirp(op)
+		default:
+			// undefined opcode, possibly defined by IDEF
+			body, ok := h.idefs[op]
+			if !ok {
+				h.fail("invalid opcode %#x", op)
+			} else if ttMaxCallDepth <= len(frames) {
+				h.fail("too many nested calls")
+			} else {
+				frames = append(frames, ttFrame{program, pc, body, 1})
+				program, pc = body, 0
+			}
+		}
+	}
+	return h.err
+}
+
+func (h *ttHinter) pushBool(b bool) {
+	if b {
+		h.push(1)
+	} else {
+		h.push(0)
+	}
+}
+
+// skip returns the position after the matching ELSE or EIF of a conditional block starting at pc. If toElse is false, only EIF ends the block.
+func (h *ttHinter) skip(program []byte, pc int, toElse bool) int {
+	depth := 0
+	for pc < len(program) {
+		op := program[pc]
+		next := ttNext(program, pc)
+		switch {
+		case op == 0x58: // IF
+			depth++
+		case op == 0x1B && depth == 0 && toElse: // ELSE
+			return next
+		case op == 0x59: // EIF
+			if depth == 0 {
+				return next
+			}
+			depth--
+		}
+		pc = next
+	}
+	h.fail("missing EIF")
+	return pc
+}
+
+// jump returns the position of a relative jump from the instruction before pc.
+func (h *ttHinter) jump(program []byte, pc int, offset int32) int {
+	if offset == 0 {
+		h.fail("infinite loop")
+		return pc
+	}
+	pc += int(offset) - 1
+	if pc < 0 || len(program) < pc {
+		h.fail("jump out of range")
+	}
+	return pc
+}
+
+// delta executes DELTAP1-3 and DELTAC1-3, which move points or change control values at specific pixels per EM.
+func (h *ttHinter) delta(op byte, cvt bool) {
+	base := h.gs.deltaBase
+	switch op {
+	case 0x71, 0x74:
+		base += 16
+	case 0x72, 0x75:
+		base += 32
+	}
+
+	n := h.pop()
+	for k := int32(0); k < n && h.err == nil; k++ {
+		i, arg := h.pop(), h.pop()
+		if base+(arg&0xF0)>>4 != h.ppem {
+			continue
+		}
+		d := arg&0xF - 8
+		if 0 <= d {
+			d++
+		}
+		d *= 1 << (6 - h.gs.deltaShift)
+		if cvt {
+			if 0 <= i && int(i) < len(h.cvt) {
+				h.cvt[i] += d
+			}
+		} else if z := h.zones[h.gs.zp[0]]; 0 <= i && int(i) < len(z.cur) {
+			h.move(z, i, d)
+		}
+	}
+}
+
+// ip interpolates points between reference points rp1 and rp2 so that their relative position is preserved.
+func (h *ttHinter) ip() {
+	z1, z2 := h.zone(0, h.gs.rp[1]), h.zone(1, h.gs.rp[2])
+	if z1 == nil || z2 == nil {
+		return
+	}
+	rp1, rp2 := h.gs.rp[1], h.gs.rp[2]
+	twilight := h.gs.zp[0] == 0 || h.gs.zp[1] == 0 || h.gs.zp[2] == 0
+
+	origBase := z1.orus[rp1]
+	origRange := int32(0)
+	if twilight {
+		origBase = z1.orig[rp1]
+		origRange = h.dualProject(z2.orig[rp2], origBase)
+	} else {
+		origRange = h.dualProject(z2.orus[rp2], origBase)
+	}
+	curBase := z1.cur[rp1]
+	curRange := h.project(z2.cur[rp2], curBase)
+
+	for ; 0 < h.gs.loop && h.err == nil; h.gs.loop-- {
+		p := h.pop()
+		z := h.zone(2, p)
+		if z == nil {
+			break
+		}
+		var origDist int32
+		if twilight {
+			origDist = h.dualProject(z.orig[p], origBase)
+		} else {
+			origDist = h.dualProject(z.orus[p], origBase)
+		}
+		curDist := h.project(z.cur[p], curBase)
+
+		newDist := int32(0)
+		if origDist != 0 {
+			if origRange != 0 {
+				newDist = int32(ttMulDiv(int64(origDist), int64(curRange), int64(origRange)))
+			} else if twilight {
+				newDist = origDist
+			} else {
+				newDist = h.scaleUnits(origDist)
+			}
+		}
+		h.move(z, p, newDist-curDist)
+	}
+	h.gs.loop = 1
+}
+
+// mdrp executes MDRP, which moves a point to keep its original distance to rp0.
+func (h *ttHinter) mdrp(op byte) {
+	p := h.pop()
+	z0, z1 := h.zone(0, h.gs.rp[0]), h.zone(1, p)
+	if z0 == nil || z1 == nil {
+		return
+	}
+	rp0 := h.gs.rp[0]
+	origDist := h.origDist(z1, p, z0, rp0)
+	if ttAbs(origDist-h.gs.singleWidth) < h.gs.singleWidthCutIn {
+		if 0 <= origDist {
+			origDist = h.gs.singleWidth
+		} else {
+			origDist = -h.gs.singleWidth
+		}
+	}
+
+	d := origDist
+	if op&0x04 != 0 {
+		d = h.round(origDist)
+	}
+	if op&0x08 != 0 {
+		d = h.minDist(origDist, d)
+	}
+	h.move(z1, p, d-h.project(z1.cur[p], z0.cur[rp0]))
+	h.gs.rp[1], h.gs.rp[2] = rp0, p
+	if op&0x10 != 0 {
+		h.gs.rp[0] = p
+	}
+}
+
+// mirp executes MIRP, which moves a point to the distance given by a control value from rp0.
+func (h *ttHinter) mirp(op byte) {
+	i, p := h.pop(), h.pop()
+	z0, z1 := h.zone(0, h.gs.rp[0]), h.zone(1, p)
+	if z0 == nil || z1 == nil {
+		return
+	} else if i < -1 || len(h.cvt) <= int(i) {
+		h.fail("control value %v out of range", i)
+		return
+	}
+	rp0 := h.gs.rp[0]
+	cvtDist := int32(0)
+	if i != -1 {
+		cvtDist = h.cvt[i]
+	}
+	if ttAbs(cvtDist-h.gs.singleWidth) < h.gs.singleWidthCutIn {
+		if 0 <= cvtDist {
+			cvtDist = h.gs.singleWidth
+		} else {
+			cvtDist = -h.gs.singleWidth
+		}
+	}
+
+	if h.gs.zp[1] == 0 {
+		z1.orig[p] = ttPoint{z0.orig[rp0].X + ttMulF2Dot14(cvtDist, h.gs.fv[0]), z0.orig[rp0].Y + ttMulF2Dot14(cvtDist, h.gs.fv[1])}
+		z1.cur[p] = z1.orig[p]
+	}
+	origDist := h.dualProject(z1.orig[p], z0.orig[rp0])
+	curDist := h.project(z1.cur[p], z0.cur[rp0])
+	if h.gs.autoFlip && (origDist^cvtDist) < 0 {
+		cvtDist = -cvtDist
+	}
+
+	d := cvtDist
+	if op&0x04 != 0 {
+		if h.gs.zp[0] == h.gs.zp[1] && h.gs.cvtCutIn < ttAbs(cvtDist-origDist) {
+			cvtDist = origDist
+		}
+		d = h.round(cvtDist)
+	}
+	if op&0x08 != 0 {
+		d = h.minDist(origDist, d)
+	}
+	h.move(z1, p, d-curDist)
+	h.gs.rp[1], h.gs.rp[2] = rp0, p
+	if op&0x10 != 0 {
+		h.gs.rp[0] = p
+	}
+}
+
+// minDist returns the distance d that is at least the minimum distance, in the direction of the original distance.
+func (h *ttHinter) minDist(origDist, d int32) int32 {
+	if 0 <= origDist {
+		return max(d, h.gs.minDist)
+	}
+	return min(d, -h.gs.minDist)
+}
+
+// iup interpolates the untouched points of the glyph zone along the x- or y-axis between the touched points of each contour.
+func (h *ttHinter) iup(x bool) {
+	z := h.zones[1]
+	flag := ttTouchedY
+	if x {
+		flag = ttTouchedX
+	}
+	coord := func(p *ttPoint) *int32 {
+		if x {
+			return &p.X
+		}
+		return &p.Y
+	}
+
+	interpolate := func(p1, p2, ref1, ref2 int) {
+		if p2 < p1 {
+			return
+		}
+		orus1, orus2 := *coord(&z.orus[ref1]), *coord(&z.orus[ref2])
+		if orus2 < orus1 {
+			orus1, orus2 = orus2, orus1
+			ref1, ref2 = ref2, ref1
+		}
+		org1, org2 := *coord(&z.orig[ref1]), *coord(&z.orig[ref2])
+		cur1, cur2 := *coord(&z.cur[ref1]), *coord(&z.cur[ref2])
+		delta1, delta2 := cur1-org1, cur2-org2
+
+		scale, scaleOK := int64(0), false
+		for i := p1; i <= p2; i++ {
+			v := *coord(&z.orig[i])
+			if v <= org1 {
+				v += delta1
+			} else if org2 <= v {
+				v += delta2
+			} else if cur1 == cur2 || orus1 == orus2 {
+				v = cur1
+			} else {
+				if !scaleOK {
+					scale, scaleOK = ttMulDiv(int64(cur2-cur1), 0x10000, int64(orus2-orus1)), true
+				}
+				v = cur1 + int32(ttMulDiv(int64(*coord(&z.orus[i])-orus1), scale, 0x10000))
+			}
+			*coord(&z.cur[i]) = v
+		}
+	}
+	shift := func(p1, p2, ref int) {
+		d := *coord(&z.cur[ref]) - *coord(&z.orig[ref])
+		for i := p1; i <= p2; i++ {
+			if i != ref {
+				*coord(&z.cur[i]) += d
+			}
+		}
+	}
+
+	p := 0
+	for _, end := range z.ends {
+		first := p
+		end = min(end, len(z.cur)-1)
+		for p <= end && z.flags[p]&flag == 0 {
+			p++
+		}
+		if end < p {
+			p = end + 1
+			continue
+		}
+		firstTouched, curTouched := p, p
+		for p++; p <= end; p++ {
+			if z.flags[p]&flag != 0 {
+				interpolate(curTouched+1, p-1, curTouched, p)
+				curTouched = p
+			}
+		}
+		if curTouched == firstTouched {
+			shift(first, end, curTouched)
+		} else {
+			interpolate(curTouched+1, end, curTouched, firstTouched)
+			if first < firstTouched {
+				interpolate(first, firstTouched-1, curTouched, firstTouched)
+			}
+		}
+	}
+}
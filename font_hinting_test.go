@@ -0,0 +1,96 @@
+package canvas
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/tdewolff/font"
+	"github.com/tdewolff/test"
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestTrueTypeHinting(t *testing.T) {
+	b, err := os.ReadFile("resources/DejaVuSerif.ttf")
+	test.Error(t, err)
+	sfnt, err := font.ParseSFNT(b, 0)
+	test.Error(t, err)
+	hinter, err := newTTHinter(sfnt)
+	test.Error(t, err)
+
+	// compare vertical coordinates against the hinter of golang/freetype
+	ref, err := truetype.Parse(b)
+	test.Error(t, err)
+	buf := &truetype.GlyphBuf{}
+	for _, ppem := range []int{8, 9, 11, 12, 16, 24} {
+		for _, r := range "AaBbEeHhMmOoRrSsgxz0123456789.,&%" {
+			id := sfnt.GlyphIndex(r)
+			err := buf.Load(ref, fixed.Int26_6(ppem<<6), truetype.Index(id), xfont.HintingFull)
+			test.Error(t, err)
+
+			glyph, err := hinter.hint(id, float64(ppem))
+			test.Error(t, err)
+			test.T(t, len(glyph.points), len(buf.Points), string(r))
+			for i, p := range glyph.points {
+				test.That(t, math.Abs(float64(p.Y-int32(buf.Points[i].Y))) <= 1.0, string(r), ppem, i, p.Y, buf.Points[i].Y)
+			}
+		}
+	}
+}
+
+func TestHintedGlyphPath(t *testing.T) {
+	var tts = []struct {
+		filename string
+		r        rune
+		ppem     float64
+	}{
+		{"resources/DejaVuSerif.ttf", 'x', 8.0},
+		{"resources/DejaVuSerif.ttf", 'H', 11.0},
+		{"resources/DejaVuSerif.ttf", 'z', 10.5},
+		{"resources/EBGaramond12-Regular.otf", 'x', 8.0},
+		{"resources/EBGaramond12-Regular.otf", 'H', 11.0},
+		{"resources/EBGaramond12-Regular.otf", 'z', 10.5},
+	}
+	for _, tt := range tts {
+		t.Run(tt.filename+"/"+string(tt.r), func(t *testing.T) {
+			family := NewFontFamily("family")
+			test.Error(t, family.LoadFontFile(tt.filename, FontRegular))
+			face := family.Face(12.0, Black, FontRegular, FontNormal)
+			id := face.Font.GlyphIndex(tt.r)
+			pxPerMm := tt.ppem / face.MmPerEm / float64(face.Font.Head.UnitsPerEm)
+
+			// glyphs are aligned to whole pixels at the baseline and x-height or cap height, apart from small curved serifs
+			bounds := face.GlyphToPath(id, tt.ppem).Bounds()
+			test.FloatDiff(t, bounds.Y0*pxPerMm, math.Round(bounds.Y0*pxPerMm), 0.05)
+			test.FloatDiff(t, bounds.Y1*pxPerMm, math.Round(bounds.Y1*pxPerMm), 0.05)
+			test.That(t, 2.0 <= math.Round(bounds.Y1*pxPerMm))
+
+			// horizontal positions are not hinted
+			unhinted := face.GlyphToPath(id, 0.0).Bounds()
+			test.Float(t, bounds.X0, unhinted.X0)
+			test.Float(t, bounds.X1, unhinted.X1)
+
+			face.Hinting = font.NoHinting
+			test.T(t, face.GlyphToPath(id, tt.ppem), face.GlyphToPath(id, 0.0))
+		})
+	}
+}
+
+func TestTextRenderHintedTo(t *testing.T) {
+	family := NewFontFamily("dejavu-serif")
+	test.Error(t, family.LoadFontFile("resources/DejaVuSerif.ttf", FontRegular))
+	face := family.Face(10.0, Black, FontRegular, FontNormal)
+	txt := NewTextLine(face, "x", Left)
+	unhinted, _ := face.ToPath("x")
+
+	// only rasterizers grid-fit glyphs
+	c := New(100, 100)
+	txt.RenderTo(c, Identity, DefaultResolution)
+	test.T(t, c.layers[0][0].path, unhinted)
+
+	c = New(100, 100)
+	txt.RenderHintedTo(c, Identity, DefaultResolution)
+	test.That(t, !c.layers[0][0].path.Equals(unhinted), "glyphs must be hinted")
+}
@@ -1264,6 +1264,324 @@ func (p *Path) Length() float64 {
 	return d
 }
 
+// moments returns the area integrals of the settled path, see Settle, relative to the returned origin to improve numerical precision.
+func (p *Path) moments(fillRule FillRule) (Point, pathMoments) {
+	q := p.Settle(fillRule)
+	if q.Empty() {
+		return Point{}, pathMoments{}
+	}
+	origin := q.StartPos()
+	q = q.Translate(-origin.X, -origin.Y)
+
+	m := pathMoments{}
+	var start, end Point
+	for i := 0; i < len(q.d); {
+		cmd := q.d[i]
+		if cmd == MoveToCmd {
+			if !end.Equals(start) {
+				m.addSegment(end, []float64{CloseCmd, start.X, start.Y, CloseCmd})
+			}
+			start = Point{q.d[i+1], q.d[i+2]}
+		} else {
+			m.addSegment(end, q.d[i:i+cmdLen(cmd)])
+		}
+		i += cmdLen(cmd)
+		end = Point{q.d[i-3], q.d[i-2]}
+	}
+	if !end.Equals(start) {
+		m.addSegment(end, []float64{CloseCmd, start.X, start.Y, CloseCmd})
+	}
+	return origin, m
+}
+
+// Area returns the area of the filled path in square millimeters, where holes and overlapping subpaths are evaluated using the fill rule, see Settle. The area is calculated exactly for all segment types, and subpaths are implicitly closed.
+func (p *Path) Area(fillRule FillRule) float64 {
+	_, m := p.moments(fillRule)
+	return m.a
+}
+
+// Centroid returns the centroid of the filled path, i.e. its center of mass, see Area. It returns the center of the bounding box if the path has no area.
+func (p *Path) Centroid(fillRule FillRule) Point {
+	origin, m := p.moments(fillRule)
+	if m.a <= Epsilon {
+		return p.Bounds().Center()
+	}
+	return origin.Add(Point{m.x / m.a, m.y / m.a})
+}
+
+// SecondMoments returns the second moments of area of the filled path about its centroid, see Area, i.e. xx = ∬(x-cx)² dA, yy = ∬(y-cy)² dA, and xy = ∬(x-cx)(y-cy) dA. The moments of inertia about the horizontal and vertical axes through the centroid are yy and xx respectively, the product of inertia is xy, and the polar moment of inertia is xx+yy.
+func (p *Path) SecondMoments(fillRule FillRule) (float64, float64, float64) {
+	_, m := p.moments(fillRule)
+	if m.a <= Epsilon {
+		return 0.0, 0.0, 0.0
+	}
+	cx, cy := m.x/m.a, m.y/m.a
+	return m.xx - m.a*cx*cx, m.yy - m.a*cy*cy, m.xy - m.a*cx*cy
+}
+
+// ClosestPoint returns the point on the path closest to q, the index of its segment, and t in [0.0,1.0] along that segment. The segment index counts all commands of the path including MoveTo and Close from zero, see Path.Len, and points to a MoveTo only for subpaths without segments. The closest point is exact for all segment types. It returns the zero point for empty paths.
+func (p *Path) ClosestPoint(q Point) (Point, int, float64) {
+	pos, seg, t := Point{}, 0, 0.0
+	distMin := math.Inf(1.0)
+	var start Point
+	for i, j := 0, 0; i < len(p.d); j++ {
+		cmd := p.d[i]
+		if cmd == MoveToCmd {
+			start = Point{p.d[i+1], p.d[i+2]}
+			if i+cmdLen(cmd) == len(p.d) || p.d[i+cmdLen(cmd)] == MoveToCmd {
+				// subpath without segments
+				if dist := q.Sub(start).Length(); dist < distMin {
+					pos, seg, t, distMin = start, j, 0.0, dist
+				}
+			}
+		} else {
+			posSeg, tSeg := segmentClosestPoint(start, p.d[i:i+cmdLen(cmd)], q)
+			if dist := q.Sub(posSeg).Length(); dist < distMin {
+				pos, seg, t, distMin = posSeg, j, tSeg, dist
+			}
+		}
+		i += cmdLen(cmd)
+		start = Point{p.d[i-3], p.d[i-2]}
+	}
+	return pos, seg, t
+}
+
+// Distance returns the distance from q to the closest point on the path, see ClosestPoint. It returns +Inf for empty paths.
+func (p *Path) Distance(q Point) float64 {
+	if len(p.d) == 0 {
+		return math.Inf(1.0)
+	}
+	pos, _, _ := p.ClosestPoint(q)
+	return q.Sub(pos).Length()
+}
+
+// HausdorffDistance returns the Hausdorff distance between paths p and q, i.e. the greatest distance from any point on one path to the closest point on the other path. It is accurate within the given tolerance, by sampling both paths at intervals of at most half the tolerance. It is zero when both paths are empty and +Inf when only one path is empty.
+func (p *Path) HausdorffDistance(q *Path, tolerance float64) float64 {
+	if p.Empty() && q.Empty() {
+		return 0.0
+	} else if p.Empty() || q.Empty() {
+		return math.Inf(1.0)
+	}
+	return math.Max(p.directedHausdorffDistance(q, tolerance), q.directedHausdorffDistance(p, tolerance))
+}
+
+// directedHausdorffDistance returns the greatest distance from any point on p to the closest point on q. Sample points are within tolerance/2 of p since the path is flattened, and the distance between sample points is at most tolerance/2 so that the error is at most 0.75*tolerance.
+func (p *Path) directedHausdorffDistance(q *Path, tolerance float64) float64 {
+	dist := 0.0
+	var start Point
+	flat := p.Flatten(tolerance / 2.0)
+	for i := 0; i < len(flat.d); {
+		cmd := flat.d[i]
+		end := Point{flat.d[i+cmdLen(cmd)-3], flat.d[i+cmdLen(cmd)-2]}
+		if cmd == MoveToCmd {
+			dist = math.Max(dist, q.Distance(end))
+		} else {
+			n := math.Max(1.0, math.Ceil(end.Sub(start).Length()/(tolerance/2.0)))
+			for k := 1.0; k <= n; k++ {
+				dist = math.Max(dist, q.Distance(start.Interpolate(end, k/n)))
+			}
+		}
+		i += cmdLen(cmd)
+		start = end
+	}
+	return dist
+}
+
+// segmentsAtLengths returns the index into the path's commands of the segment and its parameter t at each of the distances ds (in millimeters) along the path, which must be sorted. Distances are clamped to the path's length, and segment parameters are linear in the angle for arcs. The index is zero for empty paths.
+func (p *Path) segmentsAtLengths(ds []float64) ([]int, []float64) {
+	is := make([]int, len(ds))
+	ts := make([]float64, len(ds))
+
+	j := 0   // index into ds
+	T := 0.0 // current position along path
+	last := 0
+	for i := 0; i < len(p.d) && j < len(ds); i += cmdLen(p.d[i]) {
+		cmd := p.d[i]
+		if cmd == MoveToCmd {
+			continue
+		}
+		last = i
+
+		var dT float64
+		var invL func(float64) float64
+		start := Point{p.d[i-3], p.d[i-2]}
+		end := Point{p.d[i+cmdLen(cmd)-3], p.d[i+cmdLen(cmd)-2]}
+		switch cmd {
+		case LineToCmd, CloseCmd:
+			dT = end.Sub(start).Length()
+			invL = func(l float64) float64 {
+				return l / dT
+			}
+		case QuadToCmd, CubeToCmd:
+			N := 20
+			if cmd == QuadToCmd {
+				dT = quadraticBezierLength(start, Point{p.d[i+1], p.d[i+2]}, end)
+			} else {
+				cp1, cp2 := Point{p.d[i+1], p.d[i+2]}, Point{p.d[i+3], p.d[i+4]}
+				dT = cubicBezierLength(start, cp1, cp2, end)
+				N += 20 * cubicBezierNumInflections(start, cp1, cp2, end)
+			}
+			if j < len(ds) && ds[j] <= T+dT {
+				_, deriv, _ := segmentParametric(start, p.d[i:i+cmdLen(cmd)], Tolerance)
+				speed := func(t float64) float64 {
+					return deriv(t).Length()
+				}
+				invT, length := invSpeedPolynomialChebyshevApprox(N, gaussLegendre7, speed, 0.0, 1.0)
+				invL = func(l float64) float64 {
+					l *= length / dT
+					return invSpeedRefine(speed, 0.0, 1.0, invT(l), l)
+				}
+			}
+		case ArcToCmd:
+			rx, ry, phi := p.d[i+1], p.d[i+2], p.d[i+3]
+			large, sweep := toArcFlags(p.d[i+4])
+			_, _, theta1, theta2 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, end.X, end.Y)
+			dT = ellipseLength(rx, ry, theta1, theta2)
+			if j < len(ds) && ds[j] <= T+dT {
+				speed := func(theta float64) float64 {
+					return ellipseDeriv(rx, ry, 0.0, true, theta).Length()
+				}
+				invTheta, length := invSpeedPolynomialChebyshevApprox(10, gaussLegendre7, speed, theta1, theta2)
+				invL = func(l float64) float64 {
+					l *= length / dT
+					return (invSpeedRefine(speed, theta1, theta2, invTheta(l), l) - theta1) / (theta2 - theta1)
+				}
+			}
+		}
+
+		for j < len(ds) && ds[j] <= T+dT {
+			is[j] = i
+			if T+dT <= ds[j] {
+				ts[j] = 1.0
+			} else if l := ds[j] - T; 0.0 < l {
+				ts[j] = math.Max(0.0, math.Min(1.0, invL(l)))
+			}
+			j++
+		}
+		T += dT
+	}
+	for ; j < len(ds); j++ {
+		is[j] = last
+		if last != 0 {
+			ts[j] = 1.0
+		}
+	}
+	return is, ts
+}
+
+// segmentPosDeriv returns the position and the direction of unit length of the segment at index i into the path's commands and t in [0.0,1.0] along that segment, see segmentsAtLengths.
+func (p *Path) segmentPosDeriv(i int, t float64) (Point, Point) {
+	if len(p.d) == 0 {
+		return Point{}, Point{}
+	} else if i == 0 {
+		return Point{p.d[1], p.d[2]}, Point{}
+	}
+
+	start := Point{p.d[i-3], p.d[i-2]}
+	pos, deriv, _ := segmentParametric(start, p.d[i:i+cmdLen(p.d[i])], Tolerance)
+	dir := deriv(t)
+	if dir.IsZero() {
+		// at cusps or where control points coincide with end points
+		if t < 0.5 {
+			dir = pos(t + 1e-6).Sub(pos(t))
+		} else {
+			dir = pos(t).Sub(pos(t - 1e-6))
+		}
+	}
+	return pos(t), dir.Norm(1.0)
+}
+
+// PointAt returns the position at the given distance (in millimeters) along the path. The distance is clamped to the path's length, and subpaths follow each other without a gap.
+func (p *Path) PointAt(dist float64) Point {
+	is, ts := p.segmentsAtLengths([]float64{dist})
+	pos, _ := p.segmentPosDeriv(is[0], ts[0])
+	return pos
+}
+
+// TangentAt returns the direction of the path at the given distance (in millimeters) along the path, see PointAt. The direction is a vector of unit length, and is zero for empty paths.
+func (p *Path) TangentAt(dist float64) Point {
+	is, ts := p.segmentsAtLengths([]float64{dist})
+	_, dir := p.segmentPosDeriv(is[0], ts[0])
+	return dir
+}
+
+// NormalAt returns the normal of the path at the given distance (in millimeters) along the path, see PointAt. The normal is a vector of unit length pointing to the right-hand side of the path, which is the outside for CCW oriented paths, and is zero for empty paths.
+func (p *Path) NormalAt(dist float64) Point {
+	return p.TangentAt(dist).Rot90CW()
+}
+
+// SplitAtLength splits the path in two at the given distance (in millimeters) along the path, see PointAt. The first path is empty when the distance is not positive, and the second path is empty when the distance is at or beyond the path's length. A closed subpath that is split is opened.
+func (p *Path) SplitAtLength(dist float64) (*Path, *Path) {
+	is, ts := p.segmentsAtLengths([]float64{dist})
+	i, t := is[0], ts[0]
+	if i == 0 || dist <= 0.0 {
+		return &Path{}, p.Copy()
+	}
+
+	n := cmdLen(p.d[i])
+	start, end := Point{p.d[i-3], p.d[i-2]}, Point{p.d[i+n-3], p.d[i+n-2]}
+	q := &Path{append([]float64{}, p.d[:i]...)}
+	r := &Path{}
+	if t <= 0.0 {
+		r.MoveTo(start.X, start.Y)
+		r.appendSegment(p.d[i : i+n])
+	} else if t < 1.0 {
+		segs := splitSegment(start, p.d[i:i+n], []float64{t})
+		q.appendSegment(segs[0])
+		r.MoveTo(q.Pos().X, q.Pos().Y)
+		r.appendSegment(segs[1])
+	} else {
+		q.d = append(q.d, p.d[i:i+n]...)
+		r.MoveTo(end.X, end.Y)
+	}
+
+	// rest of the subpath, where a close is replaced by a line
+	i += n
+	for i < len(p.d) && p.d[i] != MoveToCmd {
+		r.appendSegment(p.d[i : i+cmdLen(p.d[i])])
+		i += cmdLen(p.d[i])
+	}
+	if len(r.d) == cmdLen(MoveToCmd) {
+		r.d = r.d[:0]
+	}
+	r.d = append(r.d, p.d[i:]...)
+	if r.Empty() {
+		r = &Path{}
+	}
+	return q, r
+}
+
+// Placements returns the transformation matrices that place objects along the path, starting at offset and then every interval (in millimeters) until the end of the path. Each matrix translates the origin to the position along the path and rotates the x-axis to the path's direction. Placements before the start of the path are skipped, and only one object is placed if interval is not positive.
+func (p *Path) Placements(offset, interval float64) []Matrix {
+	length := p.Length()
+	ds := []float64{}
+	for k := 0; ; k++ {
+		d := offset + float64(k)*interval
+		if length+Epsilon < d || 0 < k && interval <= 0.0 {
+			break
+		} else if -Epsilon <= d {
+			ds = append(ds, math.Max(0.0, d))
+		}
+	}
+
+	ms := make([]Matrix, len(ds))
+	is, ts := p.segmentsAtLengths(ds)
+	for k := range ds {
+		pos, dir := p.segmentPosDeriv(is[k], ts[k])
+		ms[k] = Identity.Translate(pos.X, pos.Y).Rotate(dir.Angle() * 180.0 / math.Pi)
+	}
+	return ms
+}
+
+// PlaceAlong returns copies of path q placed along path p, starting at offset and then every interval (in millimeters), see Path.Placements. The origin of q is placed on p and its x-axis follows the direction of p, for example to draw tick marks or arrows.
+func (p *Path) PlaceAlong(q *Path, offset, interval float64) *Path {
+	r := &Path{}
+	for _, m := range p.Placements(offset, interval) {
+		r = r.Append(q.Copy().Transform(m))
+	}
+	return r
+}
+
 // Transform transforms the path by the given transformation matrix. It modifies the path in-place.
 func (p *Path) Transform(m Matrix) *Path {
 	_, _, _, xscale, yscale, _ := m.Decompose()
@@ -1621,9 +1939,10 @@ func (p *Path) SplitAt(ts ...float64) []*Path {
 			cmd := ps.d[i]
 			switch cmd {
 			case MoveToCmd:
-				end = Point{p.d[i+1], p.d[i+2]}
+				end = Point{ps.d[i+1], ps.d[i+2]}
+				q.MoveTo(end.X, end.Y)
 			case LineToCmd, CloseCmd:
-				end = Point{p.d[i+1], p.d[i+2]}
+				end = Point{ps.d[i+1], ps.d[i+2]}
 
 				if j == len(ts) {
 					q.LineTo(end.X, end.Y)
@@ -1646,8 +1965,8 @@ func (p *Path) SplitAt(ts ...float64) []*Path {
 					T += dT
 				}
 			case QuadToCmd:
-				cp := Point{p.d[i+1], p.d[i+2]}
-				end = Point{p.d[i+3], p.d[i+4]}
+				cp := Point{ps.d[i+1], ps.d[i+2]}
+				end = Point{ps.d[i+3], ps.d[i+4]}
 
 				if j == len(ts) {
 					q.QuadTo(cp.X, cp.Y, end.X, end.Y)
@@ -1678,9 +1997,9 @@ func (p *Path) SplitAt(ts ...float64) []*Path {
 					T += dT
 				}
 			case CubeToCmd:
-				cp1 := Point{p.d[i+1], p.d[i+2]}
-				cp2 := Point{p.d[i+3], p.d[i+4]}
-				end = Point{p.d[i+5], p.d[i+6]}
+				cp1 := Point{ps.d[i+1], ps.d[i+2]}
+				cp2 := Point{ps.d[i+3], ps.d[i+4]}
+				end = Point{ps.d[i+5], ps.d[i+6]}
 
 				if j == len(ts) {
 					q.CubeTo(cp1.X, cp1.Y, cp2.X, cp2.Y, end.X, end.Y)
@@ -1713,9 +2032,9 @@ func (p *Path) SplitAt(ts ...float64) []*Path {
 					T += dT
 				}
 			case ArcToCmd:
-				rx, ry, phi := p.d[i+1], p.d[i+2], p.d[i+3]
-				large, sweep := toArcFlags(p.d[i+4])
-				end = Point{p.d[i+5], p.d[i+6]}
+				rx, ry, phi := ps.d[i+1], ps.d[i+2], ps.d[i+3]
+				large, sweep := toArcFlags(ps.d[i+4])
+				end = Point{ps.d[i+5], ps.d[i+6]}
 				cx, cy, theta1, theta2 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, end.X, end.Y)
 
 				if j == len(ts) {
@@ -0,0 +1,409 @@
+package canvas
+
+import (
+	"math"
+	"sort"
+)
+
+// PathPiece is a piece of a path that was cut at its intersections, see Path.Cut.
+type PathPiece struct {
+	*Path
+	Inside bool // piece lies inside the other path
+}
+
+// Cut cuts path p at all its intersections with path q, and with itself if self is set, and returns the pieces of p in order. Each piece is tagged whether it lies inside of q using the NonZero fill rule, where pieces running along the boundary of q are considered inside. Unlike the boolean operations, path p is not treated as a filling area but as a set of lines and curves, and Béziers and arcs are split at the intersections and are not flattened. Closed subpaths of p that are cut remain joined at their start. Path q is implicitly closed and may be nil. Intersections are found using the Bentley-Ottmann sweep in O((n + k) log n), with n the number of segments, and k the number of intersections. An error is returned if the sweep fails, which may happen for degenerate input.
+func (p *Path) Cut(q *Path, self bool) ([]PathPiece, error) {
+	return Paths(p.Split()).Cut(q.Split(), self)
+}
+
+// Cut is the same as Path.Cut, but faster if paths are already split.
+func (ps Paths) Cut(qs Paths, self bool) ([]PathPiece, error) {
+	cuts, err := cutPaths(ps, qs, self)
+	if err != nil {
+		return nil, err
+	}
+
+	var pieces []PathPiece
+	for _, cut := range cuts {
+		n := len(cut.pieces)
+		if 1 < n && !cut.startCut && cut.sub.Closed() {
+			// join the pieces around the start of closed subpaths
+			cut.pieces[n-1].d = append(cut.pieces[n-1].d, cut.pieces[0].d[cmdLen(MoveToCmd):]...)
+			cut.pieces, cut.inside = cut.pieces[1:], cut.inside[1:]
+		}
+		if len(cut.pieces) == 1 {
+			// keep as is when not cut, closed subpaths remain closed
+			cut.pieces[0] = cut.sub.Copy()
+		}
+		for i, piece := range cut.pieces {
+			pieces = append(pieces, PathPiece{piece, cut.inside[i]})
+		}
+	}
+	return pieces, nil
+}
+
+// ClipBy clips path p by polygon q and returns the parts of p that are inside and outside of q respectively. Unlike And and Not, path p is not treated as a filling area but as a set of (open) lines and curves, such as roads or hatching strokes, which are cut at their intersections with q. Béziers and arcs are split at the intersections and are not flattened. Parts of p that run along the boundary of q are considered inside. Path q is implicitly closed. Intersections are found using the Bentley-Ottmann sweep in O((n + k) log n), with n the number of segments, and k the number of intersections. An error is returned if the sweep fails, which may happen for degenerate input.
+func (p *Path) ClipBy(q *Path) (*Path, *Path, error) {
+	inside, outside, err := Paths(p.Split()).ClipBy(q.Split())
+	if err != nil {
+		return nil, nil, err
+	}
+	return inside.Merge(), outside.Merge(), nil
+}
+
+// ClipBy is the same as Path.ClipBy, but faster if paths are already split. Each resulting path is a single piece of a subpath of ps, in order.
+func (ps Paths) ClipBy(qs Paths) (Paths, Paths, error) {
+	cuts, err := cutPaths(ps, qs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var inside, outside Paths
+	for _, cut := range cuts {
+		// join consecutive pieces on the same side
+		pieces, ins := cut.pieces, cut.inside
+		n := 0
+		for i := range pieces {
+			if 0 < n && ins[n-1] == ins[i] {
+				pieces[n-1].d = append(pieces[n-1].d, pieces[i].d[cmdLen(MoveToCmd):]...)
+			} else {
+				pieces[n], ins[n] = pieces[i], ins[i]
+				n++
+			}
+		}
+		pieces, ins = pieces[:n], ins[:n]
+		if 1 < n && ins[0] == ins[n-1] && cut.sub.Closed() {
+			pieces[n-1].d = append(pieces[n-1].d, pieces[0].d[cmdLen(MoveToCmd):]...)
+			pieces, ins = pieces[1:], ins[1:]
+		}
+		if len(pieces) == 1 {
+			// keep as is when not cut, closed subpaths remain closed
+			pieces[0] = cut.sub.Copy()
+		}
+
+		for i, piece := range pieces {
+			if ins[i] {
+				inside = append(inside, piece)
+			} else {
+				outside = append(outside, piece)
+			}
+		}
+	}
+	return inside, outside, nil
+}
+
+// cutSubpath is a subpath that was cut into pieces.
+type cutSubpath struct {
+	sub      *Path
+	pieces   []*Path
+	inside   []bool // piece lies inside the other path
+	startCut bool   // subpath was cut at its start
+}
+
+// cutPaths cuts the subpaths of ps at their intersections with qs, and with each other if self is set. It returns the pieces of each subpath in order, and whether they lie inside of qs. Paths qs are implicitly closed. It returns an error if the sweep fails, see sweepSegmentPairs.
+func cutPaths(ps, qs Paths, self bool) ([]cutSubpath, error) {
+	var subs Paths
+	for _, p := range ps {
+		if !p.Empty() {
+			subs = append(subs, p.Split()...)
+		}
+	}
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	// close clipping polygon
+	var polys Paths
+	poly := &Path{}
+	for _, q := range qs {
+		for _, qi := range q.Split() {
+			qi = qi.Copy()
+			qi.Close()
+			if !qi.Empty() {
+				polys = append(polys, qi)
+				poly = poly.Append(qi)
+			}
+		}
+	}
+
+	segment := func(ps Paths, s flatSegment) []float64 {
+		d := ps[s.sub].d
+		return d[s.i : s.i+cmdLen(d[s.i])]
+	}
+	cuts := map[pathSegment][]float64{}
+	add := func(s pathSegment, t float64) {
+		cuts[s] = append(cuts[s], math.Max(0.0, math.Min(1.0, t)))
+	}
+
+	// find intersections of the original segments
+	flats, origins := flattenSegments(subs)
+	zs := Intersections{}
+	if 0 < len(polys) {
+		polyFlats, polyOrigins := flattenSegments(polys)
+		pairs, err := sweepSegmentPairs(flats, polyFlats)
+		if err != nil {
+			return nil, err
+		}
+
+		done := map[[2]pathSegment]bool{}
+		for _, pair := range pairs {
+			a, b := origins[pair[0]], polyOrigins[pair[1]]
+			if done[[2]pathSegment{a.pathSegment, b.pathSegment}] {
+				continue
+			}
+			done[[2]pathSegment{a.pathSegment, b.pathSegment}] = true
+
+			zs = intersectionSegment(zs[:0], a.start, segment(subs, a), b.start, segment(polys, b))
+			for _, z := range zs {
+				add(a.pathSegment, z.T[0])
+			}
+		}
+	}
+	if self {
+		zsSelf, err := selfIntersections(subs, flats, origins)
+		if err != nil {
+			return nil, err
+		}
+		for _, z := range zsSelf {
+			add(z.a, z.ta)
+			add(z.b, z.tb)
+		}
+	}
+
+	// cut and classify pieces
+	cutSubs := make([]cutSubpath, len(subs))
+	for k, sub := range subs {
+		pieces, startCut := cutAt(k, sub, cuts)
+		inside := make([]bool, len(pieces))
+		if 0 < len(polys) {
+			for i, piece := range pieces {
+				pos := piece.sample()
+				inside[i] = poly.ContainsPoint(pos.X, pos.Y, NonZero)
+			}
+		}
+		cutSubs[k] = cutSubpath{sub, pieces, inside, startCut}
+	}
+	return cutSubs, nil
+}
+
+// segmentIntersection is an intersection at position Point between segments a and b, at ta and tb along the segments respectively.
+type segmentIntersection struct {
+	Point
+	a, b   pathSegment
+	ta, tb float64
+}
+
+// selfIntersections returns the intersections between the segments of subpaths ps, excluding where consecutive segments join. The flat paths and their original segments must be obtained from flattenSegments(ps). Intersections are ordered by their pair of segments. It returns an error if the sweep fails, see sweepSegmentPairs.
+func selfIntersections(ps, flats Paths, origins []flatSegment) ([]segmentIntersection, error) {
+	segment := func(s flatSegment) []float64 {
+		d := ps[s.sub].d
+		return d[s.i : s.i+cmdLen(d[s.i])]
+	}
+
+	pairs, err := sweepSegmentPairs(flats, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var zsSelf []segmentIntersection
+	zs := Intersections{}
+	done := map[[2]pathSegment]bool{}
+	for _, pair := range pairs {
+		a, b := origins[pair[0]], origins[pair[1]]
+		if a.pathSegment == b.pathSegment {
+			if pair[0]+1 < pair[1] {
+				// Bézier that intersects itself
+				pos, deriv, _ := segmentParametric(a.start, segment(a), Tolerance)
+				lines := intersectionLineLine(nil, pos(a.t0), pos(a.t1), pos(b.t0), pos(b.t1))
+				for _, line := range lines {
+					s := a.t0 + line.T[0]*(a.t1-a.t0)
+					t := b.t0 + line.T[1]*(b.t1-b.t0)
+					s, t = refineIntersection(pos, deriv, pos, deriv, s, t)
+					zsSelf = append(zsSelf, segmentIntersection{pos(s), a.pathSegment, a.pathSegment, s, t})
+				}
+			}
+			continue
+		} else if done[[2]pathSegment{a.pathSegment, b.pathSegment}] {
+			continue
+		}
+		done[[2]pathSegment{a.pathSegment, b.pathSegment}] = true
+
+		zs = intersectionSegment(zs[:0], a.start, segment(a), b.start, segment(b))
+		for _, z := range zs {
+			if !ps.consecutive(a.pathSegment, b.pathSegment, z.T[0], z.T[1]) {
+				zsSelf = append(zsSelf, segmentIntersection{z.Point, a.pathSegment, b.pathSegment, z.T[0], z.T[1]})
+			}
+		}
+	}
+	return zsSelf, nil
+}
+
+// consecutive returns true if the intersection at ta and tb of segments a and b is where one segment continues into the other along their subpath.
+func (ps Paths) consecutive(a, b pathSegment, ta, tb float64) bool {
+	if a.sub != b.sub {
+		return false
+	} else if b.i < a.i {
+		a, b = b, a
+		ta, tb = tb, ta
+	}
+
+	d := ps[a.sub].d
+	if a.i+cmdLen(d[a.i]) == b.i {
+		return 1.0-Epsilon < ta && tb < Epsilon
+	} else if a.i == cmdLen(MoveToCmd) && b.i+cmdLen(d[b.i]) == len(d) && d[1] == d[len(d)-3] && d[2] == d[len(d)-2] {
+		// first and last segment of a closed subpath
+		return ta < Epsilon && 1.0-Epsilon < tb
+	}
+	return false
+}
+
+// sweepSegmentPairs returns the pairs of segment numbers of ps and qs that intersect or touch, using the Bentley-Ottmann sweep, see sweepColumn. Segment numbers are as used by SweepEvents.AddPathEndpoints and start at one for both ps and qs. If qs is nil, it returns the pairs of segments of ps that intersect or touch each other, which includes consecutive segments. Paths must be flat.
+func sweepSegmentPairs(ps, qs Paths) ([][2]int, error) {
+	boInitPoolsOnce() // use pools for SweepPoint and SweepNode to amortize repeated calls to BO
+
+	self := qs == nil
+	if ps.Empty() || !self && qs.Empty() {
+		return nil, nil
+	}
+
+	pSeg, qSeg := 0, 0
+	queue := &SweepEvents{}
+	for _, p := range ps {
+		pSeg = queue.AddPathEndpoints(p, pSeg, false)
+	}
+	for _, q := range qs {
+		qSeg = queue.AddPathEndpoints(q, qSeg, true)
+	}
+	queue.Init() // sort from left to right
+
+	// Bentley-Ottmann sweep, after which segments that intersect or touch share an endpoint
+	events := map[Point][]*SweepPoint{}
+	status := &SweepStatus{}  // contains only left events
+	zs := make([]Point, 0, 2) // buffer for intersections
+	addEvent := func(event *SweepPoint, _ *SweepNode) {
+		events[event.Point] = append(events[event.Point], event)
+	}
+	for 0 < len(*queue) {
+		x := snap(queue.Top().X, BentleyOttmannEpsilon)
+		if err := sweepColumn(queue, status, x, zs, addEvent); err != nil {
+			return nil, err
+		}
+	}
+
+	has := map[[2]int]bool{}
+	pairs := [][2]int{}
+	for _, at := range events {
+		for i, a := range at {
+			for _, b := range at[i+1:] {
+				var pair [2]int
+				if self && a.segment != b.segment {
+					pair = [2]int{min(a.segment, b.segment), max(a.segment, b.segment)}
+				} else if !self && !a.clipping && b.clipping {
+					pair = [2]int{a.segment, b.segment}
+				} else if !self && a.clipping && !b.clipping {
+					pair = [2]int{b.segment, a.segment}
+				} else {
+					continue
+				}
+				if !has[pair] {
+					has[pair] = true
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] == pairs[j][0] {
+			return pairs[i][1] < pairs[j][1]
+		}
+		return pairs[i][0] < pairs[j][0]
+	})
+	return pairs, nil
+}
+
+// cutAt cuts subpath p, with index k, at the segment parameters of cuts and returns the pieces in order, and whether it was cut at its start.
+func cutAt(k int, p *Path, cuts map[pathSegment][]float64) ([]*Path, bool) {
+	pieces := []*Path{}
+	piece := &Path{}
+	piece.MoveTo(p.d[1], p.d[2])
+	push := func() {
+		if len(piece.d) != cmdLen(MoveToCmd) {
+			pos := piece.Pos()
+			pieces = append(pieces, piece)
+			piece = &Path{}
+			piece.MoveTo(pos.X, pos.Y)
+		}
+	}
+
+	startCut := false
+	start := Point{p.d[1], p.d[2]}
+	for i := cmdLen(MoveToCmd); i < len(p.d); {
+		cmd := p.d[i]
+		ts := cuts[pathSegment{k, i}]
+		sort.Float64s(ts)
+
+		cutAfter := false
+		tsInner := ts[:0]
+		for _, t := range ts {
+			if t < Epsilon {
+				startCut = startCut || i == cmdLen(MoveToCmd)
+				push()
+			} else if 1.0-Epsilon < t {
+				cutAfter = true
+			} else if len(tsInner) == 0 || !Equal(tsInner[len(tsInner)-1], t) {
+				tsInner = append(tsInner, t)
+			}
+		}
+		for j, seg := range splitSegment(start, p.d[i:i+cmdLen(cmd)], tsInner) {
+			if 0 < j {
+				push()
+			}
+			piece.appendSegment(seg)
+		}
+
+		i += cmdLen(cmd)
+		if cutAfter {
+			startCut = startCut || i == len(p.d)
+			push()
+		}
+		start = Point{p.d[i-3], p.d[i-2]}
+	}
+	push()
+	return pieces, startCut
+}
+
+// sample returns a position halfway the middle segment of the path.
+func (p *Path) sample() Point {
+	n := 0
+	for i := 0; i < len(p.d); i += cmdLen(p.d[i]) {
+		if p.d[i] != MoveToCmd {
+			n++
+		}
+	}
+
+	j := 0
+	var start Point
+	for i := 0; i < len(p.d); i += cmdLen(p.d[i]) {
+		cmd := p.d[i]
+		end := Point{p.d[i+cmdLen(cmd)-3], p.d[i+cmdLen(cmd)-2]}
+		if cmd != MoveToCmd {
+			if j == n/2 {
+				switch cmd {
+				case QuadToCmd:
+					return quadraticBezierPos(start, Point{p.d[i+1], p.d[i+2]}, end, 0.5)
+				case CubeToCmd:
+					return cubicBezierPos(start, Point{p.d[i+1], p.d[i+2]}, Point{p.d[i+3], p.d[i+4]}, end, 0.5)
+				case ArcToCmd:
+					rx, ry, phi := p.d[i+1], p.d[i+2], p.d[i+3]
+					large, sweep := toArcFlags(p.d[i+4])
+					cx, cy, theta0, theta1 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, end.X, end.Y)
+					return EllipsePos(rx, ry, phi, cx, cy, (theta0+theta1)/2.0)
+				}
+				return start.Interpolate(end, 0.5)
+			}
+			j++
+		}
+		start = end
+	}
+	return start
+}
@@ -0,0 +1,101 @@
+package canvas
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestPathClipBy(t *testing.T) {
+	var tts = []struct {
+		p, q            string
+		inside, outside string
+	}{
+		{"", "L10 0L10 10L0 10z", "", ""},
+		{"M-5 5L15 5", "", "", "M-5 5L15 5"},
+
+		// lines
+		{"M-5 5L15 5", "L10 0L10 10L0 10z", "M0 5L10 5", "M-5 5L0 5M10 5L15 5"},
+		{"M-5 5L5 5L5 15", "L10 0L10 10L0 10z", "M0 5L5 5L5 10", "M-5 5L0 5M5 10L5 15"},
+		{"M2 2L8 8", "L10 0L10 10L0 10z", "M2 2L8 8", ""},
+		{"M12 2L18 8", "L10 0L10 10L0 10z", "", "M12 2L18 8"},
+		{"M-5 10L15 10", "L10 0L10 10L0 10z", "M0 10L10 10", "M-5 10L0 10M10 10L15 10"},
+		{"M0 0L10 10", "L10 0L10 10L0 10z", "M0 0L10 10", ""},
+		{"M-5 5L15 5", "L10 0L10 10L0 10", "M0 5L10 5", "M-5 5L0 5M10 5L15 5"}, // implicitly closed
+		{"M-5 5L15 5", "L4 0L4 10L0 10zM6 0L10 0L10 10L6 10z", "M0 5L4 5M6 5L10 5", "M-5 5L0 5M4 5L6 5M10 5L15 5"},
+
+		// closed paths
+		{"M2 2L8 2L8 8L2 8z", "L10 0L10 10L0 10z", "M2 2L8 2L8 8L2 8z", ""},
+		{"M5 2L15 2L15 8L5 8z", "L10 0L10 10L0 10z", "M10 8L5 8L5 2L10 2", "M10 2L15 2L15 8L10 8"},
+
+		// curves
+		{"M-5 5Q5 15 15 5", "L10 0L10 10L0 10z", "M0 8.75Q5 11.25 10 8.75", "M-5 5Q-2.5 7.5 0 8.75M10 8.75Q12.5 7.5 15 5"},
+		{"M-5 5C0 10 10 0 15 5", "L10 0L10 10L0 10z", "M0 6.352877C3.1764386 5.9216433 6.823561 4.0783567 10 3.6471226", "M-5 5C-3.6372602 6.3627396 -1.9031085 6.6112437 0 6.352877M10 3.6471226C11.903109 3.3887563 13.63726 3.6372602 15 5"},
+		{"M-5 5C0 15 10 15 15 5", "L10 0L10 10L0 10z", "", "M-5 5C0 15 10 15 15 5"},
+		{"M-2 5A7 7 0 0 1 12 5", "L10 0L10 10L0 10z", "M0 0.101020515A7 7 0 0 1 0.101020515 0M9.898979 0A7 7 0 0 1 10 0.101020515", "M-2 5A7 7 0 0 1 0 0.101020515M0.101020515 0A7 7 0 0 1 9.898979 0M10 0.101020515A7 7 0 0 1 12 5"},
+		{"M-2 5A7 3 30 0 0 12 5", "L10 0L10 10L0 10z", "M0 6.856587A10.170764 4.358899 30 0 0 5.2615376 10", "M-2 5A10.170764 4.358899 30 0 0 0 6.856587M5.2615376 10A10.170764 4.358899 30 0 0 12 5"},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "x", tt.q), func(t *testing.T) {
+			p := MustParseSVGPath(tt.p)
+			q := MustParseSVGPath(tt.q)
+			inside, outside, err := p.ClipBy(q)
+			test.Error(t, err)
+			defer setEpsilon(1e-6)()
+			test.T(t, inside, MustParseSVGPath(tt.inside))
+			test.T(t, outside, MustParseSVGPath(tt.outside))
+		})
+	}
+}
+
+func TestPathCut(t *testing.T) {
+	var tts = []struct {
+		p, q   string
+		self   bool
+		pieces []string
+		inside []bool
+	}{
+		{"", "L10 0L10 10L0 10z", true, nil, nil},
+		{"M-5 5L15 5", "", true, []string{"M-5 5L15 5"}, []bool{false}},
+		{"M-5 5L15 5", "L10 0L10 10L0 10z", false, []string{"M-5 5L0 5", "M0 5L10 5", "M10 5L15 5"}, []bool{false, true, false}},
+		{"M0 0L10 0L10 10L0 10z", "", true, []string{"M0 0L10 0L10 10L0 10z"}, []bool{false}},
+
+		// self-intersections
+		{"M0 5L10 5M5 0L5 10", "", false, []string{"M0 5L10 5", "M5 0L5 10"}, []bool{false, false}},
+		{"M0 5L10 5M5 0L5 10", "", true, []string{"M0 5L5 5", "M5 5L10 5", "M5 0L5 5", "M5 5L5 10"}, []bool{false, false, false, false}},
+		{"M0 0L10 10L10 0L0 10z", "", true, []string{"M5 5L10 10L10 0L5 5", "M5 5L0 10L0 0L5 5"}, []bool{false, false}},
+		{"M0 0L10 10L10 0L0 10z", "M4 0L6 0L6 10L4 10z", true, []string{"M4 4L5 5", "M5 5L6 6", "M6 6L10 10L10 0L6 4", "M6 4L5 5", "M5 5L4 6", "M4 6L0 10L0 0L4 4"}, []bool{true, true, false, true, true, false}},
+		{"M0 0C20 10 -10 10 10 0", "", true, []string{"M0 0C2.2540333 1.1270167 3.8729833 2.1270167 5 3", "M5 3C12.745967 9 -2.745967 9 5 3", "M5 3C6.1270167 2.1270167 7.7459667 1.1270167 10 0"}, []bool{false, false, false}},
+		{"M-5 5L15 5M5 -5A10 10 0 0 1 5 15", "", true, []string{"M-5 5L15 5", "M5 -5A10 10 0 0 1 15 5", "M15 5A10 10 0 0 1 5 15"}, []bool{false, false, false}},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "x", tt.q), func(t *testing.T) {
+			p := MustParseSVGPath(tt.p)
+			var q *Path
+			if tt.q != "" {
+				q = MustParseSVGPath(tt.q)
+			}
+			pieces, err := p.Cut(q, tt.self)
+			test.Error(t, err)
+			defer setEpsilon(1e-6)()
+			test.T(t, len(pieces), len(tt.pieces))
+			for i := range pieces {
+				if i < len(tt.pieces) {
+					test.T(t, pieces[i].Path, MustParseSVGPath(tt.pieces[i]))
+					test.T(t, pieces[i].Inside, tt.inside[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSweepColumnError(t *testing.T) {
+	// right-endpoint whose segment was never added to the status
+	left := &SweepPoint{Point: Point{0.0, 0.0}, left: true}
+	right := &SweepPoint{Point: Point{1.0, 0.0}, other: left}
+	left.other = right
+	queue := &SweepEvents{right}
+	err := sweepColumn(queue, &SweepStatus{}, 1.0, nil, func(*SweepPoint, *SweepNode) {})
+	test.That(t, err != nil, "must return an error instead of panicking")
+}
@@ -2,6 +2,7 @@ package canvas
 
 import (
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -124,13 +125,15 @@ var boInitPoolsOnce = sync.OnceFunc(func() {
 	boSquarePool = &sync.Pool{New: func() any { return &toleranceSquare{} }}
 })
 
-// Settle returns the "settled"/flattened path, a visually identical version of the original.
+// Settle returns the "settled" path, a visually identical version of the original.
 // It removes all self-intersections and overlapping areas, orients all filling paths CCW and all
 // holes CW, and tries to separate paths as much as possible. Paths are grouped by the filling/outer
 // ring followed by the corresponding holes/inner rings; the outer rings are ordered from
-// left-to-right and secondly from bottom-to-top. Note that path p is flattened unless q is already
-// flat. It runs in O((n + k) log n), with n the number of segments, and k the number of
-// intersections.
+// left-to-right and secondly from bottom-to-top. It runs in O((n + k) log n), with n the number
+// of segments, and k the number of intersections.
+// Béziers and arcs are kept in the result, but the sweep runs on their flattened versions within
+// Tolerance, so that intersections of curves are only accurate to about Tolerance and curves that
+// touch without crossing may be missed.
 func (p *Path) Settle(fillRule FillRule) *Path {
 	return bentleyOttmann(p.Split(), nil, opSettle, fillRule).Merge()
 }
@@ -145,9 +148,13 @@ func (ps Paths) Settle(fillRule FillRule) Paths {
 // It removes all self-intersections and overlapping areas, orients all filling paths CCW and all
 // holes CW, and tries to separate paths as much as possible. Paths are grouped by the filling/outer
 // ring followed by the corresponding holes/inner rings; the outer rings are ordered from
-// left-to-right and secondly from bottom-to-top. Note that path p is flattened unless q is already
-// flat. Path q is implicitly closed. It runs in O((n + k) log n), with n the number of segments,
-// and k the number of intersections.
+// left-to-right and secondly from bottom-to-top. Path q is implicitly closed. It runs in
+// O((n + k) log n), with n the number of segments, and k the number of intersections.
+// Béziers and arcs are kept in the result, but the sweep runs on their flattened versions within
+// Tolerance. Where a segment of p meets a segment of q, the vertex is recomputed on the original
+// segments, exactly between a line and a curve and using Newton's method between two curves. Other
+// vertices, such as self-intersections, stay on the flattened curves. Intersections are thus only
+// accurate to about Tolerance, and curves that touch without crossing may be missed.
 func (p *Path) And(q *Path) *Path {
 	return bentleyOttmann(p.Split(), q.Split(), opAND, NonZero).Merge()
 }
@@ -162,9 +169,13 @@ func (ps Paths) And(qs Paths) Paths {
 // It removes all self-intersections and overlapping areas, orients all filling paths CCW and all
 // holes CW, and tries to separate paths as much as possible. Paths are grouped by the filling/outer
 // ring followed by the corresponding holes/inner rings; the outer rings are ordered from
-// left-to-right and secondly from bottom-to-top. Note that path p is flattened unless q is already
-// flat. Path q is implicitly closed. It runs in O((n + k) log n), with n the number of segments,
-// and k the number of intersections.
+// left-to-right and secondly from bottom-to-top. Path q is implicitly closed. It runs in
+// O((n + k) log n), with n the number of segments, and k the number of intersections.
+// Béziers and arcs are kept in the result, but the sweep runs on their flattened versions within
+// Tolerance. Where a segment of p meets a segment of q, the vertex is recomputed on the original
+// segments, exactly between a line and a curve and using Newton's method between two curves. Other
+// vertices, such as self-intersections, stay on the flattened curves. Intersections are thus only
+// accurate to about Tolerance, and curves that touch without crossing may be missed.
 func (p *Path) Or(q *Path) *Path {
 	return bentleyOttmann(p.Split(), q.Split(), opOR, NonZero).Merge()
 }
@@ -179,9 +190,13 @@ func (ps Paths) Or(qs Paths) Paths {
 // It removes all self-intersections and overlapping areas, orients all filling paths CCW and all
 // holes CW, and tries to separate paths as much as possible. Paths are grouped by the filling/outer
 // ring followed by the corresponding holes/inner rings; the outer rings are ordered from
-// left-to-right and secondly from bottom-to-top. Note that path p is flattened unless q is already
-// flat. Path q is implicitly closed. It runs in O((n + k) log n), with n the number of segments,
-// and k the number of intersections.
+// left-to-right and secondly from bottom-to-top. Path q is implicitly closed. It runs in
+// O((n + k) log n), with n the number of segments, and k the number of intersections.
+// Béziers and arcs are kept in the result, but the sweep runs on their flattened versions within
+// Tolerance. Where a segment of p meets a segment of q, the vertex is recomputed on the original
+// segments, exactly between a line and a curve and using Newton's method between two curves. Other
+// vertices, such as self-intersections, stay on the flattened curves. Intersections are thus only
+// accurate to about Tolerance, and curves that touch without crossing may be missed.
 func (p *Path) Xor(q *Path) *Path {
 	return bentleyOttmann(p.Split(), q.Split(), opXOR, NonZero).Merge()
 }
@@ -196,9 +211,13 @@ func (ps Paths) Xor(qs Paths) Paths {
 // It removes all self-intersections and overlapping areas, orients all filling paths CCW and all
 // holes CW, and tries to separate paths as much as possible. Paths are grouped by the filling/outer
 // ring followed by the corresponding holes/inner rings; the outer rings are ordered from
-// left-to-right and secondly from bottom-to-top. Note that path p is flattened unless q is already
-// flat. Path q is implicitly closed. It runs in O((n + k) log n), with n the number of segments,
-// and k the number of intersections.
+// left-to-right and secondly from bottom-to-top. Path q is implicitly closed. It runs in
+// O((n + k) log n), with n the number of segments, and k the number of intersections.
+// Béziers and arcs are kept in the result, but the sweep runs on their flattened versions within
+// Tolerance. Where a segment of p meets a segment of q, the vertex is recomputed on the original
+// segments, exactly between a line and a curve and using Newton's method between two curves. Other
+// vertices, such as self-intersections, stay on the flattened curves. Intersections are thus only
+// accurate to about Tolerance, and curves that touch without crossing may be missed.
 func (p *Path) Not(q *Path) *Path {
 	return bentleyOttmann(p.Split(), q.Split(), opNOT, NonZero).Merge()
 }
@@ -213,9 +232,13 @@ func (ps Paths) Not(qs Paths) Paths {
 // It removes all self-intersections and overlapping areas, orients all filling paths CCW and all
 // holes CW, and tries to separate paths as much as possible. Paths are grouped by the filling/outer
 // ring followed by the corresponding holes/inner rings; the outer rings are ordered from
-// left-to-right and secondly from bottom-to-top. Note that path p is flattened unless q is already
-// flat. Path q is implicitly closed. It runs in O((n + k) log n), with n the number of segments,
-// and k the number of intersections.
+// left-to-right and secondly from bottom-to-top. Path q is implicitly closed. It runs in
+// O((n + k) log n), with n the number of segments, and k the number of intersections.
+// Béziers and arcs are kept in the result, but the sweep runs on their flattened versions within
+// Tolerance. Where a segment of p meets a segment of q, the vertex is recomputed on the original
+// segments, exactly between a line and a curve and using Newton's method between two curves. Other
+// vertices, such as self-intersections, stay on the flattened curves. Intersections are thus only
+// accurate to about Tolerance, and curves that touch without crossing may be missed.
 func (p *Path) Div(q *Path) *Path {
 	return bentleyOttmann(p.Split(), q.Split(), opDIV, NonZero).Merge()
 }
@@ -1802,16 +1825,281 @@ func (s *SweepPoint) mergeOverlapping(op pathOp, fillRule FillRule) {
 	s.prev = prev
 }
 
+// pathSegment is a segment of subpath sub at index i into its commands.
+type pathSegment struct {
+	sub, i int
+}
+
+// flatSegment is the original path segment of a line segment of a flattened path. The line segment runs from t0 to t1 along the original segment, which starts at start.
+type flatSegment struct {
+	pathSegment
+	start  Point
+	t0, t1 float64
+}
+
+// flattenSegments flattens each segment of the subpaths separately in uniform steps along their parameter t, and returns the flat paths and the original segment for each segment number used for SweepEvents.AddPathEndpoints. Zero-length lines are kept so that the numbering is not disturbed.
+func flattenSegments(ps Paths) (Paths, []flatSegment) {
+	flats := make(Paths, len(ps))
+	origins := []flatSegment{{}} // segment numbers start at one
+	for k, p := range ps {
+		flat := &Path{}
+		flats[k] = flat
+		if len(p.d) == 0 {
+			continue
+		}
+
+		flat.MoveTo(p.d[1], p.d[2])
+		start := Point{p.d[1], p.d[2]}
+		for i := cmdLen(MoveToCmd); i < len(p.d); {
+			cmd := p.d[i]
+			end := Point{p.d[i+cmdLen(cmd)-3], p.d[i+cmdLen(cmd)-2]}
+			pos, _, n := segmentParametric(start, p.d[i:i+cmdLen(cmd)], Tolerance)
+			for j := 1; j <= n; j++ {
+				t0, t1 := float64(j-1)/float64(n), float64(j)/float64(n)
+				z := end
+				if j < n {
+					z = pos(t1)
+				}
+				flat.d = append(flat.d, LineToCmd, z.X, z.Y, LineToCmd)
+				origins = append(origins, flatSegment{pathSegment{k, i}, start, t0, t1})
+			}
+			i += cmdLen(cmd)
+			start = end
+		}
+	}
+	return flats, origins
+}
+
+// boEdge is a segment of the result polygon, given by its segment number and input path.
+type boEdge struct {
+	segment  int
+	clipping bool
+	from, to Point
+}
+
+// recoverCurves rebuilds the result polygon from its edges, replacing edges that stem from Bézier or arc segments of the input by the corresponding part of the original segment. Where such a segment meets a segment of the other path, the vertex is moved to their exact intersection.
+func recoverCurves(edges []boEdge, closed bool, ps, qs Paths, pOrigins, qOrigins []flatSegment) *Path {
+	type boCurve struct {
+		pathSegment           // original segment
+		start       Point     // start of original segment
+		d           []float64 // original segment, nil for lines
+		tFrom, tTo  float64   // position of the endpoints along the original segment
+	}
+
+	curves := make([]boCurve, len(edges))
+	for i, e := range edges {
+		subs, origins := ps, pOrigins
+		if e.clipping {
+			subs, origins = qs, qOrigins
+		}
+		if len(origins) <= e.segment {
+			continue // close command added to the clipping path
+		}
+		o := origins[e.segment]
+		d := subs[o.sub].d[o.i : o.i+cmdLen(subs[o.sub].d[o.i])]
+		if d[0] == LineToCmd || d[0] == CloseCmd {
+			curves[i] = boCurve{o.pathSegment, o.start, nil, 0.0, 0.0}
+			continue
+		}
+
+		// find position of the endpoints along the original segment
+		pos, _, _ := segmentParametric(o.start, d, Tolerance)
+		a, b := pos(o.t0), pos(o.t1)
+		ab := b.Sub(a)
+		proj := func(z Point) float64 {
+			// endpoints may have been snapped to the grid
+			if z.Sub(a).Length() <= BentleyOttmannEpsilon {
+				return o.t0
+			} else if z.Sub(b).Length() <= BentleyOttmannEpsilon {
+				return o.t1
+			}
+			t := math.Max(0.0, math.Min(1.0, z.Sub(a).Dot(ab)/ab.Dot(ab)))
+			return o.t0 + t*(o.t1-o.t0)
+		}
+		curves[i] = boCurve{o.pathSegment, o.start, d, proj(e.from), proj(e.to)}
+	}
+
+	continues := func(i, j int) bool {
+		// edge j continues along the same original curve as edge i
+		return curves[i].d != nil && curves[j].d != nil && edges[i].clipping == edges[j].clipping && curves[i].pathSegment == curves[j].pathSegment && Equal(curves[i].tTo, curves[j].tFrom) && (curves[i].tFrom < curves[i].tTo) == (curves[j].tFrom < curves[j].tTo)
+	}
+
+	// start at the beginning of an original segment
+	k := 0
+	if closed {
+		for k < len(edges) && continues((k+len(edges)-1)%len(edges), k) {
+			k++
+		}
+		if k == len(edges) {
+			k = 0
+		}
+	}
+
+	// join edges along the same curve
+	runs := []boCurve{}
+	clipping := []bool{}
+	pts := []Point{} // start of each run
+	for n := 0; n < len(edges); {
+		i := (k + n) % len(edges)
+		j := i
+		for n++; n < len(edges) && continues(j, (k+n)%len(edges)); n++ {
+			j = (k + n) % len(edges)
+		}
+		run := curves[i]
+		run.tTo = curves[j].tTo
+		runs = append(runs, run)
+		clipping = append(clipping, edges[i].clipping)
+		pts = append(pts, edges[i].from)
+	}
+	pts = append(pts, edges[(k+len(edges)-1)%len(edges)].to)
+
+	// move vertices between a curve and a segment of the other path to their exact intersection
+	original := func(r int) []float64 {
+		if runs[r].d != nil {
+			return runs[r].d
+		} else if runs[r].i == 0 {
+			return nil // close command added to the clipping path
+		}
+		sub := ps[runs[r].sub]
+		if clipping[r] {
+			sub = qs[runs[r].sub]
+		}
+		return sub.d[runs[r].i : runs[r].i+cmdLen(sub.d[runs[r].i])]
+	}
+	var zs Intersections
+	for r := range runs {
+		if r == 0 && !closed {
+			continue
+		}
+		prev := (r + len(runs) - 1) % len(runs)
+		if clipping[prev] == clipping[r] || runs[prev].d == nil && runs[r].d == nil {
+			continue
+		}
+
+		dPrev, dCur := original(prev), original(r)
+		if dPrev == nil || dCur == nil {
+			continue
+		}
+
+		zs = intersectionSegment(zs[:0], runs[prev].start, dPrev, runs[r].start, dCur)
+		dist := 2.0 * Tolerance
+		for _, z := range zs {
+			if d := z.Sub(pts[r]).Length(); d < dist {
+				dist = d
+				pts[r] = z.Point
+				if runs[prev].d != nil {
+					runs[prev].tTo = z.T[0]
+				}
+				if runs[r].d != nil {
+					runs[r].tFrom = z.T[1]
+				}
+			}
+		}
+		if r == 0 {
+			pts[len(runs)] = pts[0]
+		}
+	}
+
+	R := &Path{}
+	R.MoveTo(pts[0].X, pts[0].Y)
+	for r, run := range runs {
+		end := pts[r+1]
+		if run.d == nil || Equal(run.tFrom, run.tTo) {
+			R.LineTo(end.X, end.Y)
+			continue
+		}
+
+		var seg []float64
+		if run.tFrom < run.tTo {
+			seg = subSegment(run.start, run.d, run.tFrom, run.tTo)
+		} else {
+			seg = subSegment(run.start, run.d, run.tTo, run.tFrom)
+			pos, _, _ := segmentParametric(run.start, run.d, Tolerance)
+			seg = reverseSegment(pos(run.tTo), seg)
+		}
+		seg = append([]float64{}, seg...)
+		seg[len(seg)-3], seg[len(seg)-2] = end.X, end.Y
+		R.appendSegment(seg)
+	}
+	return R
+}
+
 var _ps, _qs Paths
 var _op pathOp
 var _fillRule FillRule
 
+// sweepColumn processes the events of the queue in column x, as the first pass of the
+// Bentley-Ottmann sweep. Right-endpoints are removed from the status and left-endpoints are
+// inserted, while intersections between segments that become adjacent are added to the queue. For
+// each event, visit is called with the event and a node in the status next to it, which is the node
+// of the event after inserting a left-endpoint, and the node below or else above before removing a
+// right-endpoint. The node may be nil. It returns an error if the segment of a right-endpoint is not
+// part of the status, which should never happen but may for degenerate input.
+func sweepColumn(queue *SweepEvents, status *SweepStatus, x float64, zs []Point, visit func(*SweepPoint, *SweepNode)) error {
+	for 0 < len(*queue) && snap(queue.Top().X, BentleyOttmannEpsilon) == x {
+		event := queue.Top()
+		// TODO: breaking intersections into two right and two left endpoints is not the most
+		// efficient. We could keep an intersection-type event and simply swap the order of the
+		// segments in status (note there can be multiple segments crossing in one point). This
+		// would alleviate a 2*m*log(n) search in status to remove/add the segments (m number
+		// of intersections in one point, and n number of segments in status), and instead use
+		// an m/2 number of swap operations. This alleviates pressure on the CompareV method.
+		if !event.left {
+			queue.Pop()
+
+			n := event.other.node
+			if n == nil {
+				return errors.New("right-endpoint not part of status, probably buggy intersection code")
+			} else if n.SweepPoint == nil {
+				return errors.New("other endpoint already removed, probably buggy intersection code")
+			}
+
+			// find intersections between the now adjacent segments
+			prev := n.Prev()
+			next := n.Next()
+			if prev != nil && next != nil {
+				addIntersections(zs, queue, event, prev.SweepPoint, next.SweepPoint)
+			}
+
+			if prev != nil {
+				visit(event, prev)
+			} else {
+				// next can be nil
+				visit(event, next)
+			}
+
+			// remove event from sweep status
+			status.Remove(n)
+		} else {
+			// add intersections to queue
+			prev, next := status.FindPrevNext(event)
+			if prev != nil {
+				addIntersections(zs, queue, event, prev.SweepPoint, event)
+			}
+			if next != nil {
+				addIntersections(zs, queue, event, event, next.SweepPoint)
+			}
+			if queue.Top() != event {
+				// check if the queue order was changed, this happens if the current event
+				// is the left-endpoint of a segment that intersects with an existing segment
+				// that goes below, or when two segments become fully overlapping, which sets
+				// their order in status differently than when one of them extends further
+				continue
+			}
+			queue.Pop()
+
+			// add event to sweep status
+			n := status.InsertAfter(prev, event)
+			visit(event, n)
+		}
+	}
+	return nil
+}
+
 func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 	// TODO: add grid spacing argument
 	// TODO: add Intersects/Touches functions (return bool)
 	// TODO: add Intersections function (return []Point)
-	// TODO: support Cut to cut a path in subpaths between intersections (not polygons)
-	// TODO: support elliptical arcs
 	// TODO: use a red-black tree for the sweepline status?
 	// TODO: use a red-black or 2-4 tree for the sweepline queue (LessH is 33% of time spent now),
 	//       perhaps a red-black tree where the nodes are min-queues of the resulting squares
@@ -1852,7 +2140,7 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 	// - Segments may be vertical.
 	// - The clipping path is implicitly closed, it makes no sense if it is an open path.
 	// - The subject path is currently implicitly closed, but it is WIP to support open paths.
-	// - Paths are currently flattened, but supporting Bézier or elliptical arcs is a WIP.
+	// - Paths are flattened, but Béziers and elliptical arcs are restored in the result.
 
 	// An unaddressed problem in those works is that of numerical accuracies. The main problem is
 	// that calculating the intersections is not precise; the imprecision of the initial endpoints
@@ -1943,11 +2231,6 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 		return []*Path{}
 	}
 
-	// ensure that X-monotone property holds for Béziers and arcs by breaking them up at their
-	// extremes along X (ie. their inflection points along X)
-	// TODO: handle Béziers and arc segments
-	//p = p.XMonotone()
-	//q = q.XMonotone()
 	for i, iMax := 0, len(ps); i < iMax; i++ {
 		split := ps[i].Split()
 		if 1 < len(split) {
@@ -1955,9 +2238,6 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 			ps = append(ps, split[1:]...)
 		}
 	}
-	for i := range ps {
-		ps[i] = ps[i].Flatten(Tolerance)
-	}
 	if qs != nil {
 		for i, iMax := 0, len(qs); i < iMax; i++ {
 			split := qs[i].Split()
@@ -1966,63 +2246,52 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 				qs = append(qs, split[1:]...)
 			}
 		}
-		for i := range qs {
-			qs[i] = qs[i].Flatten(Tolerance)
+	}
+
+	// remove subpaths that cannot affect the result since their bounding box does not touch any
+	// subpath of the other path, a subpath has no windings outside of its bounding box
+	if op == opAND || op == opNOT {
+		ps, qs = pruneDisjoint(ps, qs, op == opAND)
+		if len(qs) == 0 {
+			if op == opAND {
+				return []*Path{}
+			}
+			return ps.Settle(fillRule)
+		} else if len(ps) == 0 {
+			return []*Path{}
+		}
+	}
+
+	// flatten Béziers and arcs and keep track of the original segments, so that we can restore
+	// them in the result polygons
+	// TODO: find intersections between Béziers and arcs directly instead of flattening
+	pSubs, qSubs := ps, qs
+	hasCurves := false
+	for _, p := range append(ps[:len(ps):len(ps)], qs...) {
+		hasCurves = hasCurves || !p.IsFlat()
+	}
+	var pOrigins, qOrigins []flatSegment
+	if hasCurves {
+		ps, pOrigins = flattenSegments(ps)
+		if qs != nil {
+			qs, qOrigins = flattenSegments(qs)
 		}
 	}
 
-	// check for path bounding boxes to overlap
 	// TODO: cluster paths that overlap and treat non-overlapping clusters separately, this
 	// makes the algorithm "more linear"
 	Rs := []*Path{}
-	//var pOverlaps, qOverlaps []bool
-	//if qs != nil {
-	//	pBounds := make([]Rect, len(ps))
-	//	qBounds := make([]Rect, len(qs))
-	//	for i := range ps {
-	//		pBounds[i] = ps[i].FastBounds()
-	//	}
-	//	for i := range qs {
-	//		qBounds[i] = qs[i].FastBounds()
-	//	}
-	//	pOverlaps = make([]bool, len(ps))
-	//	qOverlaps = make([]bool, len(qs))
-	//	for i := range ps {
-	//		for j := range qs {
-	//			if pBounds[i].Touches(qBounds[j]) {
-	//				pOverlaps[i] = true
-	//				qOverlaps[j] = true
-	//			}
-	//		}
-	//		if !pOverlaps[i] && (op == opOR || op == opXOR || op == opNOT) {
-	//			// path bounding boxes do not overlap, thus no intersections
-	//			pOverlaps[i] = true
-	//			//Rs = append(Rs, ps[i].Settle(fillRule))
-	//		}
-	//	}
-	//	for j := range qs {
-	//		if !qOverlaps[j] && (op == opOR || op == opXOR) {
-	//			// path bounding boxes do not overlap, thus no intersections
-	//			qOverlaps[j] = true
-	//			//Rs = append(Rs, qs[j].Settle(fillRule))
-	//		}
-	//	}
-	//}
 
 	// construct the priority queue of sweep events
 	pSeg, qSeg := 0, 0
 	queue := &SweepEvents{}
 	for i := range ps {
-		//if qs == nil || pOverlaps[i] {
 		pSeg = queue.AddPathEndpoints(ps[i], pSeg, false)
-		//}
 	}
 	if qs != nil {
 		for i := range qs {
-			//if qOverlaps[i] {
 			qs[i].Close() // implicitly close all subpaths on Q
 			qSeg = queue.AddPathEndpoints(qs[i], qSeg, true)
-			//}
 		}
 	}
 	queue.Init() // sort from left to right
@@ -2033,6 +2302,11 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 	events := []*SweepPoint{}     // buffer used for ordering status
 	status := &SweepStatus{}      // contains only left events
 	squares := toleranceSquares{} // sorted vertically, squares and their events
+	var x float64                 // current column
+	addToSquare := func(event *SweepPoint, n *SweepNode) {
+		// add event to tolerance square
+		squares.Add(x, event, n)
+	}
 	// TODO: use linked list for toleranceSquares?
 	for 0 < len(*queue) {
 		// TODO: skip or stop depending on operation if we're to the left/right of subject/clipping polygon
@@ -2048,76 +2322,16 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 		// Pass 1
 		// process all events of the current column
 		n := len(squares)
-		x := snap(queue.Top().X, BentleyOttmannEpsilon)
+		x = snap(queue.Top().X, BentleyOttmannEpsilon)
 		//fmt.Println()
 		//fmt.Println("---")
 		//fmt.Println("X", x)
 		//fmt.Println(queue)
 		//fmt.Println(status)
 	BentleyOttmannLoop:
-		for 0 < len(*queue) && snap(queue.Top().X, BentleyOttmannEpsilon) == x {
-			event := queue.Top()
-			// TODO: breaking intersections into two right and two left endpoints is not the most
-			// efficient. We could keep an intersection-type event and simply swap the order of the
-			// segments in status (note there can be multiple segments crossing in one point). This
-			// would alleviate a 2*m*log(n) search in status to remove/add the segments (m number
-			// of intersections in one point, and n number of segments in status), and instead use
-			// an m/2 number of swap operations. This alleviates pressure on the CompareV method.
-			if !event.left {
-				queue.Pop()
-
-				n := event.other.node
-				if n == nil {
-					// should never happen
-					panic("right-endpoint not part of status, probably buggy intersection code")
-					continue
-				} else if n.SweepPoint == nil {
-					// should never happen
-					panic("other endpoint already removed, probably buggy intersection code")
-					continue
-				}
-
-				// find intersections between the now adjacent segments
-				prev := n.Prev()
-				next := n.Next()
-				if prev != nil && next != nil {
-					addIntersections(zs, queue, event, prev.SweepPoint, next.SweepPoint)
-				}
-
-				// add event to tolerance square
-				if prev != nil {
-					squares.Add(x, event, prev)
-				} else {
-					// next can be nil
-					squares.Add(x, event, next)
-				}
-
-				// remove event from sweep status
-				status.Remove(n)
-			} else {
-				// add intersections to queue
-				prev, next := status.FindPrevNext(event)
-				if prev != nil {
-					addIntersections(zs, queue, event, prev.SweepPoint, event)
-				}
-				if next != nil {
-					addIntersections(zs, queue, event, event, next.SweepPoint)
-				}
-				if queue.Top() != event {
-					// check if the queue order was changed, this happens if the current event
-					// is the left-endpoint of a segment that intersects with an existing segment
-					// that goes below, or when two segments become fully overlapping, which sets
-					// their order in status differently than when one of them extends further
-					continue
-				}
-				queue.Pop()
-
-				// add event to sweep status
-				n := status.InsertAfter(prev, event)
-
-				// add event to tolerance square
-				squares.Add(x, event, n)
-			}
+		if err := sweepColumn(queue, status, x, zs, addToSquare); err != nil {
+			// should never happen
+			panic(err)
 		}
 
 		// Pass 2
@@ -2302,6 +2516,7 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 
 	// build resulting polygons
 	var Ropen *Path
+	var edges []boEdge
 	for _, square := range squares {
 		for _, cur := range square.Events {
 			if !cur.left || cur.inResult == 0 {
@@ -2310,6 +2525,7 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 			}
 
 		BuildPath:
+			edges = edges[:0]
 			windings := int16(0) // windings (outside) of the current polygon
 			index := len(Rs) + 1 // index into Rs + 1 to group (outer) filling with (inner) holes, 0 means resultWindings is invalid
 			if op != opDIV {
@@ -2337,6 +2553,8 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 			}
 
 			for {
+				edges = append(edges, boEdge{cur.segment, cur.clipping, cur.Point, cur.other.Point})
+
 				// find segments starting from other endpoint, find the other segment amongst
 				// them, the next segment should be the next going CCW
 				i0 := 0
@@ -2395,6 +2613,9 @@ func bentleyOttmann(ps, qs Paths, op pathOp, fillRule FillRule) Paths {
 				}
 			}
 			first.inResult--
+			if hasCurves {
+				R = recoverCurves(edges, !first.open, pSubs, qSubs, pOrigins, qOrigins)
+			}
 
 			if first.open {
 				// open path, merge separate parts
@@ -2734,6 +2955,41 @@ func eventRelation(rel Relation, zs []Point, event *SweepPoint, rights []*SweepP
 	return rel, zs
 }
 
+// pruneDisjoint removes the subpaths of qs whose bounding box does not touch that of any subpath of ps, and the same for ps if pruneP is set. It uses a spatial index and runs in O((n + k) log n), with n the number of subpaths and k the number of touching pairs.
+func pruneDisjoint(ps, qs Paths, pruneP bool) (Paths, Paths) {
+	qBounds := make([]Rect, len(qs))
+	for j := range qs {
+		qBounds[j] = qs[j].FastBounds()
+	}
+	index := NewRTree(qBounds)
+
+	pTouches := make([]bool, len(ps))
+	qTouches := make([]bool, len(qs))
+	for i := range ps {
+		for _, j := range index.Search(ps[i].FastBounds()) {
+			pTouches[i] = true
+			qTouches[j] = true
+		}
+	}
+
+	if pruneP {
+		ps2 := ps[:0:0]
+		for i := range ps {
+			if pTouches[i] {
+				ps2 = append(ps2, ps[i])
+			}
+		}
+		ps = ps2
+	}
+	qs2 := qs[:0:0]
+	for j := range qs {
+		if qTouches[j] {
+			qs2 = append(qs2, qs[j])
+		}
+	}
+	return ps, qs2
+}
+
 // relate uses the Bentley-Ottmann algorithm to classify geometry intersections using DE-9IM.
 func relate(ps, qs Paths, intersections bool) (Relation, []Point) {
 	// TODO: support arcs
@@ -497,6 +497,64 @@ func TestIntersectionEllipseEllipse(t *testing.T) {
 	Epsilon = origEpsilon
 }
 
+func TestIntersectionCurveCurve(t *testing.T) {
+	var tts = []struct {
+		a, b string
+		zs   []Point
+	}{
+		{"Q5 10 10 0", "M0 5Q5 -5 10 5", []Point{{1.4644660940672625, 2.5}, {8.535533905932738, 2.5}}},
+		{"Q5 10 10 0", "M0 5C5 -5 5 -5 10 5", []Point{{1.345317190825318, 2.3286587128646112}, {8.654682809174682, 2.3286587128646086}}},
+		{"C0 10 10 10 10 0", "M0 5C10 5 10 5 10 10", []Point{{1.1511822396339664, 5.000318736319592}, {8.514240457831098, 5.5203004132339935}}},
+		{"C0 10 10 10 10 0", "M-5 0A5 5 0 0 0 5 0", []Point{{1.0839477219289324, 4.881091818038751}}},
+		{"Q5 10 10 0", "M5 10A5 5 0 0 1 5 0", []Point{{1.0692431112104614, 1.9098300562467176}}},
+		{"Q5 10 10 0", "M20 20Q25 30 30 20", nil},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.a, "x", tt.b), func(t *testing.T) {
+			a := MustParseSVGPath(tt.a).ReverseScanner()
+			b := MustParseSVGPath(tt.b).ReverseScanner()
+			a.Scan()
+			b.Scan()
+
+			da := append([]float64{a.Cmd()}, append(a.Values(), a.Cmd())...)
+			db := append([]float64{b.Cmd()}, append(b.Values(), b.Cmd())...)
+			zs := intersectionCurveCurve(nil, a.Start(), da, b.Start(), db)
+			test.T(t, len(zs), len(tt.zs))
+			posA, _, _ := segmentParametric(a.Start(), da, Tolerance)
+			posB, _, _ := segmentParametric(b.Start(), db, Tolerance)
+			for i, z := range zs {
+				test.T(t, z.Point, tt.zs[i])
+				test.T(t, posA(z.T[0]), z.Point)
+				test.T(t, posB(z.T[1]), z.Point)
+			}
+		})
+	}
+}
+
+func TestIntersectionSegment(t *testing.T) {
+	// path data stores the rotation of arcs in radians
+	var tts = []struct {
+		a, b string
+		zs   []Point
+	}{
+		{"M0 10L10 10", "A10 5 90 0 1 0 20", []Point{{5.0, 10.0}}},
+		{"A10 5 90 0 1 0 20", "M0 10L10 10", []Point{{5.0, 10.0}}},
+		{"M5 0L5 20", "A10 5 90 0 1 0 20", []Point{{5.0, 10.0}}},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.a, "x", tt.b), func(t *testing.T) {
+			a := MustParseSVGPath(tt.a)
+			b := MustParseSVGPath(tt.b)
+			zs := intersectionSegment(nil, Point{a.d[1], a.d[2]}, a.d[4:], Point{b.d[1], b.d[2]}, b.d[4:])
+			points := []Point{}
+			for _, z := range zs {
+				points = append(points, z.Point)
+			}
+			test.T(t, points, tt.zs)
+		})
+	}
+}
+
 func boSP(a, b Point, clipping bool) *SweepPoint {
 	vertical := Equal(a.X, b.X)
 	increasing := a.X < b.X
@@ -970,6 +1028,9 @@ func TestPathAnd(t *testing.T) {
 		{"L1 0L1 3L0 3zM1 0L2 0L2 2L1 2zM1 1L2 1L2 3L1 3z", "M1 0L2 0L2 3L1 3z", "M1 0L2 0L2 3L1 3z"},
 		{"L3 0L3 1L0 1zM0 1L2 1L2 2L0 2zM1 1L3 1L3 2L1 2z", "M0 1L3 1L3 2L0 2z", "M0 1L3 1L3 2L0 2z"},
 
+		// disjoint subpaths
+		{"L2 0L2 2L0 2zM10 0L12 0L12 2L10 2z", "M1 1L3 1L3 3L1 3zM20 20L21 20L21 21L20 21z", "M1 1L2 1L2 2L1 2z"},
+
 		// bugs
 		{"M23 15L24 15L24 16L23 16zM23.4 14L24.4 14L24.4 15L23.4 15z", "M15 16A1 1 0 0 1 16 15L24 15A1 1 0 0 1 25 16L25 24A1 1 0 0 1 24 25L16 25A1 1 0 0 1 15 24z", "M23 15L24 15L24 16L23 16z"},
 		{"M23 15L24 15L24 16L23 16zM24 15.4L25 15.4L25 16.4L24 16.4z", "M14 14L24 14L24 24L14 24z", "M23 15L24 15L24 16L23 16z"},
@@ -1276,6 +1337,9 @@ func TestPathNot(t *testing.T) {
 		{"L5 0L5 1L7 -1", "L10 0L10 10L0 10z", "M6 0L7 -1"},               // touch with parallel
 		{"L5 0L5 -1L7 1", "L10 0L10 10L0 10z", "M5 0L5 -1L6 0"},           // touch with parallel
 
+		// disjoint subpaths
+		{"L2 0L2 2L0 2zM10 0L12 0L12 2L10 2z", "M1 1L3 1L3 3L1 3zM20 20L21 20L21 21L20 21z", "L2 0L2 1L1 1L1 2L0 2zM10 0L12 0L12 2L10 2z"},
+
 		// similar to holes and islands 4
 		{"M0 4L6 4L6 6L0 6zM5 5L6 6L7 5L6 4z", "M1 3L5 3L5 7L1 7z", "M0 4L1 4L1 6L0 6zM5 4L6 4L5 5zM5 5L6 6L5 6zM6 4L7 5L6 6z"},
 		{"M1 3L5 3L5 7L1 7z", "M0 4L6 4L6 6L0 6zM5 5L6 6L7 5L6 4z", "M1 3L5 3L5 4L1 4zM1 6L5 6L5 7L1 7z"},
@@ -1366,6 +1430,37 @@ const (
 	relCoveredBy
 )
 
+func TestPathBooleanCurves(t *testing.T) {
+	circle := "M5 0A5 5 0 0 1 -5 0A5 5 0 0 1 5 0z"
+	square := "L10 0L10 10L0 10z"
+	var tts = []struct {
+		p  string
+		op pathOp
+		q  string
+		r  string
+	}{
+		{circle, opSettle, "", "M-5 0A5 5 0 0 1 5 0A5 5 0 0 1 -5 0z"},
+		{circle, opAND, square, "L5 0A5 5 0 0 1 0 5z"},
+		{circle, opOR, square, "M-5 0A5 5 0 0 1 5 0L10 0L10 10L0 10L0 5A5 5 0 0 1 -5 0z"},
+		{circle, opNOT, square, "M-5 0A5 5 0 0 1 5 0L0 0L0 5A5 5 0 0 1 -5 0z"},
+		{square, opNOT, circle, "M0 5A5 5 0 0 0 5 0L10 0L10 10L0 10z"},
+		{circle, opXOR, square, "M-5 0A5 5 0 0 1 5 0L0 0L0 5A5 5 0 0 1 -5 0zM0 5A5 5 0 0 0 5 0L10 0L10 10L0 10z"},
+		{circle, opAND, "M10 0A5 5 0 0 1 0 0A5 5 0 0 1 10 0z", "A5 5 0 0 1 2.5 -4.330127018922193A5 5 0 0 1 5 0A5 5 0 0 1 2.5 4.330127018922193A5 5 0 0 1 0 0z"},
+		{"Q10 10 20 0z", opAND, "M5 -5L15 -5L15 10L5 10z", "M5 0L15 0L15 3.75Q10 6.25 5 3.75z"},
+		{"C0 10 10 10 10 0z", opAND, "M8 7A3 3 0 0 1 2 7A3 3 0 0 1 8 7z", "M2.0979040225975933 6.239842820183142A3 3 0 0 1 7.902095977389164 6.23984282018675C6.196939974000247 7.920052393272286 3.8030600259997556 7.920052393272286 2.0979040225975933 6.239842820183142z"},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, tt.op, tt.q), func(t *testing.T) {
+			var qs Paths
+			if tt.op != opSettle {
+				qs = MustParseSVGPath(tt.q).Split()
+			}
+			r := bentleyOttmann(MustParseSVGPath(tt.p).Split(), qs, tt.op, NonZero).Merge()
+			test.T(t, r, MustParseSVGPath(tt.r))
+		})
+	}
+}
+
 func TestPathRelate(t *testing.T) {
 	var tts = []struct {
 		p, q string
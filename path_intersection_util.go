@@ -24,7 +24,7 @@ func intersectionSegment(zs Intersections, a0 Point, a []float64, b0 Point, b []
 		} else if b[0] == ArcToCmd {
 			rx := b[1]
 			ry := b[2]
-			phi := b[3] * math.Pi / 180.0
+			phi := b[3]
 			large, sweep := toArcFlags(b[4])
 			cx, cy, theta0, theta1 := ellipseToCenter(b0.X, b0.Y, rx, ry, phi, large, sweep, b[5], b[6])
 			zs = intersectionLineEllipse(zs, a0, Point{a[1], a[2]}, Point{cx, cy}, Point{rx, ry}, phi, theta0, theta1)
@@ -34,40 +34,40 @@ func intersectionSegment(zs Intersections, a0 Point, a []float64, b0 Point, b []
 			zs = intersectionLineQuad(zs, b0, Point{b[1], b[2]}, a0, Point{a[1], a[2]}, Point{a[3], a[4]})
 			swapCurves = true
 		} else if b[0] == QuadToCmd {
-			panic("unsupported intersection for quad-quad")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		} else if b[0] == CubeToCmd {
-			panic("unsupported intersection for quad-cube")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		} else if b[0] == ArcToCmd {
-			panic("unsupported intersection for quad-arc")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		}
 	} else if a[0] == CubeToCmd {
 		if b[0] == LineToCmd || b[0] == CloseCmd {
 			zs = intersectionLineCube(zs, b0, Point{b[1], b[2]}, a0, Point{a[1], a[2]}, Point{a[3], a[4]}, Point{a[5], a[6]})
 			swapCurves = true
 		} else if b[0] == QuadToCmd {
-			panic("unsupported intersection for cube-quad")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		} else if b[0] == CubeToCmd {
-			panic("unsupported intersection for cube-cube")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		} else if b[0] == ArcToCmd {
-			panic("unsupported intersection for cube-arc")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		}
 	} else if a[0] == ArcToCmd {
 		rx := a[1]
 		ry := a[2]
-		phi := a[3] * math.Pi / 180.0
+		phi := a[3]
 		large, sweep := toArcFlags(a[4])
 		cx, cy, theta0, theta1 := ellipseToCenter(a0.X, a0.Y, rx, ry, phi, large, sweep, a[5], a[6])
 		if b[0] == LineToCmd || b[0] == CloseCmd {
 			zs = intersectionLineEllipse(zs, b0, Point{b[1], b[2]}, Point{cx, cy}, Point{rx, ry}, phi, theta0, theta1)
 			swapCurves = true
 		} else if b[0] == QuadToCmd {
-			panic("unsupported intersection for arc-quad")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		} else if b[0] == CubeToCmd {
-			panic("unsupported intersection for arc-cube")
+			zs = intersectionCurveCurve(zs, a0, a, b0, b)
 		} else if b[0] == ArcToCmd {
 			rx2 := b[1]
 			ry2 := b[2]
-			phi2 := b[3] * math.Pi / 180.0
+			phi2 := b[3]
 			large2, sweep2 := toArcFlags(b[4])
 			cx2, cy2, theta20, theta21 := ellipseToCenter(b0.X, b0.Y, rx2, ry2, phi2, large2, sweep2, b[5], b[6])
 			zs = intersectionEllipseEllipse(zs, Point{cx, cy}, Point{rx, ry}, phi, theta0, theta1, Point{cx2, cy2}, Point{rx2, ry2}, phi2, theta20, theta21)
@@ -84,6 +84,89 @@ func intersectionSegment(zs Intersections, a0 Point, a []float64, b0 Point, b []
 	return zs
 }
 
+// intersectionCurveCurve returns the intersections between two Bézier or arc segments a and b, starting at a0 and b0. It intersects the flattened curves and refines each intersection using Newton's method. Note that touching curves are not always found.
+func intersectionCurveCurve(zs Intersections, a0 Point, a []float64, b0 Point, b []float64) Intersections {
+	posA, derivA, nA := segmentParametric(a0, a, Tolerance)
+	posB, derivB, nB := segmentParametric(b0, b, Tolerance)
+	ptsA := make([]Point, nA+1)
+	for i := range ptsA {
+		ptsA[i] = posA(float64(i) / float64(nA))
+	}
+	ptsB := make([]Point, nB+1)
+	for j := range ptsB {
+		ptsB[j] = posB(float64(j) / float64(nB))
+	}
+
+	n := len(zs)
+	var lines Intersections
+	for i := 0; i < nA; i++ {
+		rectA := Rect{ptsA[i].X, ptsA[i].Y, ptsA[i].X, ptsA[i].Y}.AddPoint(ptsA[i+1])
+		for j := 0; j < nB; j++ {
+			rectB := Rect{ptsB[j].X, ptsB[j].Y, ptsB[j].X, ptsB[j].Y}.AddPoint(ptsB[j+1])
+			if !rectA.Touches(rectB) {
+				continue
+			}
+
+			lines = intersectionLineLine(lines[:0], ptsA[i], ptsA[i+1], ptsB[j], ptsB[j+1])
+			for _, line := range lines {
+				if line.Same {
+					continue
+				}
+
+				s := (float64(i) + line.T[0]) / float64(nA)
+				t := (float64(j) + line.T[1]) / float64(nB)
+				s, t = refineIntersection(posA, derivA, posB, derivB, s, t)
+
+				pos := posA(s)
+				if Tolerance < pos.Sub(posB(t)).Length() {
+					continue
+				}
+				duplicate := false
+				for _, z := range zs[n:] {
+					if z.Point.Equals(pos) {
+						duplicate = true
+						break
+					}
+				}
+				if duplicate {
+					continue
+				}
+
+				da, db := derivA(s), derivB(t)
+				endpoint := s == 0.0 || s == 1.0 || t == 0.0 || t == 1.0
+				tangent := Equal(da.Norm(1.0).PerpDot(db.Norm(1.0)), 0.0)
+				zs = zs.add(pos, s, t, da.Angle(), db.Angle(), endpoint || tangent, false)
+			}
+		}
+	}
+	return zs
+}
+
+// refineIntersection refines the intersection at s and t of two curves, given by their position and derivative functions over [0,1], by solving posA(s) = posB(t) using Newton's method.
+func refineIntersection(posA, derivA, posB, derivB func(float64) Point, s, t float64) (float64, float64) {
+	for k := 0; k < 20; k++ {
+		d := posA(s).Sub(posB(t))
+		da, db := derivA(s), derivB(t)
+		cross := da.PerpDot(db)
+		if d.Length() < Epsilon || cross == 0.0 {
+			break
+		}
+		s = math.Max(0.0, math.Min(1.0, s-d.PerpDot(db)/cross))
+		t = math.Max(0.0, math.Min(1.0, t+da.PerpDot(d)/cross))
+	}
+	if Equal(s, 0.0) {
+		s = 0.0
+	} else if Equal(s, 1.0) {
+		s = 1.0
+	}
+	if Equal(t, 0.0) {
+		t = 0.0
+	} else if Equal(t, 1.0) {
+		t = 1.0
+	}
+	return s, t
+}
+
 // Intersection is an intersection between two path segments, e.g. Line x Line. Note that an
 // intersection is tangent also when it is at one of the endpoints, in which case it may be tangent
 // for this segment but may or may not cross the path depending on the adjacent segment.
@@ -11,6 +11,11 @@ import (
 // performance gain for most (trivial) cases.
 var FastStroke = false
 
+// CurvedStroke approximates the offset of Béziers by cubic Béziers within the tolerance for Path.Offset and Path.Stroke, instead of
+// flattening them into lines. This gives much more compact outlines, for example when converting strokes to paths for cutting
+// plotters or vector formats. Elliptical arcs are always offset by elliptical arcs.
+var CurvedStroke = false
+
 // NOTE: implementation inspired from github.com/golang/freetype/raster/stroke.go
 
 // Capper implements Cap, with rhs the path to append to, halfWidth the half width of the stroke, pivot the pivot point around which to construct a cap, and n0 the normal at the start of the path. The length of n0 is equal to the halfWidth.
@@ -436,6 +441,7 @@ type pathStrokeState struct {
 	p0, p1 Point   // position of start and end
 	n0, n1 Point   // normal of start and end (points right when walking the path)
 	r0, r1 float64 // radius of start and end
+	w0, w1 float64 // half width of start and end
 
 	cp1, cp2                    Point   // Béziers
 	rx, ry, rot, theta0, theta1 float64 // arcs
@@ -535,6 +541,15 @@ func (p *Path) offset(halfWidth float64, cr Capper, jr Joiner, strokeOpen bool,
 		start = end
 		i += cmdLen(cmd)
 	}
+	for i := range states {
+		states[i].w0 = halfWidth
+		states[i].w1 = halfWidth
+	}
+	return offsetStates(states, closed, cr, jr, strokeOpen, tolerance)
+}
+
+// offsetStates returns the rhs and lhs paths from offsetting the segments of a path, see offset.
+func offsetStates(states []pathStrokeState, closed bool, cr Capper, jr Joiner, strokeOpen bool, tolerance float64) (*Path, *Path) {
 	if len(states) == 0 {
 		return nil, nil
 	}
@@ -553,14 +568,19 @@ func (p *Path) offset(halfWidth float64, cr Capper, jr Joiner, strokeOpen bool,
 			rhs.LineTo(rEnd.X, rEnd.Y)
 			lhs.LineTo(lEnd.X, lEnd.Y)
 		case CubeToCmd:
-			rhs = rhs.Join(strokeCubicBezier(cur.p0, cur.cp1, cur.cp2, cur.p1, halfWidth, tolerance))
-			lhs = lhs.Join(strokeCubicBezier(cur.p0, cur.cp1, cur.cp2, cur.p1, -halfWidth, tolerance))
+			if CurvedStroke {
+				rhs = rhs.Join(offsetCubicBezier(cur.p0, cur.cp1, cur.cp2, cur.p1, cur.w0, tolerance))
+				lhs = lhs.Join(offsetCubicBezier(cur.p0, cur.cp1, cur.cp2, cur.p1, -cur.w0, tolerance))
+			} else {
+				rhs = rhs.Join(strokeCubicBezier(cur.p0, cur.cp1, cur.cp2, cur.p1, cur.w0, tolerance))
+				lhs = lhs.Join(strokeCubicBezier(cur.p0, cur.cp1, cur.cp2, cur.p1, -cur.w0, tolerance))
+			}
 		case ArcToCmd:
 			rStart := cur.p0.Add(cur.n0)
 			lStart := cur.p0.Sub(cur.n0)
 			rEnd := cur.p1.Add(cur.n1)
 			lEnd := cur.p1.Sub(cur.n1)
-			dr := halfWidth
+			dr := cur.w0
 			if !cur.sweep { // bend to the right, ie. CW
 				dr = -dr
 			}
@@ -596,7 +616,7 @@ func (p *Path) offset(halfWidth float64, cr Capper, jr Joiner, strokeOpen bool,
 			if !cur.n1.Equals(next.n0) {
 				rhsJoinIndex = len(rhs.d)
 				lhsJoinIndex = len(lhs.d)
-				jr.Join(rhs, lhs, halfWidth, cur.p1, cur.n1, next.n0, cur.r1, next.r0)
+				jr.Join(rhs, lhs, cur.w1, cur.p1, cur.n1, next.n0, cur.r1, next.r0)
 			}
 		}
 	}
@@ -619,9 +639,9 @@ func (p *Path) offset(halfWidth float64, cr Capper, jr Joiner, strokeOpen bool,
 		lhs.optimizeClose()
 	} else if strokeOpen {
 		lhs = lhs.Reverse()
-		cr.Cap(rhs, halfWidth, states[len(states)-1].p1, states[len(states)-1].n1)
+		cr.Cap(rhs, states[len(states)-1].w1, states[len(states)-1].p1, states[len(states)-1].n1)
 		rhs = rhs.Join(lhs)
-		cr.Cap(rhs, halfWidth, states[0].p0, states[0].n0.Neg())
+		cr.Cap(rhs, states[0].w0, states[0].p0, states[0].n0.Neg())
 		lhs = nil
 
 		rhs.Close()
@@ -630,7 +650,7 @@ func (p *Path) offset(halfWidth float64, cr Capper, jr Joiner, strokeOpen bool,
 	return rhs, lhs
 }
 
-// Offset offsets the path by w and returns a new path. A positive w will offset the path to the right-hand side, that is, it expands CCW oriented contours and contracts CW oriented contours. If you don't know the orientation you can use `Path.CCW` to find out, but if there may be self-intersection you should use `Path.Settle` to remove them and orient all filling contours CCW. The tolerance is the maximum deviation from the actual offset when flattening Béziers and optimizing the path.
+// Offset offsets the path by w and returns a new path. A positive w will offset the path to the right-hand side, that is, it expands CCW oriented contours and contracts CW oriented contours. If you don't know the orientation you can use `Path.CCW` to find out, but if there may be self-intersection you should use `Path.Settle` to remove them and orient all filling contours CCW. The tolerance is the maximum deviation from the actual offset when flattening or approximating Béziers (see CurvedStroke) and optimizing the path.
 func (p *Path) Offset(w float64, tolerance float64) *Path {
 	if Equal(w, 0.0) {
 		return p
@@ -654,7 +674,7 @@ func (p *Path) Offset(w float64, tolerance float64) *Path {
 	return q
 }
 
-// Stroke converts a path into a stroke of width w and returns a new path. It uses cr to cap the start and end of the path, and jr to join all path elements. If the path closes itself, it will use a join between the start and end instead of capping them. The tolerance is the maximum deviation from the original path when flattening Béziers and optimizing the stroke.
+// Stroke converts a path into a stroke of width w and returns a new path. It uses cr to cap the start and end of the path, and jr to join all path elements. If the path closes itself, it will use a join between the start and end instead of capping them. The tolerance is the maximum deviation from the original path when flattening or approximating Béziers (see CurvedStroke) and optimizing the stroke.
 func (p *Path) Stroke(w float64, cr Capper, jr Joiner, tolerance float64) *Path {
 	if cr == nil {
 		cr = ButtCap
@@ -677,3 +697,93 @@ func (p *Path) Stroke(w float64, cr Capper, jr Joiner, tolerance float64) *Path
 	}
 	return q
 }
+
+// StrokeVariable converts a path into a stroke of variable width and returns a new path. The width is given by widthFunc for t in [0,1], which is the distance along the path divided by its total length, and may be zero at the ends to taper the stroke. It uses cr to cap the start and end of the path, and jr to join all path elements. If the path closes itself, it will use a join between the start and end instead of capping them. The path is flattened, and lines are subdivided so that the width varies linearly along each line. The tolerance is the maximum deviation from the original path and width when flattening and optimizing the stroke.
+func (p *Path) StrokeVariable(widthFunc func(float64) float64, cr Capper, jr Joiner, tolerance float64) *Path {
+	if cr == nil {
+		cr = ButtCap
+	}
+	if jr == nil {
+		jr = MiterJoin
+	}
+	tolerance = math.Max(tolerance, Epsilon) // prevent infinite loop if user sets tolerance to zero
+	halfWidth := func(t float64) float64 {
+		return math.Abs(widthFunc(t)) / 2.0
+	}
+
+	p = p.Flatten(tolerance)
+	length := 0.0
+	for i := cmdLen(MoveToCmd); i < len(p.d); i += cmdLen(p.d[i]) {
+		if p.d[i] != MoveToCmd {
+			length += Point{p.d[i+1], p.d[i+2]}.Sub(Point{p.d[i-3], p.d[i-2]}).Length()
+		}
+	}
+	if Equal(length, 0.0) {
+		return &Path{}
+	}
+
+	// subdivide lines until the width varies linearly
+	var subdivide func([]float64, float64, float64, float64, float64, int) []float64
+	subdivide = func(ts []float64, t0, t1, w0, w1 float64, depth int) []float64 {
+		tm := (t0 + t1) / 2.0
+		wm := halfWidth(tm)
+		if depth < 10 && tolerance < math.Abs(wm-(w0+w1)/2.0) {
+			ts = subdivide(ts, t0, tm, w0, wm, depth+1)
+			return subdivide(ts, tm, t1, wm, w1, depth+1)
+		}
+		return append(ts, t1)
+	}
+
+	q := &Path{}
+	dist := 0.0
+	for _, pi := range p.Split() {
+		closed := false
+		states := []pathStrokeState{}
+		start := Point{pi.d[1], pi.d[2]}
+		for i := cmdLen(MoveToCmd); i < len(pi.d); {
+			cmd := pi.d[i]
+			end := Point{pi.d[i+1], pi.d[i+2]}
+			i += cmdLen(cmd)
+			if cmd == CloseCmd {
+				closed = true
+			}
+			if start.Equals(end) {
+				continue
+			}
+
+			d := end.Sub(start).Length()
+			t0, t1 := dist/length, (dist+d)/length
+			dist += d
+
+			n := end.Sub(start).Rot90CW().Norm(1.0)
+			w0 := halfWidth(t0)
+			ts := subdivide([]float64{t0}, t0, t1, w0, halfWidth(t1), 0)
+			for j := 1; j < len(ts); j++ {
+				w1 := halfWidth(ts[j])
+				states = append(states, pathStrokeState{
+					cmd: LineToCmd,
+					p0:  start.Interpolate(end, (ts[j-1]-t0)/(t1-t0)),
+					p1:  start.Interpolate(end, (ts[j]-t0)/(t1-t0)),
+					n0:  n.Mul(w0),
+					n1:  n.Mul(w1),
+					r0:  math.NaN(),
+					r1:  math.NaN(),
+					w0:  w0,
+					w1:  w1,
+				})
+				w0 = w1
+			}
+			start = end
+		}
+
+		rhs, lhs := offsetStates(states, closed, cr, jr, true, tolerance)
+		if rhs != nil {
+			q = q.Append(rhs).Append(lhs.Reverse())
+		}
+	}
+	if !FastStroke {
+		// fix overlapping and spilled parts
+		q = q.Settle(Positive)
+	}
+	return q
+}
@@ -115,3 +115,78 @@ func TestPathOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestPathOffsetCurved(t *testing.T) {
+	CurvedStroke = true
+	defer func() { CurvedStroke = false }()
+
+	tolerance := 0.01
+	var tts = []struct {
+		orig string
+		n    int
+	}{
+		{"Q10 10 20 0", 5},
+		{"C0 10 10 10 10 0", 5},
+		{"C10 20 20 -10 30 10", 9},
+		{"C30 10 -20 10 10 0", 17}, // offset has cusps
+	}
+	for _, tt := range tts {
+		t.Run(tt.orig, func(t *testing.T) {
+			p := MustParseSVGPath(tt.orig)
+			rhs, _ := p.offset(1.0, ButtCap, RoundJoin, false, tolerance)
+			test.T(t, rhs.Len(), tt.n)
+
+			// compare against the exact offset curve
+			var p0, p1, p2, p3 Point
+			if p.d[4] == QuadToCmd {
+				p1 = Point{p.d[5], p.d[6]}
+				p3 = Point{p.d[7], p.d[8]}
+				p1, p2 = quadraticToCubicBezier(p0, p1, p3)
+			} else {
+				p1, p2, p3 = Point{p.d[5], p.d[6]}, Point{p.d[7], p.d[8]}, Point{p.d[9], p.d[10]}
+			}
+			coords := rhs.Flatten(tolerance / 10.0).Coords()
+			for i := 0; i <= 100; i++ {
+				s := float64(i) / 100.0
+				pos := cubicBezierPos(p0, p1, p2, p3, s).Add(cubicBezierDeriv(p0, p1, p2, p3, s).Rot90CW().Norm(1.0))
+				dist := math.Inf(1)
+				for j := 1; j < len(coords); j++ {
+					a, b := coords[j-1], coords[j]
+					u := math.Max(0.0, math.Min(1.0, pos.Sub(a).Dot(b.Sub(a))/b.Sub(a).Dot(b.Sub(a))))
+					dist = math.Min(dist, pos.Sub(a.Interpolate(b, u)).Length())
+				}
+				test.That(t, dist < 2.0*tolerance, fmt.Sprintf("t=%g: distance %g", s, dist))
+			}
+		})
+	}
+
+	stroke := MustParseSVGPath("Q10 10 20 0").Stroke(2.0, RoundCap, RoundJoin, tolerance)
+	test.T(t, stroke.Len(), 12)
+}
+
+func TestPathStrokeVariable(t *testing.T) {
+	var tts = []struct {
+		orig   string
+		width  func(float64) float64
+		cp     Capper
+		jr     Joiner
+		stroke string
+	}{
+		{"M10 10", func(float64) float64 { return 2.0 }, ButtCap, RoundJoin, ""},
+		{"L10 0", func(float64) float64 { return 2.0 }, ButtCap, RoundJoin, "M0 -1L10 -1L10 1L0 1z"},
+		{"L10 0", func(float64) float64 { return 2.0 }, RoundCap, RoundJoin, "M0 -1L10 -1A1 1 0 0 1 10 1L0 1A1 1 0 0 1 0 -1z"},
+		{"L10 0", func(t float64) float64 { return 2.0 * (1.0 - t) }, ButtCap, RoundJoin, "M0 -1L10 0L0 1z"},
+		{"L10 0L10 10", func(t float64) float64 { return 1.0 + t }, ButtCap, MiterJoin, "M0 -0.5L10 -0.75L10.75 -0.75L10.75 0L11 10L9 10L9.23173017 0.73079325L0 0.5z"},
+		{"L10 0L10 10L0 10z", func(float64) float64 { return 2.0 }, ButtCap, MiterJoin, "M-1 -1L11 -1L11 11L-1 11zM1 1L1 9L9 9L9 1z"},
+	}
+	for _, tt := range tts {
+		t.Run(tt.orig, func(t *testing.T) {
+			stroke := MustParseSVGPath(tt.orig).StrokeVariable(tt.width, tt.cp, tt.jr, 0.01)
+			test.T(t, stroke, MustParseSVGPath(tt.stroke))
+		})
+	}
+
+	// width is subdivided to be linear within tolerance
+	stroke := MustParseSVGPath("L10 0").StrokeVariable(func(t float64) float64 { return 1.0 + t*t }, ButtCap, RoundJoin, 0.01)
+	test.T(t, stroke.Len(), 11)
+}
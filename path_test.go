@@ -423,6 +423,112 @@ func TestPathLength(t *testing.T) {
 	}
 }
 
+func TestPathArea(t *testing.T) {
+	var tts = []struct {
+		p          string
+		fillRule   FillRule
+		area       float64
+		centroid   Point
+		xx, yy, xy float64
+	}{
+		{"", NonZero, 0.0, Point{}, 0.0, 0.0, 0.0},
+		{"L10 0", NonZero, 0.0, Point{5.0, 0.0}, 0.0, 0.0, 0.0},
+		{"L10 0L10 10L0 10z", NonZero, 100.0, Point{5.0, 5.0}, 10000.0 / 12.0, 10000.0 / 12.0, 0.0},
+		{"L0 10L10 10L10 0z", NonZero, 100.0, Point{5.0, 5.0}, 10000.0 / 12.0, 10000.0 / 12.0, 0.0},
+		{"L10 0L10 10", NonZero, 50.0, Point{20.0 / 3.0, 10.0 / 3.0}, 10000.0 / 36.0, 10000.0 / 36.0, 10000.0 / 72.0},
+		{"L20 0L20 10L0 10z", NonZero, 200.0, Point{10.0, 5.0}, 80000.0 / 12.0, 20000.0 / 12.0, 0.0},
+		{"L10 0L10 10L0 10zM2 2L2 8L8 8L8 2z", NonZero, 64.0, Point{5.0, 5.0}, (10000.0 - 1296.0) / 12.0, (10000.0 - 1296.0) / 12.0, 0.0},
+		{"L10 0L10 10L0 10zM2 2L8 2L8 8L2 8z", NonZero, 100.0, Point{5.0, 5.0}, 10000.0 / 12.0, 10000.0 / 12.0, 0.0},
+		{"L10 0L10 10L0 10zM2 2L8 2L8 8L2 8z", EvenOdd, 64.0, Point{5.0, 5.0}, (10000.0 - 1296.0) / 12.0, (10000.0 - 1296.0) / 12.0, 0.0},
+		{"L10 0L10 10L0 10zM5 0L15 0L15 10L5 10z", NonZero, 150.0, Point{7.5, 5.0}, 10.0 * 3375.0 / 12.0, 15.0 * 1000.0 / 12.0, 0.0},
+		{"Q10 10 20 0z", NonZero, 200.0 / 3.0, Point{10.0, 2.0}, 4000.0 / 3.0, 800.0 / 7.0, 0.0},
+		{"C0 10 20 10 20 0z", NonZero, 120.0, Point{10.0, 45.0 / 14.0}, 3064.935064935065, 474.4897959183674, 0.0},
+		{"M5 0A5 5 0 0 1 -5 0A5 5 0 0 1 5 0z", NonZero, 25.0 * math.Pi, Point{0.0, 0.0}, 625.0 * math.Pi / 4.0, 625.0 * math.Pi / 4.0, 0.0},
+		{"M5 0A5 5 0 0 1 -5 0z", NonZero, 12.5 * math.Pi, Point{0.0, 20.0 / 3.0 / math.Pi}, 625.0 * math.Pi / 8.0, 625.0*math.Pi/8.0 - 12.5*math.Pi*(20.0/3.0/math.Pi)*(20.0/3.0/math.Pi), 0.0},
+		{"M4 0A4 2 0 0 1 -4 0A4 2 0 0 1 4 0z", NonZero, 8.0 * math.Pi, Point{0.0, 0.0}, 32.0 * math.Pi, 8.0 * math.Pi, 0.0},
+		{"M2 2A4 2 90 0 1 2 -6A4 2 90 0 1 2 2z", NonZero, 8.0 * math.Pi, Point{2.0, -2.0}, 8.0 * math.Pi, 32.0 * math.Pi, 0.0},
+		{"M2.8284271247461903 2.8284271247461903A4 2 45 0 1 -1.4142135623730951 1.4142135623730951A4 2 45 1 1 2.8284271247461903 2.8284271247461903z", NonZero, 8.0 * math.Pi, Point{0.0, 0.0}, 20.0 * math.Pi, 20.0 * math.Pi, 12.0 * math.Pi},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "/", tt.fillRule), func(t *testing.T) {
+			p := MustParseSVGPath(tt.p)
+			xx, yy, xy := p.SecondMoments(tt.fillRule)
+			defer setEpsilon(1e-6)()
+			test.FloatDiff(t, p.Area(tt.fillRule), tt.area, 1e-6)
+			test.T(t, p.Centroid(tt.fillRule), tt.centroid)
+			test.FloatDiff(t, xx, tt.xx, 1e-6)
+			test.FloatDiff(t, yy, tt.yy, 1e-6)
+			test.FloatDiff(t, xy, tt.xy, 1e-6)
+		})
+	}
+}
+
+func TestPathClosestPoint(t *testing.T) {
+	var tts = []struct {
+		p   string
+		q   Point
+		pos Point
+		seg int
+		t   float64
+	}{
+		{"", Point{5.0, 5.0}, Point{}, 0, 0.0},
+		{"M5 5", Point{0.0, 0.0}, Point{5.0, 5.0}, 0, 0.0},
+		{"L10 0", Point{5.0, 5.0}, Point{5.0, 0.0}, 1, 0.5},
+		{"L10 0", Point{-5.0, 5.0}, Point{0.0, 0.0}, 1, 0.0},
+		{"L10 0L10 10", Point{12.0, 5.0}, Point{10.0, 5.0}, 2, 0.5},
+		{"L10 0L10 10zM20 0", Point{4.0, 6.0}, Point{5.0, 5.0}, 3, 0.5},
+		{"L10 0L10 10zM20 0", Point{19.0, 1.0}, Point{20.0, 0.0}, 4, 0.0},
+		{"Q10 10 20 0", Point{10.0, 10.0}, Point{10.0, 5.0}, 1, 0.5},
+		{"Q10 10 20 0", Point{10.0, 0.0}, Point{10.0, 5.0}, 1, 0.5},
+		{"Q10 10 20 0", Point{25.0, 0.0}, Point{20.0, 0.0}, 1, 1.0},
+		{"C0 10 20 10 20 0", Point{10.0, 20.0}, Point{10.0, 7.5}, 1, 0.5},
+		{"C20 10 0 10 20 0", Point{10.0, 10.0}, Point{10.0, 7.5}, 1, 0.5},
+		{"A10 10 0 0 1 20 0", Point{10.0, -20.0}, Point{10.0, -10.0}, 1, 0.5},
+		{"A10 10 0 0 1 20 0", Point{10.0, 20.0}, Point{0.0, 0.0}, 1, 0.0},
+		{"A10 5 0 0 0 20 0", Point{10.0, 1.0}, Point{10.0, 5.0}, 1, 0.5},
+		{"M5 0A5 5 0 0 1 -5 0A5 5 0 0 1 5 0z", Point{6.0, 8.0}, Point{3.0, 4.0}, 1, math.Atan2(4.0, 3.0) / math.Pi},
+		{"M5 0A5 5 0 0 1 -5 0A5 5 0 0 1 5 0z", Point{-3.0, -4.0}, Point{-3.0, -4.0}, 2, math.Atan2(4.0, 3.0) / math.Pi},
+		{"A10 5 30 0 0 20 0", Point{7.396498955658306, 5.003801132021216}, Point{6.915735825171037, 5.855487410065148}, 1, 0.23336244148530116},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "/", tt.q), func(t *testing.T) {
+			p := MustParseSVGPath(tt.p)
+			pos, seg, tp := p.ClosestPoint(tt.q)
+			defer setEpsilon(1e-6)()
+			test.T(t, pos, tt.pos)
+			test.T(t, seg, tt.seg)
+			test.Float(t, tp, tt.t)
+			if !p.Empty() {
+				test.Float(t, p.Distance(tt.q), tt.q.Sub(tt.pos).Length())
+			}
+		})
+	}
+	test.T(t, (&Path{}).Distance(Point{}), math.Inf(1.0))
+}
+
+func TestPathHausdorffDistance(t *testing.T) {
+	var tts = []struct {
+		p, q string
+		dist float64
+	}{
+		{"", "", 0.0},
+		{"L10 0", "", math.Inf(1.0)},
+		{"L10 0", "L10 0", 0.0},
+		{"L10 0", "M0 2L10 2", 2.0},
+		{"L10 0", "L5 0", 5.0},
+		{"L10 0L10 10L0 10z", "M-1 -1L11 -1L11 11L-1 11z", math.Sqrt2},
+		{"L10 0L10 10L0 10z", "M10 5A5 5 0 0 1 0 5A5 5 0 0 1 10 5z", 5.0*math.Sqrt2 - 5.0},
+		{"Q10 10 20 0", "L20 0", 5.0},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "/", tt.q), func(t *testing.T) {
+			p, q := MustParseSVGPath(tt.p), MustParseSVGPath(tt.q)
+			test.FloatDiff(t, p.HausdorffDistance(q, 0.01), tt.dist, 0.01)
+			test.FloatDiff(t, q.HausdorffDistance(p, 0.01), tt.dist, 0.01)
+		})
+	}
+}
+
 func TestPathTransform(t *testing.T) {
 	var tts = []struct {
 		p string
@@ -612,6 +718,8 @@ func TestPathSplitAt(t *testing.T) {
 		{"A10 10 0 0 1 -20 0", []float64{15.707963}, []string{"A10 10 0 0 1 -10 10", "M-10 10A10 10 0 0 1 -20 0"}},
 		{"A10 10 0 0 0 20 0", []float64{15.707963}, []string{"A10 10 0 0 0 10 10", "M10 10A10 10 0 0 0 20 0"}},
 		{"A10 10 0 1 0 2.9289 -7.0711", []float64{15.707963}, []string{"A10 10 0 0 0 10.024 9.9999", "M10.024 9.9999A10 10 0 1 0 2.9289 -7.0711"}},
+		{"L4 3M10 0L10 5", []float64{2.5}, []string{"L2 1.5", "M2 1.5L4 3M10 0L10 5"}},
+		{"L4 3M10 0L10 5", []float64{7.5}, []string{"L4 3M10 0L10 2.5", "M10 2.5L10 5"}},
 	}
 	origEpsilon := Epsilon
 	for _, tt := range tts {
@@ -636,6 +744,89 @@ func TestPathSplitAt(t *testing.T) {
 	Epsilon = origEpsilon
 }
 
+func TestPathPointAt(t *testing.T) {
+	var tts = []struct {
+		p       string
+		d       float64
+		pos     Point
+		tangent Point
+	}{
+		{"", 1.0, Point{}, Point{}},
+		{"M10 0", 1.0, Point{10.0, 0.0}, Point{}},
+		{"L10 0L10 10", -1.0, Point{0.0, 0.0}, Point{1.0, 0.0}},
+		{"L10 0L10 10", 5.0, Point{5.0, 0.0}, Point{1.0, 0.0}},
+		{"L10 0L10 10", 15.0, Point{10.0, 5.0}, Point{0.0, 1.0}},
+		{"L10 0L10 10", 25.0, Point{10.0, 10.0}, Point{0.0, 1.0}},
+		{"L10 0L10 10L0 10z", 35.0, Point{0.0, 5.0}, Point{0.0, -1.0}},
+		{"L10 0M20 0L30 0", 15.0, Point{25.0, 0.0}, Point{1.0, 0.0}},
+		{"Q10 10 20 0", 11.47793574696319, Point{10.0, 5.0}, Point{1.0, 0.0}},
+		{"C0 10 20 10 20 0", 13.946539368994614, Point{10.0, 7.5}, Point{1.0, 0.0}},
+		{"C0 10 20 10 20 0", 27.893078737989228, Point{20.0, 0.0}, Point{0.0, -1.0}},
+		{"C0 0 10 10 10 0", 0.0, Point{0.0, 0.0}, Point{1.0 / math.Sqrt2, 1.0 / math.Sqrt2}},
+		{"A10 10 0 0 1 -20 0", 15.707963267948966, Point{-10.0, 10.0}, Point{-1.0, 0.0}},
+		{"A10 10 0 0 0 20 0", 15.707963267948966, Point{10.0, 10.0}, Point{1.0, 0.0}},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "/", tt.d), func(t *testing.T) {
+			p := MustParseSVGPath(tt.p)
+			pos, tangent, normal := p.PointAt(tt.d), p.TangentAt(tt.d), p.NormalAt(tt.d)
+			defer setEpsilon(1e-4)()
+			test.T(t, pos, tt.pos)
+			test.T(t, tangent, tt.tangent)
+			test.T(t, normal, tt.tangent.Rot90CW())
+		})
+	}
+}
+
+func TestPathSplitAtLength(t *testing.T) {
+	var tts = []struct {
+		p    string
+		d    float64
+		a, b string
+	}{
+		{"L10 0L10 10", 0.0, "", "L10 0L10 10"},
+		{"L10 0L10 10", 5.0, "L5 0", "M5 0L10 0L10 10"},
+		{"L10 0L10 10", 10.0, "L10 0", "M10 0L10 10"},
+		{"L10 0L10 10", 20.0, "L10 0L10 10", ""},
+		{"L10 0L10 10L0 10z", 15.0, "L10 0L10 5", "M10 5L10 10L0 10L0 0"},
+		{"L10 0L10 10L0 10zM20 0L30 0", 40.0, "L10 0L10 10L0 10z", "M20 0L30 0"},
+		{"L10 0M20 0L30 0", 10.0, "L10 0", "M20 0L30 0"},
+		{"Q10 10 20 0", 11.47793574696319, "Q5 5 10 5", "M10 5Q15 5 20 0"},
+		{"C0 10 20 10 20 0", 13.946539368994614, "C0 5 5 7.5 10 7.5", "M10 7.5C15 7.5 20 5 20 0"},
+		{"A10 10 0 0 1 -20 0", 15.707963267948966, "A10 10 0 0 1 -10 10", "M-10 10A10 10 0 0 1 -20 0"},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p, "/", tt.d), func(t *testing.T) {
+			a, b := MustParseSVGPath(tt.p).SplitAtLength(tt.d)
+			defer setEpsilon(1e-4)()
+			test.T(t, a, MustParseSVGPath(tt.a))
+			test.T(t, b, MustParseSVGPath(tt.b))
+		})
+	}
+}
+
+func TestPathPlaceAlong(t *testing.T) {
+	tick := MustParseSVGPath("M0 -1L0 1")
+	var tts = []struct {
+		p                string
+		offset, interval float64
+		r                string
+	}{
+		{"L10 0", 0.0, 5.0, "M0 -1L0 1M5 -1L5 1M10 -1L10 1"},
+		{"L10 0", -2.0, 4.0, "M2 -1L2 1M6 -1L6 1M10 -1L10 1"},
+		{"L10 0", 3.0, 0.0, "M3 -1L3 1"},
+		{"L10 0L10 10", 5.0, 10.0, "M5 -1L5 1M11 5L9 5"},
+		{"A5 5 0 0 1 10 0", 7.853981633974483, 10.0, "M5 -6L5 -4"},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p), func(t *testing.T) {
+			r := MustParseSVGPath(tt.p).PlaceAlong(tick, tt.offset, tt.interval)
+			defer setEpsilon(1e-4)()
+			test.T(t, r, MustParseSVGPath(tt.r))
+		})
+	}
+}
+
 func TestDashCanonical(t *testing.T) {
 	var tts = []struct {
 		origOffset float64
@@ -956,3 +956,457 @@ func strokeCubicBezier(p0, p1, p2, p3 Point, d, tolerance float64) *Path {
 	}
 	return p
 }
+
+// offsetCubicBezier returns the offset of the cubic Bézier at distance d (positive is to the right) approximated by cubic Béziers within the given tolerance. The curve is split at its inflection points, and each part is fitted by a cubic Bézier with the same tangents at its endpoints and with its control point handles scaled by the change in curvature radius. Parts that cannot be fitted are subdivided, and those that remain are flattened, for example around a cusp of the offset curve.
+func offsetCubicBezier(p0, p1, p2, p3 Point, d, tolerance float64) *Path {
+	tolerance = math.Max(tolerance, Epsilon) // prevent infinite loop if user sets tolerance to zero
+
+	p := &Path{}
+	start := p0.Add(cubicBezierNormal(p0, p1, p2, p3, 0.0, d))
+	p.MoveTo(start.X, start.Y)
+
+	// split at inflection points
+	tPrev := 0.0
+	t1, t2 := findInflectionPointsCubicBezier(p0, p1, p2, p3)
+	for _, t := range []float64{t1, t2} {
+		if !math.IsNaN(t) {
+			q0, q1, q2, q3, r0, r1, r2, r3 := cubicBezierSplit(p0, p1, p2, p3, (t-tPrev)/(1.0-tPrev))
+			addOffsetCubicBezier(p, q0, q1, q2, q3, d, tolerance, 0)
+			p0, p1, p2, p3 = r0, r1, r2, r3
+			tPrev = t
+		}
+	}
+	addOffsetCubicBezier(p, p0, p1, p2, p3, d, tolerance, 0)
+	return p
+}
+
+// addOffsetCubicBezier fits a cubic Bézier to the offset of the cubic Bézier at distance d, and subdivides up to a fixed depth if its deviation is larger than tolerance.
+func addOffsetCubicBezier(p *Path, p0, p1, p2, p3 Point, d, tolerance float64, depth int) {
+	if p0.Equals(p3) && (p0.Equals(p1) || p0.Equals(p2)) {
+		// Bézier has p0=p1=p3 or p0=p2=p3 and thus has no surface or length
+		return
+	}
+
+	n0 := cubicBezierNormal(p0, p1, p2, p3, 0.0, d)
+	n1 := cubicBezierNormal(p0, p1, p2, p3, 1.0, d)
+	q0, q3 := p0.Add(n0), p3.Add(n1)
+
+	// scale handles by the ratio of the offset and original radius of curvature, which is
+	// negative when the offset curve has a cusp
+	ratio0, ratio1 := 1.0, 1.0
+	if r0 := cubicBezierCurvatureRadius(p0, p1, p2, p3, 0.0); !math.IsNaN(r0) {
+		ratio0 = 1.0 + d/r0
+	}
+	if r1 := cubicBezierCurvatureRadius(p0, p1, p2, p3, 1.0); !math.IsNaN(r1) {
+		ratio1 = 1.0 + d/r1
+	}
+	q1 := q0.Add(p1.Sub(p0).Mul(ratio0))
+	q2 := q3.Add(p2.Sub(p3).Mul(ratio1))
+
+	fits := 0.0 < ratio0 && 0.0 < ratio1
+	if fits {
+		const n = 8
+		for i := 1; i < n; i++ {
+			t := float64(i) / n
+			deriv := cubicBezierDeriv(p0, p1, p2, p3, t)
+			if deriv.IsZero() {
+				fits = false
+				break
+			}
+			pos := cubicBezierPos(p0, p1, p2, p3, t).Add(deriv.Rot90CW().Norm(d))
+			if tolerance < pos.Sub(cubicBezierPos(q0, q1, q2, q3, t)).Length() {
+				fits = false
+				break
+			}
+		}
+	}
+
+	if fits {
+		p.CubeTo(q1.X, q1.Y, q2.X, q2.Y, q3.X, q3.Y)
+	} else if depth < 8 {
+		r0, r1, r2, r3, s0, s1, s2, s3 := cubicBezierSplit(p0, p1, p2, p3, 0.5)
+		addOffsetCubicBezier(p, r0, r1, r2, r3, d, tolerance, depth+1)
+		addOffsetCubicBezier(p, s0, s1, s2, s3, d, tolerance, depth+1)
+	} else {
+		p.LineTo(q0.X, q0.Y) // in case the previous part ended elsewhere
+		flattenSmoothCubicBezier(p, p0, p1, p2, p3, d, tolerance)
+	}
+}
+
+////////////////////////////////////////////////////////////////
+// Segments ////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////
+
+// segmentParametric returns the position and derivative functions of the path segment with command values d and starting at start, parametrized by t in [0,1]. For arcs, t is linear in the angle of the ellipse. It also returns the number of steps in t that flatten the segment with a maximum deviation of tolerance.
+func segmentParametric(start Point, d []float64, tolerance float64) (func(float64) Point, func(float64) Point, int) {
+	switch d[0] {
+	case QuadToCmd:
+		p1, p2 := Point{d[1], d[2]}, Point{d[3], d[4]}
+		pos := func(t float64) Point {
+			return quadraticBezierPos(start, p1, p2, t)
+		}
+		deriv := func(t float64) Point {
+			return quadraticBezierDeriv(start, p1, p2, t)
+		}
+		// the chord deviates at most h^2/8*|B''| for a step size h
+		n := math.Sqrt(quadraticBezierDeriv2(start, p1, p2).Length() / (8.0 * tolerance))
+		return pos, deriv, max(1, int(math.Ceil(n)))
+	case CubeToCmd:
+		p1, p2, p3 := Point{d[1], d[2]}, Point{d[3], d[4]}, Point{d[5], d[6]}
+		pos := func(t float64) Point {
+			return cubicBezierPos(start, p1, p2, p3, t)
+		}
+		deriv := func(t float64) Point {
+			return cubicBezierDeriv(start, p1, p2, p3, t)
+		}
+		ddmax := math.Max(cubicBezierDeriv2(start, p1, p2, p3, 0.0).Length(), cubicBezierDeriv2(start, p1, p2, p3, 1.0).Length())
+		n := math.Sqrt(ddmax / (8.0 * tolerance))
+		return pos, deriv, max(1, int(math.Ceil(n)))
+	case ArcToCmd:
+		rx, ry, phi := d[1], d[2], d[3]
+		large, sweep := toArcFlags(d[4])
+		cx, cy, theta0, theta1 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, d[5], d[6])
+		pos := func(t float64) Point {
+			return EllipsePos(rx, ry, phi, cx, cy, theta0+t*(theta1-theta0))
+		}
+		deriv := func(t float64) Point {
+			return ellipseDeriv(rx, ry, phi, true, theta0+t*(theta1-theta0)).Mul(theta1 - theta0)
+		}
+		n := math.Abs(theta1-theta0) * math.Sqrt(math.Max(rx, ry)/(8.0*tolerance))
+		return pos, deriv, max(1, int(math.Ceil(n)))
+	}
+	end := Point{d[1], d[2]}
+	pos := func(t float64) Point {
+		return start.Interpolate(end, t)
+	}
+	deriv := func(t float64) Point {
+		return end.Sub(start)
+	}
+	return pos, deriv, 1
+}
+
+// splitSegment splits the path segment with command values d and starting at start, at the sorted segment parameters ts in (0,1). It returns the command values of the resulting segments.
+func splitSegment(start Point, d []float64, ts []float64) [][]float64 {
+	if len(ts) == 0 {
+		return [][]float64{d}
+	}
+
+	segs := make([][]float64, 0, len(ts)+1)
+	switch d[0] {
+	case LineToCmd, CloseCmd:
+		end := Point{d[1], d[2]}
+		for _, t := range ts {
+			pos := start.Interpolate(end, t)
+			segs = append(segs, []float64{LineToCmd, pos.X, pos.Y, LineToCmd})
+		}
+		segs = append(segs, []float64{LineToCmd, end.X, end.Y, LineToCmd})
+	case QuadToCmd:
+		t0 := 0.0
+		r0, r1, r2 := start, Point{d[1], d[2]}, Point{d[3], d[4]}
+		for _, t := range ts {
+			var q1 Point
+			_, q1, _, r0, r1, r2 = quadraticBezierSplit(r0, r1, r2, (t-t0)/(1.0-t0))
+			t0 = t
+			segs = append(segs, []float64{QuadToCmd, q1.X, q1.Y, r0.X, r0.Y, QuadToCmd})
+		}
+		segs = append(segs, []float64{QuadToCmd, r1.X, r1.Y, r2.X, r2.Y, QuadToCmd})
+	case CubeToCmd:
+		t0 := 0.0
+		r0, r1, r2, r3 := start, Point{d[1], d[2]}, Point{d[3], d[4]}, Point{d[5], d[6]}
+		for _, t := range ts {
+			var q1, q2 Point
+			_, q1, q2, _, r0, r1, r2, r3 = cubicBezierSplit(r0, r1, r2, r3, (t-t0)/(1.0-t0))
+			t0 = t
+			segs = append(segs, []float64{CubeToCmd, q1.X, q1.Y, q2.X, q2.Y, r0.X, r0.Y, CubeToCmd})
+		}
+		segs = append(segs, []float64{CubeToCmd, r1.X, r1.Y, r2.X, r2.Y, r3.X, r3.Y, CubeToCmd})
+	case ArcToCmd:
+		rx, ry, phi := d[1], d[2], d[3]
+		large, sweep := toArcFlags(d[4])
+		cx, cy, theta0, theta1 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, d[5], d[6])
+		theta := theta0
+		for _, t := range ts {
+			thetaT := theta0 + t*(theta1-theta0)
+			pos := EllipsePos(rx, ry, phi, cx, cy, thetaT)
+			segs = append(segs, []float64{ArcToCmd, rx, ry, phi, fromArcFlags(math.Pi < math.Abs(thetaT-theta), sweep), pos.X, pos.Y, ArcToCmd})
+			theta = thetaT
+		}
+		segs = append(segs, []float64{ArcToCmd, rx, ry, phi, fromArcFlags(math.Pi < math.Abs(theta1-theta), sweep), d[5], d[6], ArcToCmd})
+	}
+	return segs
+}
+
+// appendSegment appends a path segment given by its command values. Close commands are appended as lines.
+func (p *Path) appendSegment(d []float64) {
+	switch d[0] {
+	case LineToCmd, CloseCmd:
+		p.LineTo(d[1], d[2])
+	case QuadToCmd:
+		p.QuadTo(d[1], d[2], d[3], d[4])
+	case CubeToCmd:
+		p.CubeTo(d[1], d[2], d[3], d[4], d[5], d[6])
+	case ArcToCmd:
+		large, sweep := toArcFlags(d[4])
+		p.ArcTo(d[1], d[2], d[3]*180.0/math.Pi, large, sweep, d[5], d[6])
+	}
+}
+
+// subSegment returns the command values of the part of the path segment with command values d and starting at start between t0 and t1, with 0 <= t0 < t1 <= 1.
+func subSegment(start Point, d []float64, t0, t1 float64) []float64 {
+	ts := []float64{}
+	if Epsilon < t0 {
+		ts = append(ts, t0)
+	}
+	if t1 < 1.0-Epsilon {
+		ts = append(ts, t1)
+	}
+	segs := splitSegment(start, d, ts)
+	if Epsilon < t0 {
+		return segs[1]
+	}
+	return segs[0]
+}
+
+// reverseSegment returns the command values of the path segment with command values d and starting at start, in the opposite direction.
+func reverseSegment(start Point, d []float64) []float64 {
+	switch d[0] {
+	case QuadToCmd:
+		return []float64{QuadToCmd, d[1], d[2], start.X, start.Y, QuadToCmd}
+	case CubeToCmd:
+		return []float64{CubeToCmd, d[3], d[4], d[1], d[2], start.X, start.Y, CubeToCmd}
+	case ArcToCmd:
+		large, sweep := toArcFlags(d[4])
+		return []float64{ArcToCmd, d[1], d[2], d[3], fromArcFlags(large, !sweep), start.X, start.Y, ArcToCmd}
+	}
+	return []float64{d[0], start.X, start.Y, d[0]}
+}
+
+// segmentClosestPoint returns the point on the path segment with command values d and starting at start that is closest to q, and its t in [0.0,1.0] along the segment. It is exact up to floating-point precision by finding the roots of the derivative of the squared distance.
+func segmentClosestPoint(start Point, d []float64, q Point) (Point, float64) {
+	end := Point{d[len(d)-3], d[len(d)-2]}
+	switch d[0] {
+	case LineToCmd, CloseCmd:
+		v := end.Sub(start)
+		if v.IsZero() {
+			return start, 0.0
+		}
+		t := math.Max(0.0, math.Min(1.0, q.Sub(start).Dot(v)/v.Dot(v)))
+		return start.Interpolate(end, t), t
+	case QuadToCmd, CubeToCmd:
+		// roots of (B(t)-q)·B'(t) = 0
+		x, y := bezierPolynomial(start, d)
+		x[0] -= q.X
+		y[0] -= q.Y
+		f := polynomialMul(x, polynomialDeriv(x))
+		g := polynomialMul(y, polynomialDeriv(y))
+		for k := range f {
+			f[k] += g[k]
+		}
+
+		pos, tMin, distMin := start, 0.0, q.Sub(start).Length()
+		if dist := q.Sub(end).Length(); dist < distMin {
+			pos, tMin, distMin = end, 1.0, dist
+		}
+		for _, t := range polynomialRoots(f, 0.0, 1.0) {
+			p := Point{polynomialEval(x, t), polynomialEval(y, t)}
+			if dist := p.Length(); dist < distMin {
+				pos, tMin, distMin = p.Add(q), t, dist
+			}
+		}
+		return pos, tMin
+	case ArcToCmd:
+		rx, ry, phi := d[1], d[2], d[3]
+		large, sweep := toArcFlags(d[4])
+		if f := ellipseRadiiCorrection(start, rx, ry, phi, end); 1.0 < f {
+			rx *= f
+			ry *= f
+		}
+		cx, cy, theta1, theta2 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, end.X, end.Y)
+
+		// roots of (E(θ)-q)·E'(θ) = A·sinθ·cosθ + B·sinθ - C·cosθ = 0 for the unrotated ellipse at the origin,
+		// by substituting u = tan(θ/2) and u = tan((θ-π)/2) for θ in [-π/2,π/2] and [π/2,3π/2] respectively
+		qp := q.Sub(Point{cx, cy}).Rot(-phi, Origin)
+		A, B, C := ry*ry-rx*rx, rx*qp.X, ry*qp.Y
+		thetas := []float64{}
+		for _, u := range polynomialRoots([]float64{-C, 2.0 * (A + B), 0.0, 2.0 * (B - A), C}, -1.0, 1.0) {
+			thetas = append(thetas, 2.0*math.Atan(u))
+		}
+		for _, u := range polynomialRoots([]float64{C, 2.0 * (A - B), 0.0, -2.0 * (A + B), -C}, -1.0, 1.0) {
+			thetas = append(thetas, 2.0*math.Atan(u)+math.Pi)
+		}
+
+		pos, tMin, distMin := start, 0.0, q.Sub(start).Length()
+		if dist := q.Sub(end).Length(); dist < distMin {
+			pos, tMin, distMin = end, 1.0, dist
+		}
+		for _, theta := range thetas {
+			if angleBetween(theta, theta1, theta2) {
+				p := EllipsePos(rx, ry, phi, cx, cy, theta)
+				if dist := q.Sub(p).Length(); dist < distMin {
+					pos, tMin, distMin = p, math.Max(0.0, math.Min(1.0, angleTime(theta, theta1, theta2))), dist
+				}
+			}
+		}
+		return pos, tMin
+	}
+	return start, 0.0
+}
+
+// bezierPolynomial returns the polynomials x(t) and y(t) with coefficients p[k] for t^k of the line or Bézier path segment with command values d and starting at start.
+func bezierPolynomial(start Point, d []float64) ([]float64, []float64) {
+	end := Point{d[len(d)-3], d[len(d)-2]}
+	switch d[0] {
+	case QuadToCmd:
+		cp := Point{d[1], d[2]}
+		c1 := cp.Sub(start).Mul(2.0)
+		c2 := start.Sub(cp.Mul(2.0)).Add(end)
+		return []float64{start.X, c1.X, c2.X}, []float64{start.Y, c1.Y, c2.Y}
+	case CubeToCmd:
+		cp1, cp2 := Point{d[1], d[2]}, Point{d[3], d[4]}
+		c1 := cp1.Sub(start).Mul(3.0)
+		c2 := start.Sub(cp1.Mul(2.0)).Add(cp2).Mul(3.0)
+		c3 := end.Sub(start).Add(cp1.Sub(cp2).Mul(3.0))
+		return []float64{start.X, c1.X, c2.X, c3.X}, []float64{start.Y, c1.Y, c2.Y, c3.Y}
+	}
+	return []float64{start.X, end.X - start.X}, []float64{start.Y, end.Y - start.Y}
+}
+
+// pathMoments are the area integrals of a region, i.e. the area a = ∬dA, the first moments x = ∬x dA and y = ∬y dA, and the second moments xx = ∬x² dA, yy = ∬y² dA, and xy = ∬xy dA. They are calculated exactly as line integrals over the boundary using Green's theorem.
+type pathMoments struct {
+	a, x, y, xx, yy, xy float64
+}
+
+// addSegment adds the line integrals of the path segment with command values d and starting at start. The moments are correct only after adding all segments of closed subpaths, where area enclosed CCW is positive.
+func (m *pathMoments) addSegment(start Point, d []float64) {
+	end := Point{d[len(d)-3], d[len(d)-2]}
+	switch d[0] {
+	case LineToCmd, CloseCmd, QuadToCmd, CubeToCmd:
+		x, y := bezierPolynomial(start, d)
+		m.add(x, y, polynomialMul, polynomialDeriv, polynomialIntegral)
+	case ArcToCmd:
+		rx, ry, phi := d[1], d[2], d[3]
+		large, sweep := toArcFlags(d[4])
+		if f := ellipseRadiiCorrection(start, rx, ry, phi, end); 1.0 < f {
+			rx *= f
+			ry *= f
+		}
+		cx, cy, theta1, theta2 := ellipseToCenter(start.X, start.Y, rx, ry, phi, large, sweep, end.X, end.Y)
+
+		// trigonometric polynomial in theta of the ellipse
+		sinphi, cosphi := math.Sincos(phi)
+		integral := func(p []float64) float64 {
+			return trigonometricIntegral(p, theta1, theta2)
+		}
+		m.add([]float64{cx, rx * cosphi, -ry * sinphi}, []float64{cy, rx * sinphi, ry * cosphi}, trigonometricMul, trigonometricDeriv, integral)
+	}
+}
+
+// add adds the line integrals of the curve (x(t),y(t)) for polynomials of a type that can be multiplied, derived, and integrated over the curve's interval.
+func (m *pathMoments) add(x, y []float64, mul func([]float64, []float64) []float64, deriv func([]float64) []float64, integral func([]float64) float64) {
+	dx, dy := deriv(x), deriv(y)
+	x2, y2 := mul(x, x), mul(y, y)
+	m.a += integral(mul(x, dy))
+	m.x += integral(mul(x2, dy)) / 2.0
+	m.y -= integral(mul(y2, dx)) / 2.0
+	m.xx += integral(mul(mul(x2, x), dy)) / 3.0
+	m.yy -= integral(mul(mul(y2, y), dx)) / 3.0
+	m.xy += integral(mul(mul(x2, y), dy)) / 2.0
+}
+
+// polynomialMul multiplies two polynomials with coefficients p[k] for t^k.
+func polynomialMul(p, q []float64) []float64 {
+	r := make([]float64, len(p)+len(q)-1)
+	for i := range p {
+		for j := range q {
+			r[i+j] += p[i] * q[j]
+		}
+	}
+	return r
+}
+
+// polynomialDeriv returns the derivative of a polynomial with coefficients p[k] for t^k.
+func polynomialDeriv(p []float64) []float64 {
+	if len(p) == 1 {
+		return []float64{0.0}
+	}
+	r := make([]float64, len(p)-1)
+	for k := 1; k < len(p); k++ {
+		r[k-1] = float64(k) * p[k]
+	}
+	return r
+}
+
+// polynomialIntegral returns the integral over [0,1] of a polynomial with coefficients p[k] for t^k.
+func polynomialIntegral(p []float64) float64 {
+	r := 0.0
+	for k := range p {
+		r += p[k] / float64(k+1)
+	}
+	return r
+}
+
+// trigonometricMul multiplies two trigonometric polynomials with coefficients p[0] for the constant, and p[2n-1] and p[2n] for cos(nθ) and sin(nθ) respectively.
+func trigonometricMul(p, q []float64) []float64 {
+	r := make([]float64, len(p)+len(q)-1)
+	add := func(n int, sin bool, v float64) {
+		if n < 0 {
+			n = -n
+			if sin {
+				v = -v
+			}
+		}
+		if n == 0 {
+			if !sin {
+				r[0] += v
+			}
+		} else if sin {
+			r[2*n] += v
+		} else {
+			r[2*n-1] += v
+		}
+	}
+	for i := range p {
+		m, sinm := (i+1)/2, i != 0 && i%2 == 0
+		for j := range q {
+			n, sinn := (j+1)/2, j != 0 && j%2 == 0
+			v := p[i] * q[j] / 2.0
+			if !sinm && !sinn {
+				add(m-n, false, v)
+				add(m+n, false, v)
+			} else if sinm && sinn {
+				add(m-n, false, v)
+				add(m+n, false, -v)
+			} else if sinm {
+				add(m+n, true, v)
+				add(m-n, true, v)
+			} else {
+				add(m+n, true, v)
+				add(m-n, true, -v)
+			}
+		}
+	}
+	return r
+}
+
+// trigonometricDeriv returns the derivative of a trigonometric polynomial, see trigonometricMul.
+func trigonometricDeriv(p []float64) []float64 {
+	r := make([]float64, len(p))
+	for i := 1; i+1 < len(p); i += 2 {
+		n := float64((i + 1) / 2)
+		r[i] = n * p[i+1]
+		r[i+1] = -n * p[i]
+	}
+	return r
+}
+
+// trigonometricIntegral returns the integral over [theta1,theta2] of a trigonometric polynomial, see trigonometricMul.
+func trigonometricIntegral(p []float64, theta1, theta2 float64) float64 {
+	r := p[0] * (theta2 - theta1)
+	for i := 1; i+1 < len(p); i += 2 {
+		n := float64((i + 1) / 2)
+		sin1, cos1 := math.Sincos(n * theta1)
+		sin2, cos2 := math.Sincos(n * theta2)
+		r += (p[i]*(sin2-sin1) - p[i+1]*(cos2-cos1)) / n
+	}
+	return r
+}
@@ -0,0 +1,181 @@
+package canvas
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PathIssueKind is the kind of problem found by Path.Validate.
+type PathIssueKind int
+
+// see PathIssueKind
+const (
+	SelfIntersection PathIssueKind = iota
+	ZeroLengthSegment
+	DegenerateArc
+	UnclosedSubpath
+	WrongOrientation
+)
+
+func (kind PathIssueKind) String() string {
+	switch kind {
+	case SelfIntersection:
+		return "SelfIntersection"
+	case ZeroLengthSegment:
+		return "ZeroLengthSegment"
+	case DegenerateArc:
+		return "DegenerateArc"
+	case UnclosedSubpath:
+		return "UnclosedSubpath"
+	case WrongOrientation:
+		return "WrongOrientation"
+	}
+	return fmt.Sprintf("PathIssueKind(%d)", int(kind))
+}
+
+// PathIssue is a problem found by Path.Validate. Segment is the index of the segment, counting all commands of the path including MoveTo and Close from zero, see Path.Len. Other is the index of the other segment for self-intersections and equals Segment otherwise. Pos is the position of the problem.
+type PathIssue struct {
+	Kind           PathIssueKind
+	Segment, Other int
+	Pos            Point
+}
+
+func (issue PathIssue) String() string {
+	if issue.Kind == SelfIntersection {
+		return fmt.Sprintf("%v of segments %d and %d at %v", issue.Kind, issue.Segment, issue.Other, issue.Pos)
+	}
+	return fmt.Sprintf("%v at segment %d at %v", issue.Kind, issue.Segment, issue.Pos)
+}
+
+// Validate checks path p for geometry that may break boolean operations or render unexpectedly, and returns the problems found ordered by segment. It is nil when p is valid. Reported are:
+//   - self-intersections and touches between any two segments of all subpaths, excluding where consecutive segments join, at the point of intersection;
+//   - segments of zero length, excluding a Close that ends at the start of the subpath, at their start;
+//   - arcs with zero radii or radii that are too small to reach the end point, at their start.
+//
+// If fill is set, p is validated as a filling path and additionally reported are:
+//   - subpaths that are not closed, at their last segment and end point;
+//   - subpaths whose orientation does not alternate with their depth of nesting, at their MoveTo and start point. Outer rings must be CCW and holes CW as is returned by Settle. Only subpaths that do not intersect any segment are checked.
+//
+// Intersections are found using the Bentley-Ottmann sweep in O((n + k) log n), with n the number of segments, and k the number of intersections. An error is returned if the sweep fails, which may happen for degenerate input.
+func (p *Path) Validate(fill bool) ([]PathIssue, error) {
+	var issues []PathIssue
+
+	// clean subpaths of zero-length segments and degenerate arcs for finding intersections
+	var subs Paths
+	moveTos := []int{}            // segment index of MoveTo for each subpath
+	closed := []bool{}            // subpath was closed
+	segs := map[pathSegment]int{} // segment index for each segment of subs
+	seg := 0
+	var start Point
+	for i := 0; i < len(p.d); seg++ {
+		cmd := p.d[i]
+		d := p.d[i : i+cmdLen(cmd)]
+		i += cmdLen(cmd)
+
+		end := Point{d[len(d)-3], d[len(d)-2]}
+		if cmd == MoveToCmd {
+			if 0 < len(subs) && len(subs[len(subs)-1].d) == cmdLen(MoveToCmd) {
+				// remove empty subpath
+				subs, moveTos, closed = subs[:len(subs)-1], moveTos[:len(moveTos)-1], closed[:len(closed)-1]
+			}
+			sub := &Path{}
+			sub.MoveTo(end.X, end.Y)
+			subs = append(subs, sub)
+			moveTos = append(moveTos, seg)
+			closed = append(closed, false)
+			start = end
+			continue
+		}
+
+		zero := start.Equals(end)
+		switch cmd {
+		case QuadToCmd:
+			zero = zero && start.Equals(Point{d[1], d[2]})
+		case CubeToCmd:
+			zero = zero && start.Equals(Point{d[1], d[2]}) && start.Equals(Point{d[3], d[4]})
+		}
+
+		sub := subs[len(subs)-1]
+		if zero {
+			if cmd != CloseCmd {
+				issues = append(issues, PathIssue{ZeroLengthSegment, seg, seg, start})
+			} else {
+				// snap to start so that the first and last segment join
+				closed[len(closed)-1] = true
+				sub.d[len(sub.d)-3], sub.d[len(sub.d)-2] = end.X, end.Y
+			}
+			start = end
+			continue
+		} else if cmd == ArcToCmd {
+			rx, ry, phi := d[1], d[2], d[3]
+			if Equal(rx, 0.0) || Equal(ry, 0.0) {
+				issues = append(issues, PathIssue{DegenerateArc, seg, seg, start})
+				segs[pathSegment{len(subs) - 1, len(sub.d)}] = seg
+				sub.d = append(sub.d, LineToCmd, end.X, end.Y, LineToCmd)
+				start = end
+				continue
+			} else if 1.0+Epsilon < ellipseRadiiCorrection(start, rx, ry, phi, end) {
+				issues = append(issues, PathIssue{DegenerateArc, seg, seg, start})
+			}
+		}
+		segs[pathSegment{len(subs) - 1, len(sub.d)}] = seg
+		sub.d = append(sub.d, d...)
+		if cmd == CloseCmd {
+			closed[len(closed)-1] = true
+		}
+		start = end
+	}
+	if 0 < len(subs) && len(subs[len(subs)-1].d) == cmdLen(MoveToCmd) {
+		subs, moveTos, closed = subs[:len(subs)-1], moveTos[:len(moveTos)-1], closed[:len(closed)-1]
+	}
+
+	// self-intersections
+	flats, origins := flattenSegments(subs)
+	intersects := make([]bool, len(subs))
+	zs, err := selfIntersections(subs, flats, origins)
+	if err != nil {
+		return nil, err
+	}
+	for _, z := range zs {
+		issues = append(issues, PathIssue{SelfIntersection, segs[z.a], segs[z.b], z.Point})
+		intersects[z.a.sub] = true
+		intersects[z.b.sub] = true
+	}
+
+	if fill {
+		for k, sub := range subs {
+			if !closed[k] {
+				n := len(sub.d) - cmdLen(sub.d[len(sub.d)-1])
+				issues = append(issues, PathIssue{UnclosedSubpath, segs[pathSegment{k, n}], segs[pathSegment{k, n}], sub.Pos()})
+			}
+		}
+
+		for k, sub := range subs {
+			if intersects[k] {
+				continue
+			}
+
+			// count the subpaths that contain this subpath
+			depth := 0
+			pos := sub.sample()
+			for j, sub2 := range subs {
+				if j != k {
+					if n, boundary := sub2.WindingsAt(pos.X, pos.Y); n != 0 && !boundary {
+						depth++
+					}
+				}
+			}
+			if sub.CCW() != (depth%2 == 0) {
+				issues = append(issues, PathIssue{WrongOrientation, moveTos[k], moveTos[k], sub.StartPos()})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Segment == issues[j].Segment {
+			return issues[i].Other < issues[j].Other
+		}
+		return issues[i].Segment < issues[j].Segment
+	})
+	return issues, nil
+}
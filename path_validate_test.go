@@ -0,0 +1,59 @@
+package canvas
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestPathValidate(t *testing.T) {
+	var tts = []struct {
+		p      string
+		fill   bool
+		issues []PathIssue
+	}{
+		{"", true, nil},
+		{"M0 0L10 0L10 10L0 10z", true, nil},
+		{"M0 0L10 0L10 10L0 10L0 0z", true, nil},
+		{"M0 0L10 0L10 10L0 10zM2 2L2 8L8 8L8 2z", true, nil},
+		{"M0 0L10 0L10 10L0 10", false, nil},
+		{"M0 0L10 0L10 10L0 10", true, []PathIssue{{UnclosedSubpath, 3, 3, Point{0.0, 10.0}}}},
+		{"M0 0L0 10L10 10L10 0z", true, []PathIssue{{WrongOrientation, 0, 0, Point{0.0, 0.0}}}},
+		{"M0 0L0 10L10 10L10 0z", false, nil},
+		{"M0 0L10 0L10 10L0 10zM2 2L8 2L8 8L2 8z", true, []PathIssue{{WrongOrientation, 5, 5, Point{2.0, 2.0}}}},
+
+		// self-intersections
+		{"M0 0L10 10L10 0L0 10z", true, []PathIssue{{SelfIntersection, 1, 3, Point{5.0, 5.0}}}},
+		{"M0 0L10 0L5 0L5 5", false, []PathIssue{{SelfIntersection, 1, 2, Point{5.0, 0.0}}, {SelfIntersection, 1, 3, Point{5.0, 0.0}}}},
+		{"M0 0L10 0L10 10L0 10zM5 5L15 5L15 15L5 15z", true, []PathIssue{{SelfIntersection, 2, 6, Point{10.0, 5.0}}, {SelfIntersection, 3, 9, Point{5.0, 10.0}}}},
+		{"M0 0C20 10 -10 10 10 0", false, []PathIssue{{SelfIntersection, 1, 1, Point{5.0, 3.0}}}},
+		{"M0 0A5 5 0 0 1 10 0L10 -5L0 5", false, []PathIssue{{SelfIntersection, 1, 3, Point{8.535533905932738, -3.535533905932738}}}},
+	}
+	for _, tt := range tts {
+		t.Run(fmt.Sprint(tt.p), func(t *testing.T) {
+			issues, err := MustParseSVGPath(tt.p).Validate(tt.fill)
+			test.Error(t, err)
+			test.T(t, len(issues), len(tt.issues))
+			for i := range issues {
+				if i < len(tt.issues) {
+					test.T(t, issues[i].Kind, tt.issues[i].Kind)
+					test.T(t, issues[i].Segment, tt.issues[i].Segment)
+					test.T(t, issues[i].Other, tt.issues[i].Other)
+					test.T(t, issues[i].Pos, tt.issues[i].Pos)
+				}
+			}
+		})
+	}
+
+	p := &Path{}
+	p.MoveTo(0.0, 0.0)
+	p.d = append(p.d, LineToCmd, 0.0, 0.0, LineToCmd)
+	p.LineTo(10.0, 0.0)
+	p.d = append(p.d, ArcToCmd, 0.0, 5.0, 0.0, 0.0, 10.0, 10.0, ArcToCmd)
+	p.d = append(p.d, ArcToCmd, 1.0, 1.0, 0.0, 0.0, 0.0, 10.0, ArcToCmd)
+	p.Close()
+	issues, err := p.Validate(true)
+	test.Error(t, err)
+	test.T(t, fmt.Sprint(issues), "[ZeroLengthSegment at segment 1 at (0,0) DegenerateArc at segment 3 at (10,0) DegenerateArc at segment 4 at (10,10)]")
+}
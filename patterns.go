@@ -49,8 +49,22 @@ type HatchPattern struct {
 	Thickness float64
 	cell      Matrix
 	hatch     Hatcher
+
+	// for serialization of the predefined hatches, see NewLineHatch, NewCrossHatch, and NewShapeHatch
+	kind     hatchKind
+	shape    *Path
+	distance float64
 }
 
+type hatchKind int
+
+const (
+	customHatch hatchKind = iota
+	lineHatch
+	crossHatch
+	shapeHatch
+)
+
 // Hatcher is a hatch pattern along the cell's axes. The rectangle (x0,y0)-(x1,y1) is expressed in the unit cell's coordinate system, and the returned path should be transformed by the cell to obtain the final hatch pattern.
 type Hatcher func(float64, float64, float64, float64) *Path
 
@@ -82,18 +96,19 @@ func (p *HatchPattern) Transform(view Matrix) Pattern {
 	return p
 }
 
-// SetColorSpace sets the color space. Automatically called by the rasterizer.
+// SetColorSpace returns a copy of the pattern with its fill converted to the linear color space. Automatically called by the rasterizer.
 func (p *HatchPattern) SetColorSpace(colorSpace ColorSpace) Pattern {
 	if _, ok := colorSpace.(LinearColorSpace); ok {
 		return p
 	}
 
+	pattern := *p
 	if p.Fill.IsGradient() {
-		p.Fill.Gradient.SetColorSpace(colorSpace)
+		pattern.Fill.Gradient = p.Fill.Gradient.SetColorSpace(colorSpace)
 	} else if p.Fill.IsColor() {
-		p.Fill.Color = colorSpace.ToLinear(p.Fill.Color)
+		pattern.Fill.Color = colorSpace.ToLinear(p.Fill.Color)
 	}
-	return p
+	return &pattern
 }
 
 // Tile tiles the hatch pattern within the clipping path.
@@ -135,7 +150,11 @@ func (p *HatchPattern) RenderTo(r Renderer, clip *Path) {
 // NewLineHatch returns a new line hatch pattern with lines at an angle with a spacing of distance. Thickness is the stroke thickness applied to the shape; stroking is ignored with thickness is zero.
 func NewLineHatch(ifill any, angle, distance, thickness float64) *HatchPattern {
 	cell := Identity.Rotate(angle).Scale(distance, distance)
-	return NewHatchPattern(ifill, thickness, cell, func(x0, y0, x1, y1 float64) *Path {
+	return newLineHatch(ifill, thickness, cell)
+}
+
+func newLineHatch(ifill any, thickness float64, cell Matrix) *HatchPattern {
+	pattern := NewHatchPattern(ifill, thickness, cell, func(x0, y0, x1, y1 float64) *Path {
 		p := &Path{}
 		for y := math.Floor(y0); y <= y1; y += 1.0 {
 			p.MoveTo(x0, y)
@@ -143,6 +162,8 @@ func NewLineHatch(ifill any, angle, distance, thickness float64) *HatchPattern {
 		}
 		return p
 	})
+	pattern.kind = lineHatch
+	return pattern
 }
 
 // NewCrossHatch returns a new cross hatch pattern of two regular line hatches at different angles and with different distance intervals. Thickness is the stroke thickness applied to the shape; stroking is ignored with thickness is zero.
@@ -151,7 +172,11 @@ func NewCrossHatch(ifill any, angle0, angle1, distance0, distance1, thickness fl
 		Point{distance0, 0.0}.Rot(angle0*math.Pi/180.0, Origin),
 		Point{distance1, 0.0}.Rot(angle1*math.Pi/180.0, Origin),
 	)
-	return NewHatchPattern(ifill, thickness, cell, func(x0, y0, x1, y1 float64) *Path {
+	return newCrossHatch(ifill, thickness, cell)
+}
+
+func newCrossHatch(ifill any, thickness float64, cell Matrix) *HatchPattern {
+	pattern := NewHatchPattern(ifill, thickness, cell, func(x0, y0, x1, y1 float64) *Path {
 		p := &Path{}
 		for y := math.Floor(y0); y <= y1; y += 1.0 {
 			p.MoveTo(x0, y)
@@ -163,13 +188,15 @@ func NewCrossHatch(ifill any, angle0, angle1, distance0, distance1, thickness fl
 		}
 		return p
 	})
+	pattern.kind = crossHatch
+	return pattern
 }
 
 // NewShapeHatch returns a new shape hatch that repeats the given shape over a rhombus primitive cell with sides of length distance. Thickness is the stroke thickness applied to the shape; stroking is ignored with thickness is zero.
 func NewShapeHatch(ifill any, shape *Path, distance, thickness float64) *HatchPattern {
 	d := distance * math.Sin(60.0*math.Pi/180.0)
 	cell := SquareCell(1.0)
-	return NewHatchPattern(ifill, thickness, cell, func(x0, y0, x1, y1 float64) *Path {
+	pattern := NewHatchPattern(ifill, thickness, cell, func(x0, y0, x1, y1 float64) *Path {
 		p := &Path{}
 		for y := math.Floor(y0/distance) * distance; y <= y1; y += 2.0 * d {
 			for x := math.Floor(x0/distance) * distance; x <= x1; x += distance {
@@ -181,6 +208,10 @@ func NewShapeHatch(ifill any, shape *Path, distance, thickness float64) *HatchPa
 		}
 		return p
 	})
+	pattern.kind = shapeHatch
+	pattern.shape = shape
+	pattern.distance = distance
+	return pattern
 }
 
 // ImagePattern is an image tiling pattern of an image drawn from an origin with a certain resolution. Higher resolution will give smaller tilings.
@@ -27,6 +27,8 @@ type PDF struct {
 	w             *pdfPageWriter
 	width, height float64
 	opts          *Options
+	link          string
+	resampling    canvas.ImageResampling
 }
 
 // New returns a portable document format (PDF) renderer.
@@ -66,7 +68,7 @@ func (r *PDF) SetLang(lang string) {
 	r.w.pdf.SetLang(lang)
 }
 
-// NewPage starts adds a new page where further rendering will be written to.
+// NewPage starts adds a new page where further rendering will be written to. The content of the current page is written and flushed to the output immediately, while fonts are written at Close and images and gradients are written once when first used, so that only references are kept in memory. Documents with many pages can thus be streamed by rendering each page directly to the PDF instead of recording all pages first.
 func (r *PDF) NewPage(width, height float64) {
 	r.w = r.w.pdf.NewPage(width, height)
 }
@@ -84,6 +86,29 @@ func (r *PDF) AddLink(uri string, rect canvas.Rect) {
 	r.w.AddLink(uri, rect)
 }
 
+// SetMetadata sets the metadata of the drawn objects that follow. Only the link is used, which adds a link annotation over the bounds of each drawn object, see AddLink.
+func (r *PDF) SetMetadata(meta canvas.Metadata) {
+	r.link = meta.Link
+}
+
+// SetImageResampling sets the interpolation of the images that follow. PDF only supports enabling or disabling interpolation by the viewer, which is disabled for canvas.NearestResampling and enabled otherwise.
+func (r *PDF) SetImageResampling(resampling canvas.ImageResampling) {
+	r.resampling = resampling
+}
+
+// addLink adds a link annotation over the given bounds if a link was set with SetMetadata, and returns a function that restores it. Nested drawing operations, such as text decorations, are not linked again.
+func (r *PDF) addLink(rect canvas.Rect) func() {
+	link := r.link
+	if link == "" || rect.Empty() {
+		return func() {}
+	}
+	r.w.AddLink(link, rect)
+	r.link = ""
+	return func() {
+		r.link = link
+	}
+}
+
 // AddOutline adds an outline element at the given y position. The top-level element must have level zero. If any level is missing, then
 // higher level elements are ignored.
 func (r *PDF) AddOutline(name string, level int, y float64) {
@@ -102,6 +127,14 @@ func (r *PDF) Size() (float64, float64) {
 
 // RenderPath renders a path to the canvas using a style and a transformation matrix.
 func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	if r.link != "" {
+		bounds := path.Copy().Transform(m).Bounds()
+		if style.HasStroke() {
+			bounds = bounds.Expand(style.StrokeWidth * math.Sqrt(math.Abs(m.Det())) / 2.0)
+		}
+		defer r.addLink(bounds)()
+	}
+
 	// PDFs don't support the arcs joiner, miter joiner (not clipped), or miter joiner (clipped) with non-bevel fallback
 	strokeUnsupported := false
 	if _, ok := style.StrokeJoiner.(canvas.ArcsJoiner); ok {
@@ -227,6 +260,10 @@ func (r *PDF) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 
 // RenderText renders a text object to the canvas using a transformation matrix.
 func (r *PDF) RenderText(text *canvas.Text, m canvas.Matrix) {
+	if r.link != "" {
+		defer r.addLink(text.Bounds().Transform(m))()
+	}
+
 	text.RenderDecorationsTo(r, m, 0.0)
 
 	text.WalkSpans(func(x, y float64, span canvas.TextSpan) {
@@ -258,5 +295,9 @@ func (r *PDF) RenderText(text *canvas.Text, m canvas.Matrix) {
 
 // RenderImage renders an image to the canvas using a transformation matrix.
 func (r *PDF) RenderImage(img image.Image, m canvas.Matrix) {
-	r.w.DrawImage(img, r.opts.ImageEncoding, m)
+	if r.link != "" {
+		size := img.Bounds().Size()
+		defer r.addLink(canvas.Rect{X1: float64(size.X), Y1: float64(size.Y)}.Transform(m))()
+	}
+	r.w.DrawImage(img, r.opts.ImageEncoding, r.resampling != canvas.NearestResampling, m)
 }
@@ -117,10 +117,30 @@ func TestPDFImage(t *testing.T) {
 
 	buf := &bytes.Buffer{}
 	pdf := newPDFWriter(buf).NewPage(210.0, 297.0)
-	pdf.DrawImage(img, cimage.Lossless, canvas.Identity)
+	pdf.DrawImage(img, cimage.Lossless, true, canvas.Identity)
 	test.String(t, pdf.String(), " 2.8346457 0 0 2.8346457 0 0 cm q 0 0 2 2 re W n 0 0 m 0 2 l 2 2 l 2 0 l h W n 2 0 0 2 0 0 cm /Im0 Do Q")
 }
 
+func TestPDFImageResampling(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	c := canvas.New(10.0, 10.0)
+	ctx := canvas.NewContext(c)
+	ctx.DrawImage(0.0, 0.0, img, canvas.DPMM(1.0))
+	ctx.SetImageResampling(canvas.NearestResampling)
+	ctx.DrawImage(2.0, 0.0, img, canvas.DPMM(1.0))
+	ctx.DrawImage(4.0, 0.0, img, canvas.DPMM(1.0))
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 10.0, 10.0, &Options{Compress: false})
+	c.RenderTo(pdf)
+	test.Error(t, pdf.Close())
+
+	// images with different interpolation are not deduplicated, and the mask has the same interpolation
+	out := buf.String()
+	test.T(t, strings.Count(out, "/Interpolate true"), 2)
+	test.T(t, strings.Count(out, "/Interpolate false"), 2)
+}
+
 func TestPDFMultipage(t *testing.T) {
 	buf := &bytes.Buffer{}
 	pdf := New(buf, 210, 297, nil)
@@ -135,6 +155,35 @@ func TestPDFMultipage(t *testing.T) {
 	test.That(t, nbPages == 2, "expected 2 pages, got", nbPages)
 }
 
+func TestPDFStreaming(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	grad := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 10.0, Y: 0.0})
+	grad.Add(0.0, canvas.Red)
+	grad.Add(1.0, canvas.Blue)
+	style := canvas.DefaultStyle
+	style.Fill = canvas.Paint{Gradient: grad}
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 210, 297, nil)
+	for i := 0; i < 3; i++ {
+		if i != 0 {
+			n := buf.Len()
+			pdf.NewPage(210, 297)
+			test.That(t, n < buf.Len(), "page must be written on NewPage")
+			test.T(t, strings.Count(buf.String(), "/Type/Page/"), i)
+		}
+		pdf.RenderImage(img, canvas.Identity)
+		pdf.RenderImage(image.NewNRGBA(image.Rect(0, 0, 2, 2)), canvas.Identity) // same content
+		pdf.RenderPath(canvas.Rectangle(10.0, 10.0), style, canvas.Identity)
+	}
+	test.Error(t, pdf.Close())
+
+	out := buf.String()
+	test.T(t, strings.Count(out, "/Type/Page/"), 3)
+	test.T(t, strings.Count(out, "/Subtype/Image"), 2) // image and its mask
+	test.T(t, strings.Count(out, "/ShadingType 2"), 1)
+}
+
 func TestPDFMetadata(t *testing.T) {
 	buf := &bytes.Buffer{}
 	pdf := New(buf, 210, 297, nil)
@@ -150,3 +199,41 @@ func TestPDFMetadata(t *testing.T) {
 	test.That(t, strings.Contains(out, "/Author(d4)"), `could not find "/Author (d4)" in output`)
 	test.That(t, strings.Contains(out, "/Creator(e5)"), `could not find "/Creator (e5)" in output`)
 }
+
+func TestPDFLinkMetadata(t *testing.T) {
+	c := canvas.New(100.0, 100.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetMetadata(canvas.Metadata{ID: "rect", Link: "https://example.com/"})
+	ctx.DrawPath(10.0, 20.0, canvas.Rectangle(30.0, 40.0))
+	ctx.SetMetadata(canvas.Metadata{})
+	ctx.DrawPath(50.0, 50.0, canvas.Rectangle(10.0, 10.0))
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 100.0, 100.0, nil)
+	c.RenderTo(pdf)
+	test.T(t, len(pdf.w.annots), 1)
+	annot := pdf.w.annots[0].(pdfDict)
+	rect := annot["Rect"].(pdfArray)
+	for i, v := range []float64{10.0, 20.0, 40.0, 60.0} {
+		test.Float(t, rect[i].(float64), v*ptPerMm)
+	}
+	test.T(t, annot["Contents"], "https://example.com/")
+	test.Error(t, pdf.Close())
+	test.That(t, strings.Contains(buf.String(), "/Subtype/Link"), `could not find "/Subtype /Link" in output`)
+}
+
+func TestPDFLinkMetadataImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+
+	c := canvas.New(100.0, 100.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetMetadata(canvas.Metadata{Link: "https://example.com/"})
+	ctx.DrawImage(10.0, 20.0, img, 1.0)
+	ctx.DrawPath(50.0, 50.0, canvas.Rectangle(10.0, 10.0))
+
+	buf := &bytes.Buffer{}
+	pdf := New(buf, 100.0, 100.0, nil)
+	c.RenderTo(pdf)
+	test.T(t, len(pdf.w.annots), 2)
+	test.Error(t, pdf.Close())
+}
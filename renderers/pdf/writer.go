@@ -3,6 +3,7 @@ package pdf
 import (
 	"bytes"
 	"compress/zlib"
+	"crypto/sha256"
 	"encoding/ascii85"
 	"fmt"
 	"image"
@@ -58,7 +59,8 @@ type pdfWriter struct {
 	fontsH     map[*canvas.Font]pdfRef
 	fontsV     map[*canvas.Font]pdfRef
 	fontsStd   map[*canvas.Font]pdfRef
-	images     map[image.Image]pdfRef
+	images     map[[sha256.Size]byte]pdfRef // by content, so that images are not retained
+	shadings   map[string]pdfRef
 	anchors    []pdfAnchor
 	outlines   []pdfOutline
 	compress   bool
@@ -79,7 +81,8 @@ func newPDFWriter(writer io.Writer) *pdfWriter {
 		fontsH:     map[*canvas.Font]pdfRef{},
 		fontsV:     map[*canvas.Font]pdfRef{},
 		fontsStd:   map[*canvas.Font]pdfRef{},
-		images:     map[image.Image]pdfRef{},
+		images:     map[[sha256.Size]byte]pdfRef{},
+		shadings:   map[string]pdfRef{},
 		compress:   true,
 		subset:     true,
 	}
@@ -137,6 +140,13 @@ func (w *pdfWriter) writeBytes(b []byte) {
 	w.err = err
 }
 
+// flush flushes the output if it is buffered, such as for bufio.Writer.
+func (w *pdfWriter) flush() {
+	if flusher, ok := w.w.(interface{ Flush() error }); ok && w.err == nil {
+		w.err = flusher.Flush()
+	}
+}
+
 func (w *pdfWriter) write(s string, v ...interface{}) {
 	if w.err != nil {
 		return
@@ -852,10 +862,11 @@ type pdfPageWriter struct {
 	textRenderMode int
 }
 
-// NewPage starts a new page.
+// NewPage writes the current page to the output and starts a new page. Only the references to the written pages and shared resources are retained.
 func (w *pdfWriter) NewPage(width, height float64) *pdfPageWriter {
 	if w.page != nil {
 		w.pages = append(w.pages, w.page.writePage(pdfRef(3)))
+		w.flush()
 	}
 
 	// for defaults see https://help.adobe.com/pdfl_sdk/15/PDFL_SDK_HTMLHelp/PDFL_SDK_HTMLHelp/API_References/PDFL_API_Reference/PDFEdit_Layer/General.html#_t_PDEGraphicState
@@ -1336,7 +1347,7 @@ func (w *pdfPageWriter) WriteText(mode canvas.WritingMode, TJ ...interface{}) {
 }
 
 // DrawImage embeds and draws an image.
-func (w *pdfPageWriter) DrawImage(img image.Image, enc cimage.ImageEncoding, m canvas.Matrix) {
+func (w *pdfPageWriter) DrawImage(img image.Image, enc cimage.ImageEncoding, interpolate bool, m canvas.Matrix) {
 	size := img.Bounds().Size()
 
 	// add clipping path around image for smooth edges when rotating
@@ -1348,7 +1359,7 @@ func (w *pdfPageWriter) DrawImage(img image.Image, enc cimage.ImageEncoding, m c
 	fmt.Fprintf(w, " q %v %v %v %v re W n", dec(outerRect.X0), dec(outerRect.Y0), dec(outerRect.W()), dec(outerRect.H()))
 	fmt.Fprintf(w, " %v %v m %v %v l %v %v l %v %v l h W n", dec(bl.X), dec(bl.Y), dec(tl.X), dec(tl.Y), dec(tr.X), dec(tr.Y), dec(br.X), dec(br.Y))
 
-	ref := w.embedImage(img, enc)
+	ref := w.embedImage(img, enc, interpolate)
 	if _, ok := w.resources["XObject"]; !ok {
 		w.resources["XObject"] = pdfDict{}
 	}
@@ -1360,11 +1371,7 @@ func (w *pdfPageWriter) DrawImage(img image.Image, enc cimage.ImageEncoding, m c
 	fmt.Fprintf(w, " %v %v %v %v %v %v cm /%v Do Q", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(m[0][2]), dec(m[1][2]), name)
 }
 
-func (w *pdfPageWriter) embedImage(img image.Image, enc cimage.ImageEncoding) pdfRef {
-	//if ref, ok := w.pdf.images[img]; ok {
-	//	return ref
-	//}
-
+func (w *pdfPageWriter) embedImage(img image.Image, enc cimage.ImageEncoding, interpolate bool) pdfRef {
 	var stream []byte
 	var streamMask []byte
 
@@ -1505,6 +1512,17 @@ func (w *pdfPageWriter) embedImage(img image.Image, enc cimage.ImageEncoding) pd
 		}
 	}
 
+	// deduplicate images by their content, which may be shared between pages
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%dx%d %v %v %v %d:", size.X, size.Y, interpolate, filters, filtersMask, len(stream))
+	hash.Write(stream)
+	hash.Write(streamMask)
+	var key [sha256.Size]byte
+	hash.Sum(key[:0])
+	if ref, ok := w.pdf.images[key]; ok {
+		return ref
+	}
+
 	dict := pdfDict{
 		"Type":             pdfName("XObject"),
 		"Subtype":          pdfName("Image"),
@@ -1512,7 +1530,7 @@ func (w *pdfPageWriter) embedImage(img image.Image, enc cimage.ImageEncoding) pd
 		"Height":           size.Y,
 		"ColorSpace":       pdfName("DeviceRGB"),
 		"BitsPerComponent": 8,
-		"Interpolate":      true,
+		"Interpolate":      interpolate,
 		"Filter":           filters,
 	}
 
@@ -1525,7 +1543,7 @@ func (w *pdfPageWriter) embedImage(img image.Image, enc cimage.ImageEncoding) pd
 				"Height":           size.Y,
 				"ColorSpace":       pdfName("DeviceGray"),
 				"BitsPerComponent": 8,
-				"Interpolate":      true,
+				"Interpolate":      interpolate,
 				"Filter":           filtersMask,
 			},
 			stream: streamMask,
@@ -1536,7 +1554,7 @@ func (w *pdfPageWriter) embedImage(img image.Image, enc cimage.ImageEncoding) pd
 		dict:   dict,
 		stream: stream,
 	})
-	//w.pdf.images[img] = ref
+	w.pdf.images[key] = ref
 	return ref
 }
 
@@ -1573,9 +1591,17 @@ func (w *pdfPageWriter) getPattern(gradient canvas.Gradient, m canvas.Matrix) pd
 		shading["Function"] = patternGradFunction(g.Grad)
 		shading["Extend"] = pdfArray{true, true}
 	}
+
+	// shadings are shared between pages, only the pattern matrix is specific to the page
+	key := fmt.Sprint(shading)
+	ref, ok := w.pdf.shadings[key]
+	if !ok {
+		ref = w.pdf.writeObject(shading)
+		w.pdf.shadings[key] = ref
+	}
 	pattern := pdfDict{
 		"PatternType": 2,
-		"Shading":     shading,
+		"Shading":     ref,
 		"Matrix":      pdfArray{m[0][0], m[1][0], m[0][1], m[1][1], m[0][2] * ptPerMm, m[1][2] * ptPerMm},
 	}
 
@@ -3,6 +3,7 @@ package ps
 import (
 	"compress/zlib"
 	"encoding/ascii85"
+	"errors"
 	"fmt"
 	"image"
 	"io"
@@ -26,6 +27,7 @@ const (
 
 type Options struct {
 	Format
+	PageLabel string // label of the first page in the document structuring comments, or the page number if empty, see NewPage
 }
 
 var DefaultOptions = Options{}
@@ -35,6 +37,8 @@ type PS struct {
 	w             io.Writer
 	width, height float64
 	opts          *Options
+	pages         int
+	err           error
 
 	paint      canvas.Paint
 	lineWidth  float64
@@ -43,6 +47,8 @@ type PS struct {
 	lineJoin   canvas.Joiner
 	dashOffset float64
 	dashes     []float64
+
+	resampling canvas.ImageResampling
 }
 
 // New returns an PostScript renderer.
@@ -60,28 +66,72 @@ func New(w io.Writer, width, height float64, opts *Options) *PS {
 	fmt.Fprintf(w, "%%%%Creator: tdewolff/canvas\n")
 	fmt.Fprintf(w, "%%%%CreationDate: %v\n", time.Now().Format(time.ANSIC))
 	fmt.Fprintf(w, "%%%%BoundingBox: 0 0 %v %v\n", dec(width), dec(height))
-
-	if opts.Format == EncapsulatedPostScript {
-		fmt.Fprintf(w, "%%%%EndComments\n")
-		// TODO: (EPS) generate and add preview
+	if opts.Format == PostScript {
+		fmt.Fprintf(w, "%%%%Pages: (atend)\n")
 	}
+	fmt.Fprintf(w, "%%%%EndComments\n")
+	// TODO: (EPS) generate and add preview
 
 	fmt.Fprint(w, psEllipseDef)
+	if opts.Format == PostScript {
+		label := opts.PageLabel
+		if label == "" {
+			label = "1"
+		}
+		fmt.Fprintf(w, "\n%%%%Page: (%s) 1\n", escapeString(label))
+	}
 
 	return &PS{
 		w:          w,
 		width:      width,
 		height:     height,
 		opts:       opts,
+		pages:      1,
 		miterLimit: 10.0,
 	}
 }
 
-func (r *PS) Close() error {
+// NewPage ends the current page and starts a new page where further rendering will be written to. The label is the page's name in the document structuring comments, or the page number if empty. Only PostScript supports multiple pages, and not Encapsulated PostScript for which the page is not added and Close returns an error. The output is flushed if it is buffered, such as for bufio.Writer, so that pages are streamed. Since PostScript is executed sequentially, resources cannot be written at Close: fonts are rendered as paths and images are written inline.
+func (r *PS) NewPage(width, height float64, label string) {
 	if r.opts.Format == EncapsulatedPostScript {
-		fmt.Fprintf(r.w, "%%%%EOF")
+		if r.err == nil {
+			r.err = errors.New("Encapsulated PostScript does not support multiple pages")
+		}
+		return
+	}
+
+	r.pages++
+	if label == "" {
+		label = fmt.Sprintf("%d", r.pages)
+	}
+	fmt.Fprintf(r.w, "\nshowpage\n%%%%Page: (%s) %d\n", escapeString(label), r.pages)
+	fmt.Fprintf(r.w, "%%%%PageBoundingBox: 0 0 %v %v\n", dec(width), dec(height))
+	fmt.Fprintf(r.w, "<</PageSize [%v %v]>> setpagedevice", dec(width), dec(height))
+	r.width, r.height = width, height
+	if flusher, ok := r.w.(interface{ Flush() error }); ok && r.err == nil {
+		r.err = flusher.Flush()
+	}
+
+	// graphics state is reset by showpage, see New
+	r.paint = canvas.Paint{}
+	r.lineWidth = 0.0
+	r.miterLimit = 10.0
+	r.lineCap = nil
+	r.lineJoin = nil
+	r.dashOffset = 0.0
+	r.dashes = nil
+}
+
+// Close finishes the PostScript, ending the last page if multiple pages were written and writing the number of pages in the trailer. It returns an error if NewPage was called for Encapsulated PostScript, or if flushing the output on NewPage failed.
+func (r *PS) Close() error {
+	if 1 < r.pages {
+		fmt.Fprintf(r.w, "\nshowpage")
+	}
+	if r.opts.Format == PostScript {
+		fmt.Fprintf(r.w, "\n%%%%Trailer\n%%%%Pages: %d", r.pages)
 	}
-	return nil
+	fmt.Fprintf(r.w, "\n%%%%EOF\n")
+	return r.err
 }
 
 func (r *PS) setPaint(paint canvas.Paint) {
@@ -160,6 +210,11 @@ func (r *PS) setDashes(offset float64, dashes []float64) {
 	}
 }
 
+// SetImageResampling sets the interpolation of the images that follow. PostScript only supports enabling or disabling interpolation, which is disabled for canvas.NearestResampling and enabled otherwise.
+func (r *PS) SetImageResampling(resampling canvas.ImageResampling) {
+	r.resampling = resampling
+}
+
 // Size returns the size of the canvas in millimeters.
 func (r *PS) Size() (float64, float64) {
 	return r.width, r.height
@@ -266,7 +321,7 @@ func (r *PS) RenderImage(img image.Image, m canvas.Matrix) {
 	fmt.Fprintf(r.w, " gsave")
 	fmt.Fprintf(r.w, " /DeviceRGB setcolorspace")
 	fmt.Fprintf(r.w, " [%v %v %v %v %v %v] concat", dec(m[0][0]), dec(m[1][0]), dec(m[0][1]), dec(m[1][1]), dec(m[0][2]), dec(m[1][2]))
-	fmt.Fprintf(r.w, "<</ImageType 1 /BitsPerComponent 8 /Decode [0 1 0 1 0 1] /Interpolate true")
+	fmt.Fprintf(r.w, "<</ImageType 1 /BitsPerComponent 8 /Decode [0 1 0 1 0 1] /Interpolate %v", r.resampling != canvas.NearestResampling)
 	fmt.Fprintf(r.w, " /Width %d /Height %d", size.X, size.Y)
 	fmt.Fprintf(r.w, " /ImageMatrix [%d %d %d %d %d %d]", size.X, 0, 0, -size.Y, 0, size.Y)
 	fmt.Fprintf(r.w, " /DataSource currentfile /ASCII85Decode filter /FlateDecode filter>>image\n")
@@ -1,10 +1,14 @@
 package ps
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
 )
 
 func TestPS(t *testing.T) {
@@ -13,3 +17,52 @@ func TestPS(t *testing.T) {
 	ps.setPaint(canvas.Paint{Color: canvas.Red})
 	//test.String(t, string(w.Bytes()), "")
 }
+
+func TestPSNewPage(t *testing.T) {
+	w := &bytes.Buffer{}
+	ps := New(w, 100, 80, &Options{PageLabel: "first"})
+	ps.NewPage(50, 40, "a (b)")
+	ps.NewPage(60, 30, "")
+	test.Error(t, ps.Close())
+	test.T(t, ps.width, 60.0)
+
+	out := w.String()
+	test.That(t, strings.Contains(out, "%%Pages: (atend)\n%%EndComments\n"), out)
+	test.That(t, strings.Contains(out, "\n%%Page: (first) 1\n"), out)
+	test.That(t, strings.Contains(out, "\nshowpage\n%%Page: (a \\(b\\)) 2\n%%PageBoundingBox: 0 0 50 40\n<</PageSize [50 40]>> setpagedevice"), out)
+	test.That(t, strings.Contains(out, "%%Page: (3) 3\n"), out)
+	test.T(t, strings.Count(out, "showpage"), 3)
+	test.That(t, strings.HasSuffix(out, "\nshowpage\n%%Trailer\n%%Pages: 3\n%%EOF\n"), out)
+}
+
+func TestEPSNewPage(t *testing.T) {
+	w := &bytes.Buffer{}
+	ps := New(w, 100, 80, &Options{Format: EncapsulatedPostScript})
+	ps.NewPage(50, 40, "")
+	test.That(t, ps.Close() != nil, "Encapsulated PostScript must not support multiple pages")
+	test.T(t, ps.width, 100.0)
+	test.T(t, strings.Count(w.String(), "showpage"), 0)
+}
+
+func TestPSStreaming(t *testing.T) {
+	w := &bytes.Buffer{}
+	bw := bufio.NewWriterSize(w, 1<<16)
+	ps := New(bw, 100, 80, nil)
+	ps.RenderPath(canvas.Rectangle(10.0, 10.0), canvas.DefaultStyle, canvas.Identity)
+	test.T(t, w.Len(), 0)
+	ps.NewPage(100, 80, "")
+	test.That(t, strings.Contains(w.String(), "%%Page: (2) 2"), "page must be flushed on NewPage")
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(b []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestPSStreamingError(t *testing.T) {
+	bw := bufio.NewWriterSize(errorWriter{}, 1<<16)
+	ps := New(bw, 100, 80, nil)
+	ps.NewPage(100, 80, "")
+	test.T(t, ps.Close(), errors.New("write failed"))
+}
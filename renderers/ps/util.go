@@ -1,6 +1,9 @@
 package ps
 
-import "image/color"
+import (
+	"image/color"
+	"strings"
+)
 
 func float64sEqual(a, b []float64) bool {
 	if len(a) != len(b) {
@@ -24,3 +27,8 @@ func toNRGBA(col color.Color) color.NRGBA {
 	b = (b * 0xffff) / a
 	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
 }
+
+// escapeString escapes the backslashes and parentheses of a PostScript string.
+func escapeString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
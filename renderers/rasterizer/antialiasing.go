@@ -0,0 +1,101 @@
+package rasterizer
+
+import (
+	"image/color"
+
+	"golang.org/x/image/draw"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Antialiasing is the antialiasing mode of fills and strokes of the rasterizers, which also applies to text as it is rendered as paths. Its value is the number of point samples per pixel in each direction, see Scanner.SetSamples. Images are not affected, use canvas.NearestResampling for hard-edged images.
+type Antialiasing int
+
+// See Antialiasing.
+const (
+	GrayscaleAntialiasing Antialiasing = 0 // exact area coverage (default)
+	NoAntialiasing        Antialiasing = 1 // pixels are sampled at their center, without partial coverage
+)
+
+// Supersampling returns the antialiasing mode that samples each pixel at n×n points, so that there are n×n+1 levels of coverage.
+func Supersampling(n int) Antialiasing {
+	return Antialiasing(max(n, 1))
+}
+
+// SetAntialiasing sets the antialiasing mode of subsequent fills and strokes. For any mode other than GrayscaleAntialiasing, paths are rasterized by point sampling with a Scanner.
+func (r *Rasterizer) SetAntialiasing(antialiasing Antialiasing) {
+	r.antialiasing = max(antialiasing, 0)
+	if r.sampler != nil {
+		r.sampler.SetSamples(int(r.antialiasing))
+	}
+}
+
+// drawSampled draws a transformed outline with a color or gradient using point sampling, see SetAntialiasing.
+func (r *Rasterizer) drawSampled(path *canvas.Path, paint canvas.Paint, fillRule canvas.FillRule, m canvas.Matrix) {
+	size := r.Bounds().Size()
+	var colorAt func(x, y int) color.Color
+	if paint.IsGradient() {
+		mInv := m.Inv()
+		gradient := paint.Gradient.SetColorSpace(r.colorSpace)
+		colorAt = func(x, y int) color.Color {
+			p := canvas.Point{X: (float64(x) + 0.5) / float64(r.resolution), Y: (float64(size.Y-y) - 0.5) / float64(r.resolution)}
+			p = mInv.Dot(p)
+			return gradient.At(p.X, p.Y)
+		}
+	} else if paint.IsColor() {
+		c := r.colorSpace.ToLinear(paint.Color)
+		colorAt = func(int, int) color.Color {
+			return c
+		}
+	} else {
+		return
+	}
+
+	if r.sampler == nil {
+		r.sampler = NewScanner(size.X, size.Y)
+		r.sampler.SetSamples(int(r.antialiasing))
+	}
+	r.sampler.Reset()
+	r.sampler.AddPath(path, float64(size.Y), r.resolution)
+
+	origin := r.Bounds().Min
+	op := r.spanner.Op
+	r.sampler.Spans(fillRule, r.clip, func(y, x0, x1 int, alpha uint16) {
+		for x := x0; x < x1; x++ {
+			blendPixel(r.Image, origin.X+x, origin.Y+y, colorAt(x, y), uint32(alpha), op)
+		}
+	})
+}
+
+// blendPixel composites a premultiplied color with the given 16-bit coverage onto a pixel of the image using the drawing operation.
+func blendPixel(img draw.Image, x, y int, c color.Color, alpha uint32, op draw.Op) {
+	const m = 0xffff
+	sr, sg, sb, sa := c.RGBA()
+	sr = sr * alpha / m
+	sg = sg * alpha / m
+	sb = sb * alpha / m
+	sa = sa * alpha / m
+
+	a := m - sa // draw.Over
+	if op == draw.Src {
+		a = m - alpha
+	}
+	if a != 0 {
+		dr, dg, db, da := img.At(x, y).RGBA()
+		sr += dr * a / m
+		sg += dg * a / m
+		sb += db * a / m
+		sa += da * a / m
+	}
+	img.Set(x, y, color.RGBA64{uint16(sr), uint16(sg), uint16(sb), uint16(sa)})
+}
+
+// SetAntialiasing sets the antialiasing mode of subsequent fills and strokes, see Rasterizer.SetAntialiasing.
+func (r *TiledRasterizer) SetAntialiasing(antialiasing Antialiasing) {
+	r.antialiasing = max(antialiasing, 0)
+}
+
+// SetAntialiasing sets the antialiasing mode of subsequent fills and strokes, see Rasterizer.SetAntialiasing.
+func (r *ScanlineRasterizer) SetAntialiasing(antialiasing Antialiasing) {
+	r.scanner.SetSamples(int(max(antialiasing, 0)))
+}
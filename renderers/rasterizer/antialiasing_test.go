@@ -0,0 +1,146 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/draw"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestScannerSamples(t *testing.T) {
+	rectangle := func(s *Scanner) {
+		s.MoveTo(0.5, 0.5)
+		s.LineTo(2.5, 0.5)
+		s.LineTo(2.5, 1.5)
+		s.LineTo(0.5, 1.5)
+	}
+
+	var tts = []struct {
+		name     string
+		path     func(*Scanner)
+		samples  int
+		fillRule canvas.FillRule
+		expected [][]float64
+	}{
+		{"rectangle aliased", rectangle, 1, canvas.NonZero, [][]float64{
+			{1.0, 1.0, 0.0},
+			{0.0, 0.0, 0.0},
+		}},
+		{"rectangle 2x2", rectangle, 2, canvas.NonZero, [][]float64{
+			{0.25, 0.5, 0.25},
+			{0.25, 0.5, 0.25},
+		}},
+		{"triangle 4x4", func(s *Scanner) {
+			s.MoveTo(0.0, 0.0)
+			s.LineTo(2.0, 2.0)
+			s.LineTo(0.0, 2.0)
+		}, 4, canvas.NonZero, [][]float64{
+			{6.0 / 16.0, 0.0, 0.0},
+			{1.0, 6.0 / 16.0, 0.0},
+		}},
+		{"outside aliased", func(s *Scanner) {
+			s.MoveTo(-5.0, -1.0)
+			s.LineTo(1.5, -1.0)
+			s.LineTo(1.5, 1.0)
+			s.LineTo(-5.0, 1.0)
+		}, 1, canvas.NonZero, [][]float64{
+			{1.0, 0.0, 0.0},
+			{0.0, 0.0, 0.0},
+		}},
+		{"overlap evenodd aliased", overlappingSquares, 1, canvas.EvenOdd, [][]float64{
+			{1.0, 1.0, 0.0},
+			{1.0, 0.0, 1.0},
+		}},
+		{"overlap evenodd 2x2", overlappingSquares, 2, canvas.EvenOdd, [][]float64{
+			{1.0, 1.0, 0.0},
+			{1.0, 0.0, 1.0},
+		}},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(3, 2)
+			s.SetSamples(tt.samples)
+			tt.path(s)
+			s.Close()
+			grid := scannerCoverage(s, tt.fillRule)
+			for y := range tt.expected {
+				for x := range tt.expected[y] {
+					test.FloatDiff(t, grid[y][x], tt.expected[y][x], 1e-4)
+				}
+			}
+		})
+	}
+}
+
+func TestRasterizerAntialiasing(t *testing.T) {
+	family := canvas.NewFontFamily("dejavu-serif")
+	if err := family.LoadFontFile("../../resources/DejaVuSerif.ttf", canvas.FontRegular); err != nil {
+		test.Error(t, err)
+	}
+	face := family.Face(24.0, canvas.Black, canvas.FontRegular, canvas.FontNormal)
+
+	c := canvas.New(60, 40)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.White)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(60.0, 40.0))
+	ctx.SetFillColor(canvas.Black)
+	ctx.SetStrokeColor(canvas.Black)
+	ctx.SetStrokeWidth(0.7)
+	ctx.DrawPath(30.0, 20.0, canvas.Circle(12.0))
+	ctx.DrawText(8.0, 30.0, canvas.NewTextLine(face, "Pixels", canvas.Left))
+
+	levels := func(img *image.RGBA) map[uint8]bool {
+		levels := map[uint8]bool{}
+		for i := 0; i < len(img.Pix); i += 4 {
+			levels[img.Pix[i]] = true
+		}
+		return levels
+	}
+
+	// aliased
+	ras := New(c.W, c.H, canvas.DPMM(4.0), nil)
+	ras.SetAntialiasing(NoAntialiasing)
+	c.RenderTo(ras)
+	ras.Close()
+	serial := ras.Image.(*image.RGBA)
+	test.T(t, levels(serial), map[uint8]bool{0: true, 255: true})
+
+	tiled := NewTiled(c.W, c.H, canvas.DPMM(4.0), nil)
+	tiled.TileSize = 17
+	tiled.SetAntialiasing(NoAntialiasing)
+	c.RenderTo(tiled)
+	tiled.Close()
+	test.Bytes(t, tiled.Image.(*image.RGBA).Pix, serial.Pix)
+
+	scanline := NewScanline(c.W, c.H, canvas.DPMM(4.0), nil)
+	scanline.SetAntialiasing(NoAntialiasing)
+	c.RenderTo(scanline)
+	scanline.Close()
+	test.T(t, levels(scanline.Image.(*image.RGBA)), map[uint8]bool{0: true, 255: true})
+
+	// supersampled
+	c = canvas.New(60, 40)
+	ctx = canvas.NewContext(c)
+	ctx.SetFillColor(canvas.White)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(60.0, 40.0))
+	ctx.SetFillColor(canvas.Black)
+	ctx.DrawPath(30.0, 20.0, canvas.Circle(12.0))
+	ras = New(c.W, c.H, canvas.DPMM(4.0), canvas.LinearColorSpace{})
+	ras.SetAntialiasing(Supersampling(2))
+	c.RenderTo(ras)
+	ras.Close()
+	test.T(t, levels(ras.Image.(*image.RGBA)), map[uint8]bool{0: true, 64: true, 127: true, 191: true, 255: true})
+}
+
+func TestBlendPixel(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{0, 0, 255, 255})
+	blendPixel(img, 0, 0, color.RGBA{128, 0, 0, 128}, 0xffff, draw.Over)
+	test.T(t, img.RGBAAt(0, 0), color.RGBA{128, 0, 127, 255})
+	blendPixel(img, 0, 0, color.RGBA{128, 0, 0, 128}, 0xffff, draw.Src)
+	test.T(t, img.RGBAAt(0, 0), color.RGBA{128, 0, 0, 128})
+}
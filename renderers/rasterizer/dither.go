@@ -0,0 +1,255 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// Dithering is the method used to reduce the colors of an image to a palette, see Dither.
+type Dithering int
+
+// See Dithering.
+const (
+	NoDithering             Dithering = iota // nearest palette color
+	BayerDithering                           // ordered dithering with an 8x8 Bayer matrix
+	FloydSteinbergDithering                  // error diffusion to four neighbours
+	AtkinsonDithering                        // error diffusion of 3/4 of the error to six neighbours, which keeps more contrast
+)
+
+func (dithering Dithering) String() string {
+	switch dithering {
+	case NoDithering:
+		return "None"
+	case BayerDithering:
+		return "Bayer"
+	case FloydSteinbergDithering:
+		return "FloydSteinberg"
+	case AtkinsonDithering:
+		return "Atkinson"
+	}
+	return "Unknown"
+}
+
+// Palettes for two- and three-color displays such as e-paper.
+var (
+	BlackWhite    = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}}
+	BlackWhiteRed = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, color.RGBA{255, 0, 0, 255}}
+)
+
+// bayer is the 8x8 Bayer threshold matrix.
+var bayer = [8][8]int{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// diffusion is an error diffusion kernel of neighbour offsets and weights, where the weights are divided by the divisor.
+type diffusion struct {
+	divisor int32
+	weights []struct{ dx, dy, w int32 }
+}
+
+var floydSteinberg = diffusion{16, []struct{ dx, dy, w int32 }{
+	{1, 0, 7}, {-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+}}
+
+var atkinson = diffusion{8, []struct{ dx, dy, w int32 }{
+	{1, 0, 1}, {2, 0, 1}, {-1, 1, 1}, {0, 1, 1}, {1, 1, 1}, {0, 2, 1},
+}}
+
+// Dither returns the image reduced to the colors of a palette using the dithering method, such as for GIF, paletted PNG, or 1-bit output. It can be used as a post-process on the output of Draw, and dithers in the color space of the image. If the palette is nil, a palette of 256 colors is quantized from the image, see Quantize. Colors are compared as premultiplied RGBA, like color.Palette.Index.
+func Dither(img image.Image, p color.Palette, dithering Dithering) *image.Paletted {
+	if p == nil {
+		p = Quantize(img, 256)
+	}
+	bounds := img.Bounds()
+	dst := image.NewPaletted(bounds, p)
+	if bounds.Empty() || len(p) == 0 {
+		return dst
+	}
+
+	// palette colors in 8 bits per channel
+	colors := make([][4]int32, len(p))
+	for i, c := range p {
+		r, g, b, a := c.RGBA()
+		colors[i] = [4]int32{int32(r >> 8), int32(g >> 8), int32(b >> 8), int32(a >> 8)}
+	}
+	nearest := func(c [4]int32) uint8 {
+		best, bestDist := 0, int32(math.MaxInt32)
+		for i, pc := range colors {
+			var dist int32
+			for k := range 4 {
+				d := c[k] - pc[k]
+				dist += d * d
+			}
+			if dist < bestDist {
+				best, bestDist = i, dist
+				if dist == 0 {
+					break
+				}
+			}
+		}
+		return uint8(best)
+	}
+
+	var kernel diffusion
+	switch dithering {
+	case FloydSteinbergDithering:
+		kernel = floydSteinberg
+	case AtkinsonDithering:
+		kernel = atkinson
+	}
+	spread := paletteSpread(colors)
+
+	// errors of the current and following two rows
+	w := bounds.Dx()
+	errs := [3][][4]int32{make([][4]int32, w+4), make([][4]int32, w+4), make([][4]int32, w+4)}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			c := [4]int32{int32(r >> 8), int32(g >> 8), int32(b >> 8), int32(a >> 8)}
+
+			i := x - bounds.Min.X
+			if dithering == BayerDithering {
+				// offset in [-1/2,1/2) of the distance between palette colors
+				t := int32(2*bayer[y&7][x&7]+1) - 64
+				for k := range 4 {
+					c[k] += t * spread[k] / 128
+				}
+			} else if kernel.divisor != 0 {
+				for k := range 4 {
+					c[k] += errs[0][i+2][k] / kernel.divisor
+				}
+			}
+			for k := range 4 {
+				c[k] = min(max(c[k], 0), 255)
+			}
+
+			index := nearest(c)
+			dst.Pix[dst.PixOffset(x, y)] = index
+			if kernel.divisor != 0 {
+				for _, n := range kernel.weights {
+					for k := range 4 {
+						errs[n.dy][i+2+int(n.dx)][k] += n.w * (c[k] - colors[index][k])
+					}
+				}
+			}
+		}
+		errs[0], errs[1], errs[2] = errs[1], errs[2], errs[0]
+		clear(errs[2])
+	}
+	return dst
+}
+
+// paletteSpread returns the average distance between successive distinct values of each channel of the palette colors, which is the spread of ordered dithering.
+func paletteSpread(colors [][4]int32) [4]int32 {
+	var spread [4]int32
+	for k := range 4 {
+		values := []int32{}
+		for _, c := range colors {
+			values = append(values, c[k])
+		}
+		slices.Sort(values)
+		values = slices.Compact(values)
+		if 1 < len(values) {
+			spread[k] = (values[len(values)-1] - values[0]) / int32(len(values)-1)
+		}
+	}
+	return spread
+}
+
+// Quantize returns a palette of at most n colors that represents the colors of the image using the median cut algorithm. Colors are compared as premultiplied RGBA. Images with at most n distinct colors keep their exact colors.
+func Quantize(img image.Image, n int) color.Palette {
+	// histogram of colors
+	hist := map[color.RGBA]int{}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			hist[color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}]++
+		}
+	}
+	type entry struct {
+		c     [4]int32
+		count int
+	}
+	entries := make([]entry, 0, len(hist))
+	for c, count := range hist {
+		entries = append(entries, entry{[4]int32{int32(c.R), int32(c.G), int32(c.B), int32(c.A)}, count})
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		for k := range 4 {
+			if a.c[k] != b.c[k] {
+				return int(a.c[k] - b.c[k])
+			}
+		}
+		return 0
+	})
+	if n <= 0 || len(entries) == 0 {
+		return color.Palette{}
+	}
+
+	// split the box with the widest channel range at the median of that channel, until there are n boxes
+	boxes := [][]entry{entries}
+	for len(boxes) < n {
+		split, channel, widest := -1, 0, int32(0)
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			for k := range 4 {
+				lo, hi := box[0].c[k], box[0].c[k]
+				for _, e := range box[1:] {
+					lo, hi = min(lo, e.c[k]), max(hi, e.c[k])
+				}
+				if widest < hi-lo {
+					split, channel, widest = i, k, hi-lo
+				}
+			}
+		}
+		if split == -1 {
+			break
+		}
+
+		box := boxes[split]
+		slices.SortStableFunc(box, func(a, b entry) int {
+			return int(a.c[channel] - b.c[channel])
+		})
+		total := 0
+		for _, e := range box {
+			total += e.count
+		}
+		median, count := 1, box[0].count
+		for ; median < len(box)-1 && 2*count < total; median++ {
+			count += box[median].count
+		}
+		boxes[split] = box[:median:median]
+		boxes = append(boxes, box[median:])
+	}
+
+	// average color of each box weighted by count
+	p := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		var sum [4]int
+		total := 0
+		for _, e := range box {
+			for k := range 4 {
+				sum[k] += int(e.c[k]) * e.count
+			}
+			total += e.count
+		}
+		var c [4]uint8
+		for k := range 4 {
+			c[k] = uint8((sum[k] + total/2) / total)
+		}
+		p = append(p, color.RGBA{c[0], c[1], c[2], c[3]})
+	}
+	return p
+}
@@ -0,0 +1,76 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestDither(t *testing.T) {
+	gray := image.NewUniform(color.Gray{128})
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.Set(x, y, gray.C)
+		}
+	}
+
+	white := func(dst *image.Paletted) int {
+		n := 0
+		for _, index := range dst.Pix {
+			if index == 1 {
+				n++
+			}
+		}
+		return n
+	}
+
+	dst := Dither(img, BlackWhite, NoDithering)
+	test.T(t, white(dst), 32*32)
+
+	// ordered dithering of 50% gray alternates half of the pixels
+	dst = Dither(img, BlackWhite, BayerDithering)
+	test.T(t, white(dst), 32*32/2)
+	test.T(t, dst.ColorIndexAt(0, 0), uint8(0))
+	test.T(t, dst.ColorIndexAt(1, 0), uint8(1))
+
+	for _, dithering := range []Dithering{FloydSteinbergDithering, AtkinsonDithering} {
+		t.Run(dithering.String(), func(t *testing.T) {
+			dst := Dither(img, BlackWhite, dithering)
+			test.FloatDiff(t, float64(white(dst))/(32*32), 0.5, 0.05)
+		})
+	}
+
+	// three colors
+	img.Set(5, 5, color.RGBA{250, 10, 0, 255})
+	dst = Dither(img, BlackWhiteRed, FloydSteinbergDithering)
+	test.T(t, dst.ColorIndexAt(5, 5), uint8(2))
+	test.T(t, dst.Palette, BlackWhiteRed)
+}
+
+func TestQuantize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.RGBA{0, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{20, 20, 20, 255})
+	img.Set(2, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(3, 0, color.RGBA{255, 0, 0, 255})
+
+	// colors are kept exactly
+	p := Quantize(img, 256)
+	test.T(t, len(p), 3)
+	for x := 0; x < 4; x++ {
+		test.T(t, p[p.Index(img.At(x, 0))], img.At(x, 0))
+	}
+
+	// close colors are merged
+	p = Quantize(img, 2)
+	test.T(t, len(p), 2)
+	test.T(t, p.Index(img.At(0, 0)), p.Index(img.At(1, 0)))
+	test.T(t, p[p.Index(img.At(2, 0))], color.Color(color.RGBA{255, 0, 0, 255}))
+
+	dst := Dither(img, nil, NoDithering)
+	test.T(t, len(dst.Palette), 3)
+	test.T(t, dst.At(2, 0), img.At(2, 0))
+}
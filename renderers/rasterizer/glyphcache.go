@@ -0,0 +1,191 @@
+package rasterizer
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"golang.org/x/image/draw"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/canvas/text"
+	"github.com/tdewolff/font"
+)
+
+// GlyphSubpixels is the number of subpixel positions per pixel in each direction at which glyph masks are cached.
+const GlyphSubpixels = 4
+
+// glyphKey identifies a rasterized glyph mask. The scale is in pixels per millimeter of the font face in each direction, ppem is the pixels per EM used for hinting or zero, and the offset is the subpixel position of the glyph origin.
+type glyphKey struct {
+	font                 *canvas.Font
+	id                   uint16
+	ppem                 float64
+	sx, sy               float64
+	fauxBold, fauxItalic float64
+	dx, dy               uint8
+}
+
+// GlyphCache is a cache of glyph coverage masks, which is used by the rasterizer to render text by compositing the masks instead of rasterizing the glyph outlines every time. It can be shared between rasterizers and is safe for concurrent use. Glyph origins are positioned at a fraction of 1/GlyphSubpixels of a pixel.
+type GlyphCache struct {
+	mu    sync.Mutex
+	size  int
+	masks map[glyphKey]*image.Alpha
+}
+
+// NewGlyphCache returns a glyph cache that holds at most size masks. When the cache is full it is cleared. If size is zero or negative, the cache is unbounded.
+func NewGlyphCache(size int) *GlyphCache {
+	return &GlyphCache{
+		size:  size,
+		masks: map[glyphKey]*image.Alpha{},
+	}
+}
+
+// Len returns the number of cached glyph masks.
+func (cache *GlyphCache) Len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return len(cache.masks)
+}
+
+// mask returns the coverage mask of a glyph for the font face at the given scale (px/mm) and subpixel offset, which is hinted if ppem is non-zero. The bounds of the mask are in pixels relative to the whole pixel position of the glyph origin, with the Y-axis pointing down.
+func (cache *GlyphCache) mask(face *canvas.FontFace, id uint16, ppem, sx, sy float64, dx, dy uint8) *image.Alpha {
+	key := glyphKey{face.Font, id, ppem, face.MmPerEm * sx, face.MmPerEm * sy, face.FauxBold * face.Size, face.FauxItalic, dx, dy}
+	cache.mu.Lock()
+	mask, ok := cache.masks[key]
+	cache.mu.Unlock()
+	if ok {
+		return mask
+	}
+
+	mask = rasterizeGlyph(face.GlyphToPath(id, ppem), sx, sy, float64(dx)/GlyphSubpixels, float64(dy)/GlyphSubpixels)
+
+	cache.mu.Lock()
+	if 0 < cache.size && cache.size <= len(cache.masks) {
+		clear(cache.masks)
+	}
+	cache.masks[key] = mask
+	cache.mu.Unlock()
+	return mask
+}
+
+// rasterizeGlyph returns the coverage mask of a glyph path in millimeters, scaled by sx and sy to pixels and with its origin at (dx,dy) in pixels where the Y-axis points down.
+func rasterizeGlyph(p *canvas.Path, sx, sy, dx, dy float64) *image.Alpha {
+	// glyph in pixels with the Y-axis pointing up
+	p = p.Transform(canvas.Identity.Translate(dx, -dy).Scale(sx, sy))
+	bounds := p.FastBounds()
+	rect := image.Rect(int(math.Floor(bounds.X0)), int(math.Floor(-bounds.Y1)), int(math.Ceil(bounds.X1)), int(math.Ceil(-bounds.Y0)))
+	mask := image.NewAlpha(rect)
+	if rect.Empty() {
+		return mask
+	}
+
+	s := NewScanner(rect.Dx(), rect.Dy())
+	s.AddPath(p.Translate(-float64(rect.Min.X), 0.0), -float64(rect.Min.Y), canvas.DPMM(1.0))
+	s.Spans(canvas.NonZero, image.Rect(0, 0, rect.Dx(), rect.Dy()), func(y, x0, x1 int, alpha uint16) {
+		a := uint8(alpha >> 8)
+		i := mask.PixOffset(rect.Min.X+x0, rect.Min.Y+y)
+		for x := x0; x < x1; x++ {
+			mask.Pix[i] = a
+			i++
+		}
+	})
+	return mask
+}
+
+// SetGlyphCache sets the glyph cache used for rendering text, or disables it when nil (default). The cache is used for text spans that are not rotated and have a color fill, when the transformation is only a translation and a positive scaling, and when using GrayscaleAntialiasing. Other text is rendered as paths.
+func (r *Rasterizer) SetGlyphCache(cache *GlyphCache) {
+	r.glyphs = cache
+}
+
+// cachedText returns true if the text can be rendered from the glyph cache, see SetGlyphCache.
+func (r *Rasterizer) cachedText(t *canvas.Text, m canvas.Matrix) bool {
+	if r.glyphs == nil || r.antialiasing != GrayscaleAntialiasing || m.HasRotation() || m[0][0] <= 0.0 || m[1][1] <= 0.0 {
+		return false
+	}
+	ok := true
+	t.WalkSpans(func(_, _ float64, span canvas.TextSpan) {
+		if span.IsText() && (span.Rotation != text.NoRotation || !span.Face.Fill.IsColor()) {
+			ok = false
+		}
+	})
+	return ok
+}
+
+// renderCachedText renders text by compositing glyph masks from the glyph cache.
+func (r *Rasterizer) renderCachedText(t *canvas.Text, m canvas.Matrix) {
+	t.RenderDecorationsTo(r, m, r.resolution)
+
+	dpmm := r.resolution.DPMM()
+	sx, sy := m[0][0]*dpmm, m[1][1]*dpmm
+	bounds := r.Bounds()
+	clip := r.clip.Add(bounds.Min)
+	t.WalkSpans(func(x, y float64, span canvas.TextSpan) {
+		if !span.IsText() {
+			for _, obj := range span.Objects {
+				obj.Canvas.RenderViewTo(r, m.Mul(obj.View(x, y, span.Face)))
+			}
+			return
+		}
+
+		face := span.Face
+		ppem := 0.0
+		if face.Hinting != font.NoHinting && t.WritingMode == canvas.HorizontalTB {
+			// grid-fit glyphs vertically, see Text.RenderHintedTo
+			ppem = face.MmPerEm * float64(face.Font.Head.UnitsPerEm) * sy
+		}
+		c := r.Image.ColorModel().Convert(r.colorSpace.ToLinear(face.Fill.Color))
+		src := image.NewUniform(c)
+
+		// span origin in pixels with the Y-axis pointing down
+		origin := m.Dot(canvas.Point{X: x, Y: y}).Mul(dpmm)
+		origin.Y = float64(bounds.Dy()) - origin.Y
+		if face.Hinting != font.NoHinting {
+			// grid-align vertically on pixel raster, see Text.RenderTo
+			origin.Y = math.Floor(origin.Y + 0.5)
+		}
+
+		var gx, gy int32
+		for _, glyph := range span.Glyphs {
+			px := face.MmPerEm * float64(gx+glyph.XOffset)
+			py := face.MmPerEm * float64(gy+glyph.YOffset)
+			px += face.FauxItalic * py
+			gx += glyph.XAdvance
+			gy += glyph.YAdvance
+
+			// split glyph origin into whole pixels and subpixels
+			qx := int(math.Floor((origin.X+px*sx)*GlyphSubpixels + 0.5))
+			qy := int(math.Floor((origin.Y-py*sy)*GlyphSubpixels + 0.5))
+			ix, dx := floorDiv(qx, GlyphSubpixels)
+			iy, dy := floorDiv(qy, GlyphSubpixels)
+
+			mask := r.glyphs.mask(face, glyph.ID, ppem, sx, sy, uint8(dx), uint8(dy))
+			offset := image.Point{bounds.Min.X + ix, bounds.Min.Y + iy}
+			rect := mask.Rect.Add(offset).Intersect(clip)
+			if rect.Empty() {
+				continue
+			} else if r.spanner.Op == draw.Over {
+				draw.DrawMask(r.Image, rect, src, image.Point{}, mask, rect.Min.Sub(offset), draw.Over)
+				continue
+			}
+
+			// draw.Src replaces covered pixels only, like path drawing
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				for x := rect.Min.X; x < rect.Max.X; x++ {
+					if a := mask.AlphaAt(x-offset.X, y-offset.Y).A; a != 0 {
+						blendPixel(r.Image, x, y, c, uint32(a)*0x101, draw.Src)
+					}
+				}
+			}
+		}
+	})
+}
+
+// floorDiv returns the quotient rounded down and the non-negative remainder of a/b.
+func floorDiv(a, b int) (int, int) {
+	q, m := a/b, a%b
+	if m < 0 {
+		q--
+		m += b
+	}
+	return q, m
+}
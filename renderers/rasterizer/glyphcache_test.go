@@ -0,0 +1,70 @@
+package rasterizer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestGlyphCache(t *testing.T) {
+	family := canvas.NewFontFamily("dejavu-serif")
+	if err := family.LoadFontFile("../../resources/DejaVuSerif.ttf", canvas.FontRegular); err != nil {
+		test.Error(t, err)
+	}
+	face := family.Face(12.0, canvas.Black, canvas.FontRegular, canvas.FontNormal)
+
+	c := canvas.New(60, 40)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.White)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(60.0, 40.0))
+	ctx.DrawText(4.0, 30.0, canvas.NewTextLine(face, "0.25 0.50", canvas.Left))
+	ctx.DrawText(4.3, 20.0, canvas.NewTextLine(face, "0.75 1.00", canvas.Left))
+	ctx.Scale(1.5, 1.0)
+	ctx.DrawText(2.0, 8.0, canvas.NewTextLine(face, "x", canvas.Left))
+
+	expected := Draw(c, canvas.DPMM(4.0), nil)
+
+	cache := NewGlyphCache(0)
+	ras := New(c.W, c.H, canvas.DPMM(4.0), nil)
+	ras.SetGlyphCache(cache)
+	c.RenderTo(ras)
+	ras.Close()
+	img := ras.Image.(*image.RGBA)
+
+	// glyphs differ at most by their subpixel position
+	diff := 0.0
+	for i := range img.Pix {
+		d := float64(img.Pix[i]) - float64(expected.Pix[i])
+		diff += d * d
+	}
+	test.FloatDiff(t, diff/float64(len(img.Pix)), 0.0, 5.0)
+
+	// repeated glyphs at equal subpixel offsets share masks: digits, period and space in the first two lines, 'x' scaled
+	n := cache.Len()
+	test.That(t, n <= 18, "too many masks:", n)
+
+	ras = New(c.W, c.H, canvas.DPMM(4.0), nil)
+	ras.SetGlyphCache(cache)
+	c.RenderTo(ras)
+	ras.Close()
+	test.T(t, cache.Len(), n)
+	test.Bytes(t, ras.Image.(*image.RGBA).Pix, img.Pix)
+
+	// bounded cache
+	cache = NewGlyphCache(2)
+	ras = New(c.W, c.H, canvas.DPMM(4.0), nil)
+	ras.SetGlyphCache(cache)
+	c.RenderTo(ras)
+	test.That(t, cache.Len() <= 2)
+}
+
+func TestFloorDiv(t *testing.T) {
+	q, m := floorDiv(-5, 4)
+	test.T(t, q, -2)
+	test.T(t, m, 3)
+	q, m = floorDiv(9, 4)
+	test.T(t, q, 2)
+	test.T(t, m, 1)
+}
@@ -0,0 +1,92 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// LinearImage is an image of premultiplied RGBA colors in linear light with floating-point precision, suitable for high dynamic range output and further processing. Color values are not clamped, but At returns colors clamped to [0,1].
+type LinearImage struct {
+	// Pix holds the image's pixels in R, G, B, A order, where the pixel at (x,y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*4].
+	Pix    []float32
+	Stride int
+	Rect   image.Rectangle
+}
+
+// NewLinearImage returns a new transparent LinearImage with the given bounds.
+func NewLinearImage(r image.Rectangle) *LinearImage {
+	return &LinearImage{
+		Pix:    make([]float32, 4*r.Dx()*r.Dy()),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+func (img *LinearImage) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (img *LinearImage) Bounds() image.Rectangle {
+	return img.Rect
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to the pixel at (x,y).
+func (img *LinearImage) PixOffset(x, y int) int {
+	return (y-img.Rect.Min.Y)*img.Stride + (x-img.Rect.Min.X)*4
+}
+
+// LinearAt returns the red, green, blue and alpha values of the pixel at (x,y) without clamping.
+func (img *LinearImage) LinearAt(x, y int) (float32, float32, float32, float32) {
+	if !(image.Point{x, y}).In(img.Rect) {
+		return 0.0, 0.0, 0.0, 0.0
+	}
+	i := img.PixOffset(x, y)
+	pix := img.Pix[i : i+4 : i+4]
+	return pix[0], pix[1], pix[2], pix[3]
+}
+
+func (img *LinearImage) At(x, y int) color.Color {
+	return img.RGBA64At(x, y)
+}
+
+func (img *LinearImage) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := img.LinearAt(x, y)
+	return color.RGBA64{toUint16(r), toUint16(g), toUint16(b), toUint16(a)}
+}
+
+func toUint16(v float32) uint16 {
+	return uint16(math.Min(math.Max(float64(v), 0.0), 1.0)*0xffff + 0.5)
+}
+
+// SetLinear sets the red, green, blue and alpha values of the pixel at (x,y).
+func (img *LinearImage) SetLinear(x, y int, r, g, b, a float32) {
+	if !(image.Point{x, y}).In(img.Rect) {
+		return
+	}
+	i := img.PixOffset(x, y)
+	pix := img.Pix[i : i+4 : i+4]
+	pix[0], pix[1], pix[2], pix[3] = r, g, b, a
+}
+
+func (img *LinearImage) Set(x, y int, c color.Color) {
+	r, g, b, a := c.RGBA()
+	img.SetLinear(x, y, float32(r)/0xffff, float32(g)/0xffff, float32(b)/0xffff, float32(a)/0xffff)
+}
+
+// blendOver composites a premultiplied color with the given 16-bit coverage over a pixel.
+func (img *LinearImage) blendOver(x, y int, c color.RGBA64, alpha uint32) {
+	cov := float32(alpha) / 0xffff
+	sr := float32(c.R) / 0xffff * cov
+	sg := float32(c.G) / 0xffff * cov
+	sb := float32(c.B) / 0xffff * cov
+	sa := float32(c.A) / 0xffff * cov
+
+	i := img.PixOffset(x, y)
+	pix := img.Pix[i : i+4 : i+4]
+	a := 1.0 - sa
+	pix[0] = sr + pix[0]*a
+	pix[1] = sg + pix[1]*a
+	pix[2] = sb + pix[2]*a
+	pix[3] = sa + pix[3]*a
+}
@@ -29,9 +29,16 @@ type Rasterizer struct {
 	draw.Image
 	resolution canvas.Resolution
 	colorSpace canvas.ColorSpace
+	resampling canvas.ImageResampling
 
 	spanner *scanx.ImgSpanner
 	scanner *scanx.Scanner
+	clip    image.Rectangle // relative to the image origin
+
+	antialiasing Antialiasing
+	sampler      *Scanner // created when needed for point sampling
+
+	glyphs *GlyphCache
 }
 
 // New returns a renderer that draws to a rasterized image. The final width and height of the image is the width and height (mm) multiplied by the resolution (px/mm), thus a higher resolution results in larger images. By default the linear color space is used, which assumes input and output colors are in linearRGB. If the sRGB color space is used for drawing with an average of gamma=2.2, the input and output colors are assumed to be in sRGB (a common assumption) and blending happens in linearRGB. Be aware that for text this results in thin stems for black-on-white (but wide stems for white-on-black).
@@ -62,6 +69,7 @@ func FromImage(img draw.Image, resolution canvas.Resolution, colorSpace canvas.C
 
 		spanner: spanner,
 		scanner: scanx.NewScanner(spanner, bounds.Dx(), bounds.Dy()),
+		clip:    image.Rect(0, 0, bounds.Dx(), bounds.Dy()),
 	}
 }
 
@@ -77,6 +85,17 @@ func (r *Rasterizer) SetOp(op draw.Op) {
 	r.spanner.Op = op
 }
 
+// setClip restricts drawing of paths to a rectangle relative to the image origin.
+func (r *Rasterizer) setClip(rect image.Rectangle) {
+	r.scanner.SetClip(rect)
+	r.clip = rect
+}
+
+// SetImageResampling sets the interpolation used to draw subsequent images.
+func (r *Rasterizer) SetImageResampling(resampling canvas.ImageResampling) {
+	r.resampling = resampling
+}
+
 // Size returns the size of the canvas in millimeters.
 func (r *Rasterizer) Size() (float64, float64) {
 	size := r.Bounds().Size()
@@ -85,14 +104,23 @@ func (r *Rasterizer) Size() (float64, float64) {
 
 // RenderPath renders a path to the canvas using a style and a transformation matrix.
 func (r *Rasterizer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
-	bounds := canvas.Rect{}
+	fill, stroke := outlines(path, style, m, r.resolution)
+	if style.HasFill() {
+		r.renderPaint(fill, style.Fill, style.FillRule, m)
+	}
+	if style.HasStroke() {
+		r.renderPaint(stroke, style.Stroke, style.FillRule, m)
+	}
+}
+
+// outlines returns the transformed outlines of the fill and stroke of a path, which are nil if the style has no fill or stroke respectively.
+func outlines(path *canvas.Path, style canvas.Style, m canvas.Matrix, resolution canvas.Resolution) (*canvas.Path, *canvas.Path) {
 	var fill, stroke *canvas.Path
 	if style.HasFill() {
 		fill = path.Copy().Transform(m)
-		bounds = fill.FastBounds()
 	}
 	if style.HasStroke() {
-		tolerance := canvas.PixelTolerance / r.resolution.DPMM()
+		tolerance := canvas.PixelTolerance / resolution.DPMM()
 		stroke = path
 		if 0 < len(style.Dashes) {
 			dashOffset, dashes := canvas.ScaleDash(style.StrokeWidth, style.DashOffset, style.Dashes)
@@ -100,83 +128,72 @@ func (r *Rasterizer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.
 		}
 		stroke = stroke.Stroke(style.StrokeWidth, style.StrokeCapper, style.StrokeJoiner, tolerance)
 		stroke = stroke.Transform(m)
-		if style.HasFill() {
-			bounds = bounds.Add(stroke.FastBounds())
+	}
+	return fill, stroke
+}
+
+// renderPaint renders a transformed outline with a paint. Hatch patterns are drawn as their tiled outline, while other patterns are rendered to the renderer.
+func (r *Rasterizer) renderPaint(path *canvas.Path, paint canvas.Paint, fillRule canvas.FillRule, m canvas.Matrix) {
+	if paint.IsPattern() {
+		if hatch, ok := paint.Pattern.(*canvas.HatchPattern); ok {
+			paint = hatch.Fill
+			path = hatch.Tile(path)
 		} else {
-			bounds = stroke.FastBounds()
+			pattern := paint.Pattern.Transform(m).SetColorSpace(r.colorSpace)
+			pattern.RenderTo(r, path)
+			return
 		}
 	}
+	r.drawPath(path, paint, fillRule, m)
+}
 
-	r.scanner.SetWinding(style.FillRule == canvas.NonZero)
+// drawPath draws a transformed outline with a color or gradient.
+func (r *Rasterizer) drawPath(path *canvas.Path, paint canvas.Paint, fillRule canvas.FillRule, m canvas.Matrix) {
+	if r.antialiasing != GrayscaleAntialiasing {
+		r.drawSampled(path, paint, fillRule, m)
+		return
+	}
+	r.scanner.SetWinding(fillRule == canvas.NonZero)
 
 	size := r.Bounds().Size()
-	if style.HasFill() {
-		if style.Fill.IsPattern() {
-			if hatch, ok := style.Fill.Pattern.(*canvas.HatchPattern); ok {
-				style.Fill = hatch.Fill
-				fill = hatch.Tile(fill)
-			} else {
-				pattern := style.Fill.Pattern.Transform(m).SetColorSpace(r.colorSpace)
-				pattern.RenderTo(r, fill)
-			}
-		}
-		if style.Fill.IsGradient() {
-			mInv := m.Inv()
-			gradient := style.Fill.Gradient.SetColorSpace(r.colorSpace)
-			r.scanner.Clear()
-			r.scanner.SetColor(rasterx.ColorFunc(func(x, y int) color.Color {
-				p := canvas.Point{(float64(x) + 0.5) / float64(r.resolution), (float64(size.Y-y) - 0.5) / float64(r.resolution)}
-				p = mInv.Dot(p)
-				return gradient.At(p.X, p.Y)
-			}))
-			fill.ToScanxScanner(r.scanner, float64(size.Y), r.resolution)
-			r.scanner.Draw()
-		} else if style.Fill.IsColor() {
-			c := r.colorSpace.ToLinear(style.Fill.Color)
-			r.scanner.Clear()
-			r.scanner.SetColor(color.Color(r.Image.ColorModel().Convert(c)))
-			fill.ToScanxScanner(r.scanner, float64(size.Y), r.resolution)
-			r.scanner.Draw()
-		}
-	}
-	if style.HasStroke() {
-		if style.Stroke.IsPattern() {
-			if hatch, ok := style.Stroke.Pattern.(*canvas.HatchPattern); ok {
-				style.Stroke = hatch.Fill
-				stroke = hatch.Tile(stroke)
-			} else {
-				pattern := style.Stroke.Pattern.Transform(m).SetColorSpace(r.colorSpace)
-				pattern.RenderTo(r, stroke)
-			}
-		}
-		if style.Stroke.IsGradient() {
-			mInv := m.Inv()
-			gradient := style.Stroke.Gradient.SetColorSpace(r.colorSpace)
-			r.scanner.Clear()
-			r.scanner.SetColor(rasterx.ColorFunc(func(x, y int) color.Color {
-				p := canvas.Point{(float64(x) + 0.5) / float64(r.resolution), (float64(size.Y-y) - 0.5) / float64(r.resolution)}
-				p = mInv.Dot(p)
-				return gradient.At(p.X, p.Y)
-			}))
-			stroke.ToScanxScanner(r.scanner, float64(size.Y), r.resolution)
-			r.scanner.Draw()
-		} else if style.Stroke.IsColor() {
-			c := r.colorSpace.ToLinear(style.Stroke.Color)
-			r.scanner.Clear()
-			r.scanner.SetColor(color.Color(r.Image.ColorModel().Convert(c)))
-			stroke.ToScanxScanner(r.scanner, float64(size.Y), r.resolution)
-			r.scanner.Draw()
-		}
+	if paint.IsGradient() {
+		mInv := m.Inv()
+		gradient := paint.Gradient.SetColorSpace(r.colorSpace)
+		r.scanner.Clear()
+		r.scanner.SetColor(rasterx.ColorFunc(func(x, y int) color.Color {
+			p := canvas.Point{(float64(x) + 0.5) / float64(r.resolution), (float64(size.Y-y) - 0.5) / float64(r.resolution)}
+			p = mInv.Dot(p)
+			return gradient.At(p.X, p.Y)
+		}))
+		path.ToScanxScanner(r.scanner, float64(size.Y), r.resolution)
+		r.scanner.Draw()
+	} else if paint.IsColor() {
+		c := r.colorSpace.ToLinear(paint.Color)
+		r.scanner.Clear()
+		r.scanner.SetColor(color.Color(r.Image.ColorModel().Convert(c)))
+		path.ToScanxScanner(r.scanner, float64(size.Y), r.resolution)
+		r.scanner.Draw()
 	}
 }
 
 // RenderText renders a text object to the canvas using a transformation matrix.
 func (r *Rasterizer) RenderText(text *canvas.Text, m canvas.Matrix) {
-	text.RenderTo(r, m, r.resolution)
+	if r.cachedText(text, m) {
+		r.renderCachedText(text, m)
+		return
+	}
+	text.RenderHintedTo(r, m, r.resolution)
 }
 
 // RenderImage renders an image to the canvas using a transformation matrix.
 func (r *Rasterizer) RenderImage(img image.Image, m canvas.Matrix) {
+	img, aff3 := imageTransform(img, m, r.resolution, r.colorSpace, float64(r.Bounds().Size().Y))
+	img, aff3, transformer := resample(img, aff3, r.resampling)
+	transformer.Transform(r, aff3, img, img.Bounds(), draw.Over, nil)
+}
+
+// imageTransform returns the image in linear color space with a margin if needed, and its transformation to a destination image of height h in pixels.
+func imageTransform(img image.Image, m canvas.Matrix, resolution canvas.Resolution, colorSpace canvas.ColorSpace, h float64) (image.Image, f64.Aff3) {
 	// add transparent margin to image for smooth borders when rotating
 	// TODO: optimize when transformation is only translation or stretch (if optimizing, dont overwrite original img when gamma correcting)
 	margin := 0
@@ -190,18 +207,15 @@ func (r *Rasterizer) RenderImage(img image.Image, m canvas.Matrix) {
 		img = img2
 	}
 
-	if _, ok := r.colorSpace.(canvas.LinearColorSpace); !ok {
+	if _, ok := colorSpace.(canvas.LinearColorSpace); !ok {
 		// gamma decompress
-		changeColorSpace(img.(draw.Image), img, r.colorSpace.ToLinear)
+		changeColorSpace(img.(draw.Image), img, colorSpace.ToLinear)
 	}
 
-	// draw to destination image
+	// transformation to destination image
 	// note that we need to correct for the added margin in origin and m
-	dpmm := r.resolution.DPMM()
+	dpmm := resolution.DPMM()
 	origin := m.Dot(canvas.Point{-float64(margin), float64(img.Bounds().Size().Y - margin)}).Mul(dpmm)
 	m = m.Scale(dpmm, dpmm)
-
-	h := float64(r.Bounds().Size().Y)
-	aff3 := f64.Aff3{m[0][0], -m[0][1], origin.X, -m[1][0], m[1][1], h - origin.Y}
-	draw.CatmullRom.Transform(r, aff3, img, img.Bounds(), draw.Over, nil)
+	return img, f64.Aff3{m[0][0], -m[0][1], origin.X, -m[1][0], m[1][1], h - origin.Y}
 }
@@ -0,0 +1,99 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+
+	"github.com/tdewolff/canvas"
+)
+
+// Lanczos is the Lanczos resampling kernel with a support of three pixels. It is sharper than Catmull-Rom but may produce ringing near hard edges.
+var Lanczos = &draw.Kernel{Support: 3.0, At: func(t float64) float64 {
+	if t == 0.0 {
+		return 1.0
+	} else if 3.0 <= t {
+		return 0.0
+	}
+	x := math.Pi * t
+	return 3.0 * math.Sin(x) * math.Sin(x/3.0) / (x * x)
+}}
+
+// resample returns the transformer that draws an image for the given resampling. For BoxResampling the image is first downsampled by averaging blocks of 2x2 pixels (mipmapping) until it is less than twice the size of the output, and the transformation is adjusted accordingly. The other kernels of golang.org/x/image/draw already widen their support when minifying.
+func resample(img image.Image, aff3 f64.Aff3, resampling canvas.ImageResampling) (image.Image, f64.Aff3, draw.Transformer) {
+	switch resampling {
+	case canvas.NearestResampling:
+		return img, aff3, draw.NearestNeighbor
+	case canvas.BilinearResampling:
+		return img, aff3, draw.BiLinear
+	case canvas.LanczosResampling:
+		return img, aff3, Lanczos
+	case canvas.BoxResampling:
+		img, aff3 = mipmap(img, aff3)
+		return img, aff3, draw.BiLinear
+	}
+	return img, aff3, draw.CatmullRom
+}
+
+// mipmap halves the image horizontally and/or vertically while at least two image pixels map to one output pixel in that direction. The transformation from image to output pixels is adjusted accordingly.
+func mipmap(img image.Image, aff3 f64.Aff3) (image.Image, f64.Aff3) {
+	for {
+		// output pixels per image pixel along the image's axes
+		sx := math.Hypot(aff3[0], aff3[3])
+		sy := math.Hypot(aff3[1], aff3[4])
+		halveX, halveY := sx <= 0.5 && 1 < img.Bounds().Dx(), sy <= 0.5 && 1 < img.Bounds().Dy()
+		if !halveX && !halveY {
+			return img, aff3
+		}
+
+		// move origin of the image to (0,0)
+		sp := img.Bounds().Min
+		aff3[2] += aff3[0]*float64(sp.X) + aff3[1]*float64(sp.Y)
+		aff3[5] += aff3[3]*float64(sp.X) + aff3[4]*float64(sp.Y)
+
+		img = halve(img, halveX, halveY)
+		if halveX {
+			aff3[0] *= 2.0
+			aff3[3] *= 2.0
+		}
+		if halveY {
+			aff3[1] *= 2.0
+			aff3[4] *= 2.0
+		}
+	}
+}
+
+// halve returns the image downsampled by a factor of two horizontally and/or vertically by averaging premultiplied colors. For odd sizes, the last row or column is averaged with transparent pixels so that the image keeps its size.
+func halve(img image.Image, halveX, halveY bool) *image.RGBA64 {
+	bounds := img.Bounds()
+	dx, dy := 1, 1
+	if halveX {
+		dx = 2
+	}
+	if halveY {
+		dy = 2
+	}
+
+	size := bounds.Size()
+	dst := image.NewRGBA64(image.Rect(0, 0, (size.X+dx-1)/dx, (size.Y+dy-1)/dy))
+	n := uint32(dx * dy)
+	for y := 0; y < dst.Rect.Max.Y; y++ {
+		for x := 0; x < dst.Rect.Max.X; x++ {
+			var r, g, b, a uint32
+			for j := y * dy; j < y*dy+dy && j < size.Y; j++ {
+				for i := x * dx; i < x*dx+dx && i < size.X; i++ {
+					R, G, B, A := img.At(bounds.Min.X+i, bounds.Min.Y+j).RGBA()
+					r += R
+					g += G
+					b += B
+					a += A
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,74 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/math/f64"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestImageResampling(t *testing.T) {
+	// magnification of pixel art keeps hard edges
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{0, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{255, 255, 255, 255})
+
+	c := canvas.New(8.0, 1.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetImageResampling(canvas.NearestResampling)
+	ctx.DrawImage(0.0, 0.0, img, canvas.DPMM(0.25))
+	dst := Draw(c, canvas.DPMM(1.0), nil)
+	for x := 0; x < 8; x++ {
+		test.T(t, dst.RGBAAt(x, 0).R, img.RGBAAt(x/4, 0).R)
+	}
+
+	// minification of a checkerboard averages its pixels
+	checker := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if (x+y)%2 == 0 {
+				checker.Set(x, y, color.RGBA{255, 255, 255, 255})
+			} else {
+				checker.Set(x, y, color.RGBA{0, 0, 0, 255})
+			}
+		}
+	}
+	for _, resampling := range []canvas.ImageResampling{canvas.BicubicResampling, canvas.BilinearResampling, canvas.LanczosResampling, canvas.BoxResampling} {
+		t.Run(resampling.String(), func(t *testing.T) {
+			c := canvas.New(4.0, 4.0)
+			ctx := canvas.NewContext(c)
+			ctx.SetImageResampling(resampling)
+			ctx.DrawImage(0.0, 0.0, checker, canvas.DPMM(16.0))
+			for _, dst := range []*image.RGBA{Draw(c, canvas.DPMM(1.0), nil), DrawTiled(c, canvas.DPMM(1.0), nil)} {
+				for y := 0; y < 4; y++ {
+					for x := 0; x < 4; x++ {
+						test.FloatDiff(t, float64(dst.RGBAAt(x, y).R), 127.5, 2.0)
+					}
+				}
+			}
+
+			dst := DrawScanline(c, canvas.DPMM(1.0), nil)
+			test.FloatDiff(t, float64(dst.RGBAAt(1, 1).R), 127.5, 2.0)
+		})
+	}
+}
+
+func TestMipmap(t *testing.T) {
+	img := image.NewRGBA(image.Rect(2, 2, 7, 6))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	// five pixels to one is halved twice horizontally, four to three is not halved vertically
+	mip, aff3 := mipmap(img, f64.Aff3{0.2, 0.0, 1.0, 0.0, 0.75, 2.0})
+	test.T(t, mip.Bounds(), image.Rect(0, 0, 2, 4))
+	test.T(t, aff3, f64.Aff3{0.8, 0.0, 1.4, 0.0, 0.75, 3.5})
+
+	// the last column is averaged with transparent pixels
+	test.T(t, mip.At(0, 0), color.Color(color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}))
+	test.T(t, mip.At(1, 0), color.Color(color.RGBA64{0x3fff, 0x3fff, 0x3fff, 0x3fff}))
+}
@@ -0,0 +1,314 @@
+package rasterizer
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/tdewolff/canvas"
+)
+
+// cell is the accumulated coverage of a pixel, where area is the signed area covered inside the pixel and cover is the signed height of the edges crossing the pixel, which accumulates for all pixels to the right.
+type cell struct {
+	x           int
+	area, cover float32
+}
+
+// Scanner is a sparse scanline rasterizer that computes the exact area coverage of paths. For each row of pixels it keeps only the cells that are crossed by edges, and coverage in between cells is derived from the accumulated winding. Since all subpaths of a path are accumulated before being converted to coverage, edges that are shared between subpaths do not leave any seams (conflation artifacts). Coordinates are in pixels with the origin in the top-left corner. Instead of the exact area coverage, the scanner can sample the path at points within each pixel, see SetSamples.
+type Scanner struct {
+	width, height int
+	samples       int      // number of point samples per pixel in each direction, or zero for area coverage
+	rows          [][]cell // rows of pixels, or rows of samples with cells of samples
+	minY, maxY    int      // range of rows with cells
+
+	start, pos canvas.Point
+}
+
+// NewScanner returns a new scanner for an image of the given width and height in pixels.
+func NewScanner(width, height int) *Scanner {
+	s := &Scanner{
+		width:  width,
+		height: height,
+		rows:   make([][]cell, height),
+	}
+	s.Reset()
+	return s
+}
+
+// SetSamples sets the number of point samples per pixel in each direction, so that the coverage of a pixel is the fraction of its n×n samples that lie inside the path. The samples are at the centers of the n×n subpixels, and a sample that lies exactly on the left or top edge of a path is inside. For n=1 pixels are sampled only at their center, which results in hard edges without partial coverage (aliased). For n=0 (default) the exact area coverage is used. Accumulated coverage is cleared.
+func (s *Scanner) SetSamples(n int) {
+	s.Reset()
+	s.samples = max(n, 0)
+	s.rows = make([][]cell, s.height*max(n, 1))
+	s.minY, s.maxY = len(s.rows), 0
+}
+
+// Reset clears all accumulated coverage.
+func (s *Scanner) Reset() {
+	for y := s.minY; y < s.maxY; y++ {
+		s.rows[y] = s.rows[y][:0]
+	}
+	s.minY, s.maxY = len(s.rows), 0
+	s.start, s.pos = canvas.Point{}, canvas.Point{}
+}
+
+// MoveTo starts a new subpath and implicitly closes the previous subpath.
+func (s *Scanner) MoveTo(x, y float64) {
+	s.Close()
+	s.start = canvas.Point{X: x, Y: y}
+	s.pos = s.start
+}
+
+// LineTo adds a line to the current subpath.
+func (s *Scanner) LineTo(x, y float64) {
+	s.line(s.pos.X, s.pos.Y, x, y)
+	s.pos = canvas.Point{X: x, Y: y}
+}
+
+// Close closes the current subpath.
+func (s *Scanner) Close() {
+	s.LineTo(s.start.X, s.start.Y)
+}
+
+// AddPath adds a path in canvas coordinates (millimeters) to the scanner, where dy is the height of the image in pixels used to flip the y-axis. Subpaths are implicitly closed.
+func (s *Scanner) AddPath(p *canvas.Path, dy float64, resolution canvas.Resolution) {
+	dpmm := resolution.DPMM()
+	tolerance := canvas.PixelTolerance / dpmm // tolerance of 1/10 of a pixel
+	for seg := p.Flatten(tolerance).Scanner(); seg.Scan(); {
+		end := seg.End()
+		switch seg.Cmd() {
+		case canvas.MoveToCmd:
+			s.MoveTo(end.X*dpmm, dy-end.Y*dpmm)
+		default:
+			s.LineTo(end.X*dpmm, dy-end.Y*dpmm)
+		}
+	}
+	s.Close()
+}
+
+// line adds the coverage of a line, after clipping it horizontally to the image. Parts left of the image are moved onto its left border as they still contribute to the winding of all pixels to the right, and parts right of the image are dropped.
+func (s *Scanner) line(x0, y0, x1, y1 float64) {
+	if y0 == y1 || math.IsNaN(x0) || math.IsNaN(x1) {
+		return
+	}
+
+	w := float64(s.width)
+	for _, bound := range []float64{0.0, w} {
+		if (x0 < bound) != (x1 < bound) && x0 != bound && x1 != bound {
+			// split at the vertical bound
+			y := y0 + (y1-y0)*(bound-x0)/(x1-x0)
+			s.line(x0, y0, bound, y)
+			s.line(bound, y, x1, y1)
+			return
+		}
+	}
+	if w <= x0 && w <= x1 {
+		return
+	}
+	s.clippedLine(math.Max(x0, 0.0), y0, math.Max(x1, 0.0), y1)
+}
+
+// clippedLine adds the coverage of a line that is horizontally inside the image.
+func (s *Scanner) clippedLine(x0, y0, x1, y1 float64) {
+	dir := float32(1.0)
+	if y1 < y0 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+		dir = -1.0
+	}
+	if y1 <= 0.0 || float64(s.height) <= y0 {
+		return
+	} else if s.samples != 0 {
+		s.sampledLine(x0, y0, x1, y1, dir)
+		return
+	}
+
+	dxdy := (x1 - x0) / (y1 - y0)
+	rowMin := max(int(math.Floor(y0)), 0)
+	rowMax := min(int(math.Ceil(y1)), s.height)
+	for row := rowMin; row < rowMax; row++ {
+		// part of the line inside the row
+		ya := math.Max(y0, float64(row))
+		yb := math.Min(y1, float64(row+1))
+		xa := x0 + (ya-y0)*dxdy
+		xb := x0 + (yb-y0)*dxdy
+
+		// split the part at pixel boundaries
+		if xb < xa {
+			xa, ya, xb, yb = xb, yb, xa, ya
+		}
+		for xs, ys := xa, ya; ; {
+			col := math.Floor(xs)
+			xe, ye := math.Min(xb, col+1.0), yb
+			if xe < xb {
+				ye = ya + (xe-xa)*(yb-ya)/(xb-xa)
+			}
+			mid := (xs+xe)/2.0 - col
+			dy := dir * float32(math.Abs(ye-ys))
+			s.add(row, int(col), dy*float32(1.0-mid), dy)
+			if xb <= xe {
+				break
+			}
+			xs, ys = xe, ye
+		}
+	}
+}
+
+// sampledLine adds the crossings of a line with the rows of samples, where y0 < y1. For each row of samples, the winding of the first sample to the right of the crossing and all samples after it are changed.
+func (s *Scanner) sampledLine(x0, y0, x1, y1 float64, dir float32) {
+	n := float64(s.samples)
+	x0, y0, x1, y1 = x0*n, y0*n, x1*n, y1*n
+	dxdy := (x1 - x0) / (y1 - y0)
+
+	// samples are at the centers of the subpixels, the top sample is included and the bottom sample is excluded
+	rowMin := max(int(math.Ceil(y0-0.5)), 0)
+	rowMax := min(int(math.Ceil(y1-0.5)), len(s.rows))
+	for row := rowMin; row < rowMax; row++ {
+		x := x0 + (float64(row)+0.5-y0)*dxdy
+		col := max(int(math.Ceil(x-0.5)), 0)
+		if col < s.width*s.samples {
+			s.add(row, col, dir, dir)
+		}
+	}
+}
+
+func (s *Scanner) add(row, x int, area, cover float32) {
+	cells := s.rows[row]
+	if n := len(cells); 0 < n && cells[n-1].x == x {
+		cells[n-1].area += area
+		cells[n-1].cover += cover
+		return
+	}
+	s.rows[row] = append(cells, cell{x, area, cover})
+	s.minY = min(s.minY, row)
+	s.maxY = max(s.maxY, row+1)
+}
+
+// Bounds returns the bounds of the rows that have coverage, with the full width of the image.
+func (s *Scanner) Bounds() image.Rectangle {
+	if s.maxY <= s.minY {
+		return image.Rectangle{}
+	} else if 1 < s.samples {
+		return image.Rect(0, s.minY/s.samples, s.width, (s.maxY+s.samples-1)/s.samples)
+	}
+	return image.Rect(0, s.minY, s.width, s.maxY)
+}
+
+// coverage returns the coverage in [0,1] for an accumulated winding using the fill rule.
+func coverage(winding float32, fillRule canvas.FillRule) float32 {
+	switch fillRule {
+	case canvas.EvenOdd:
+		w := float32(math.Mod(math.Abs(float64(winding)), 2.0))
+		if 1.0 < w {
+			w = 2.0 - w
+		}
+		return w
+	case canvas.Positive:
+		return min(max(winding, 0.0), 1.0)
+	case canvas.Negative:
+		return min(max(-winding, 0.0), 1.0)
+	}
+	if winding < 0.0 {
+		winding = -winding
+	}
+	return min(winding, 1.0)
+}
+
+// Spans calls span for each horizontal run of pixels with equal, non-zero coverage within the clip rectangle, using the fill rule to convert windings to coverage. The coverage alpha is 16-bit. Spans are sorted by y and then x.
+func (s *Scanner) Spans(fillRule canvas.FillRule, clip image.Rectangle, span func(y, x0, x1 int, alpha uint16)) {
+	clip = clip.Intersect(s.Bounds())
+	if 1 < s.samples {
+		s.sampledSpans(fillRule, clip, span)
+		return
+	}
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		cells := s.rows[y]
+		slices.SortStableFunc(cells, func(a, b cell) int {
+			return a.x - b.x
+		})
+
+		emit := func(x0, x1 int, winding float32) {
+			x0, x1 = max(x0, clip.Min.X), min(x1, clip.Max.X)
+			if x0 < x1 {
+				if alpha := uint16(coverage(winding, fillRule)*0xffff + 0.5); alpha != 0 {
+					span(y, x0, x1, alpha)
+				}
+			}
+		}
+
+		var winding float32
+		for i := 0; i < len(cells); {
+			x := cells[i].x
+			area, cover := float32(0.0), float32(0.0)
+			for ; i < len(cells) && cells[i].x == x; i++ {
+				area += cells[i].area
+				cover += cells[i].cover
+			}
+			emit(x, x+1, winding+area)
+			winding += cover
+
+			next := s.width
+			if i < len(cells) {
+				next = cells[i].x
+			}
+			emit(x+1, next, winding)
+		}
+	}
+}
+
+// sampledSpans calls span for each horizontal run of pixels with equal, non-zero coverage within the clip rectangle, where coverage is the fraction of samples of a pixel that are inside the path.
+func (s *Scanner) sampledSpans(fillRule canvas.FillRule, clip image.Rectangle, span func(y, x0, x1 int, alpha uint16)) {
+	n := s.samples
+	counts := make([]int, s.width) // number of samples inside per pixel
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		xmin, xmax := s.width, 0
+		for row := y * n; row < (y+1)*n; row++ {
+			cells := s.rows[row]
+			slices.SortStableFunc(cells, func(a, b cell) int {
+				return a.x - b.x
+			})
+
+			var winding float32
+			for i := 0; i < len(cells); {
+				x0 := cells[i].x
+				for ; i < len(cells) && cells[i].x == x0; i++ {
+					winding += cells[i].cover
+				}
+				x1 := s.width * n
+				if i < len(cells) {
+					x1 = cells[i].x
+				}
+				if x0 == x1 || coverage(winding, fillRule) < 0.5 {
+					continue
+				}
+
+				// add run of samples [x0,x1) to the pixels
+				px0, px1 := x0/n, x1/n
+				if px0 == px1 {
+					counts[px0] += x1 - x0
+				} else {
+					counts[px0] += n - x0%n
+					for px := px0 + 1; px < px1; px++ {
+						counts[px] += n
+					}
+					if x1%n != 0 {
+						counts[px1] += x1 % n
+					}
+				}
+				xmin = min(xmin, px0)
+				xmax = max(xmax, (x1+n-1)/n)
+			}
+		}
+
+		for x0 := xmin; x0 < xmax; {
+			x1 := x0 + 1
+			for x1 < xmax && counts[x1] == counts[x0] {
+				x1++
+			}
+			if cx0, cx1 := max(x0, clip.Min.X), min(x1, clip.Max.X); cx0 < cx1 && counts[x0] != 0 {
+				span(y, cx0, cx1, uint16((counts[x0]*0xffff+n*n/2)/(n*n)))
+			}
+			x0 = x1
+		}
+		clear(counts[xmin:max(xmin, xmax)])
+	}
+}
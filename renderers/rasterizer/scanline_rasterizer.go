@@ -0,0 +1,322 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+
+	"github.com/tdewolff/canvas"
+)
+
+// DrawScanline draws the canvas on a new image with given resolution (in dots-per-millimeter) using the scanline rasterizer, see Draw.
+func DrawScanline(c *canvas.Canvas, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(c.W*resolution.DPMM()+0.5), int(c.H*resolution.DPMM()+0.5)))
+	ras := ScanlineFromImage(img, resolution, colorSpace)
+	c.RenderTo(ras)
+	ras.Close()
+	return img
+}
+
+// DrawRGBA64 draws the canvas on a new 16-bit image with given resolution (in dots-per-millimeter) using the scanline rasterizer.
+func DrawRGBA64(c *canvas.Canvas, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *image.RGBA64 {
+	img := image.NewRGBA64(image.Rect(0, 0, int(c.W*resolution.DPMM()+0.5), int(c.H*resolution.DPMM()+0.5)))
+	ras := ScanlineFromImage(img, resolution, colorSpace)
+	c.RenderTo(ras)
+	ras.Close()
+	return img
+}
+
+// DrawNRGBA64 draws the canvas on a new non-premultiplied 16-bit image with given resolution (in dots-per-millimeter) using the scanline rasterizer.
+func DrawNRGBA64(c *canvas.Canvas, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *image.NRGBA64 {
+	img := image.NewNRGBA64(image.Rect(0, 0, int(c.W*resolution.DPMM()+0.5), int(c.H*resolution.DPMM()+0.5)))
+	ras := ScanlineFromImage(img, resolution, colorSpace)
+	c.RenderTo(ras)
+	ras.Close()
+	return img
+}
+
+// DrawLinear draws the canvas on a new floating-point image in linear light with given resolution (in dots-per-millimeter) using the scanline rasterizer. Colors are converted to linear light using the color space, and the image is not converted back.
+func DrawLinear(c *canvas.Canvas, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *LinearImage {
+	img := NewLinearImage(image.Rect(0, 0, int(c.W*resolution.DPMM()+0.5), int(c.H*resolution.DPMM()+0.5)))
+	ras := ScanlineFromImage(img, resolution, colorSpace)
+	c.RenderTo(ras)
+	ras.Close()
+	return img
+}
+
+// ScanlineRasterizer is a rasterizing renderer that uses the sparse scanline rasterizer with exact area coverage, see Scanner. It supports all fill rules. Consecutive fills and strokes with the same opaque color are accumulated into one coverage before being blended, so that edges shared between separate paths, such as adjacent polygons of a map, leave no seams (conflation artifacts). Where such paths overlap they are drawn once, which is the same as drawing them one at a time since the color is opaque. Paths with other paints are blended one at a time. Drawing happens in a 16-bit premultiplied buffer in the linear color space, so that blending is in linear light for color spaces such as sRGB, and the buffer is converted to the color space of the destination image at Close. Destination images with 16 bits per channel, such as image.RGBA64 and image.NRGBA64, are converted with 16-bit precision. When drawing to a LinearImage, drawing happens directly on the image in floating-point precision and it is not converted, but Close must still be called to blend the accumulated coverage.
+type ScanlineRasterizer struct {
+	draw.Image
+	resolution canvas.Resolution
+	colorSpace canvas.ColorSpace
+	resampling canvas.ImageResampling
+
+	buf     draw.Image // *image.RGBA64 or *LinearImage in linear color space
+	scanner *Scanner
+
+	// accumulated coverage of consecutive paths with the same opaque color, see renderPaint
+	coverage     []uint16
+	coverageRect image.Rectangle
+	coverageCol  color.RGBA
+}
+
+// NewScanline returns a scanline renderer that draws to a rasterized image, see New.
+func NewScanline(width, height float64, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *ScanlineRasterizer {
+	img := image.NewRGBA(image.Rect(0, 0, int(width*resolution.DPMM()+0.5), int(height*resolution.DPMM()+0.5)))
+	return ScanlineFromImage(img, resolution, colorSpace)
+}
+
+// ScanlineFromImage returns a scanline renderer that draws over an existing image, see FromImage. The image is only written to at Close, unless it is a LinearImage.
+func ScanlineFromImage(img draw.Image, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *ScanlineRasterizer {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		panic("raster size is zero, increase resolution")
+	} else if math.MaxInt32/bounds.Dx() < bounds.Dy() {
+		panic("raster size overflow, decrease resolution")
+	}
+
+	if colorSpace == nil {
+		colorSpace = canvas.DefaultColorSpace
+	}
+
+	var buf draw.Image
+	linear, isLinear := img.(*LinearImage)
+	if isLinear && bounds.Min == (image.Point{}) {
+		buf = linear
+	} else {
+		// gamma decompress the existing image
+		rgba64 := image.NewRGBA64(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				if c := img.At(bounds.Min.X+x, bounds.Min.Y+y); isLinear {
+					rgba64.Set(x, y, c)
+				} else if !isTransparent(c) {
+					rgba64.SetRGBA64(x, y, canvas.ToLinear64(colorSpace, c))
+				}
+			}
+		}
+		buf = rgba64
+	}
+	return &ScanlineRasterizer{
+		Image:      img,
+		resolution: resolution,
+		colorSpace: colorSpace,
+		buf:        buf,
+		scanner:    NewScanner(bounds.Dx(), bounds.Dy()),
+	}
+}
+
+func isTransparent(c color.Color) bool {
+	_, _, _, a := c.RGBA()
+	return a == 0
+}
+
+// isHighBitDepth returns true if the image has more than 8 bits per channel.
+func isHighBitDepth(img image.Image) bool {
+	switch img.ColorModel() {
+	case color.RGBA64Model, color.NRGBA64Model, color.Gray16Model, color.Alpha16Model:
+		return true
+	}
+	return false
+}
+
+// Close blends the accumulated coverage, converts the buffer to the color space and writes it to the image.
+func (r *ScanlineRasterizer) Close() {
+	r.flush()
+	buf, ok := r.buf.(*image.RGBA64)
+	if !ok {
+		return // drawn directly to LinearImage
+	}
+
+	_, isLinear := r.Image.(*LinearImage)
+	highBitDepth := isHighBitDepth(r.Image)
+	bounds := r.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := buf.RGBA64At(x, y)
+			if c.A == 0 {
+				r.Image.Set(bounds.Min.X+x, bounds.Min.Y+y, color.RGBA64{})
+			} else if isLinear {
+				r.Image.Set(bounds.Min.X+x, bounds.Min.Y+y, c)
+			} else if highBitDepth {
+				r.Image.Set(bounds.Min.X+x, bounds.Min.Y+y, canvas.FromLinear64(r.colorSpace, c))
+			} else {
+				r.Image.Set(bounds.Min.X+x, bounds.Min.Y+y, r.colorSpace.FromLinear(c))
+			}
+		}
+	}
+}
+
+// SetImageResampling sets the interpolation used to draw subsequent images.
+func (r *ScanlineRasterizer) SetImageResampling(resampling canvas.ImageResampling) {
+	r.resampling = resampling
+}
+
+// Size returns the size of the canvas in millimeters.
+func (r *ScanlineRasterizer) Size() (float64, float64) {
+	size := r.Bounds().Size()
+	return float64(size.X) / r.resolution.DPMM(), float64(size.Y) / r.resolution.DPMM()
+}
+
+// RenderPath renders a path to the canvas using a style and a transformation matrix.
+func (r *ScanlineRasterizer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	fill, stroke := outlines(path, style, m, r.resolution)
+	if style.HasFill() {
+		r.renderPaint(fill, style.Fill, style.FillRule, m)
+	}
+	if style.HasStroke() {
+		// strokes may overlap themselves
+		r.renderPaint(stroke, style.Stroke, canvas.NonZero, m)
+	}
+}
+
+// renderPaint renders a transformed outline with a paint, see Rasterizer.renderPaint. Consecutive paths with the same opaque color are accumulated into one coverage that is blended by flush, so that edges shared between the paths leave no seams.
+func (r *ScanlineRasterizer) renderPaint(path *canvas.Path, paint canvas.Paint, fillRule canvas.FillRule, m canvas.Matrix) {
+	if paint.IsPattern() {
+		if hatch, ok := paint.Pattern.(*canvas.HatchPattern); ok {
+			paint = hatch.Fill
+			path = hatch.Tile(path)
+		} else {
+			r.flush()
+			pattern := paint.Pattern.Transform(m).SetColorSpace(r.colorSpace)
+			pattern.RenderTo(r, path)
+			return
+		}
+	}
+
+	if paint.IsColor() && paint.Color.A == 0xff {
+		if paint.Color != r.coverageCol {
+			r.flush()
+			r.coverageCol = paint.Color
+		}
+		r.accumulate(path, fillRule)
+		return
+	}
+	r.flush()
+
+	var colorAt func(x, y int) color.RGBA64
+	if paint.IsGradient() {
+		mInv := m.Inv()
+		gradient := paint.Gradient.SetColorSpace(r.colorSpace)
+		at64 := func(x, y float64) color.RGBA64 {
+			R, G, B, A := gradient.At(x, y).RGBA()
+			return color.RGBA64{uint16(R), uint16(G), uint16(B), uint16(A)}
+		}
+		if gradient64, ok := gradient.(interface {
+			At64(float64, float64) color.RGBA64
+		}); ok {
+			at64 = gradient64.At64
+		}
+		h := r.buf.Bounds().Dy()
+		colorAt = func(x, y int) color.RGBA64 {
+			p := canvas.Point{X: (float64(x) + 0.5) / float64(r.resolution), Y: (float64(h-y) - 0.5) / float64(r.resolution)}
+			p = mInv.Dot(p)
+			return at64(p.X, p.Y)
+		}
+	} else if paint.IsColor() {
+		c := canvas.ToLinear64(r.colorSpace, paint.Color)
+		colorAt = func(int, int) color.RGBA64 {
+			return c
+		}
+	} else {
+		return
+	}
+
+	blend := r.blender()
+	r.scanner.Reset()
+	r.scanner.AddPath(path, float64(r.buf.Bounds().Dy()), r.resolution)
+	r.scanner.Spans(fillRule, r.buf.Bounds(), func(y, x0, x1 int, alpha uint16) {
+		for x := x0; x < x1; x++ {
+			blend(x, y, colorAt(x, y), uint32(alpha))
+		}
+	})
+}
+
+// accumulate adds the coverage of a path to the accumulated coverage, which saturates where paths overlap. Edges shared between paths add up to full coverage.
+func (r *ScanlineRasterizer) accumulate(path *canvas.Path, fillRule canvas.FillRule) {
+	bounds := r.buf.Bounds()
+	if r.coverage == nil {
+		r.coverage = make([]uint16, bounds.Dx()*bounds.Dy())
+	}
+	r.scanner.Reset()
+	r.scanner.AddPath(path, float64(bounds.Dy()), r.resolution)
+	r.scanner.Spans(fillRule, bounds, func(y, x0, x1 int, alpha uint16) {
+		coverage := r.coverage[y*bounds.Dx()+x0 : y*bounds.Dx()+x1]
+		for i, a := range coverage {
+			coverage[i] = uint16(min(uint32(a)+uint32(alpha), 0xffff))
+		}
+		r.coverageRect = r.coverageRect.Union(image.Rect(x0, y, x1, y+1))
+	})
+}
+
+// flush blends the accumulated coverage with its color and clears it, see renderPaint.
+func (r *ScanlineRasterizer) flush() {
+	if r.coverageRect.Empty() {
+		return
+	}
+	c := canvas.ToLinear64(r.colorSpace, r.coverageCol)
+	blend := r.blender()
+	w := r.buf.Bounds().Dx()
+	for y := r.coverageRect.Min.Y; y < r.coverageRect.Max.Y; y++ {
+		coverage := r.coverage[y*w+r.coverageRect.Min.X : y*w+r.coverageRect.Max.X]
+		for i, alpha := range coverage {
+			if alpha != 0 {
+				blend(r.coverageRect.Min.X+i, y, c, uint32(alpha))
+				coverage[i] = 0
+			}
+		}
+	}
+	r.coverageRect = image.Rectangle{}
+}
+
+// blender returns the function that composites a premultiplied color with the given coverage over a pixel of the buffer.
+func (r *ScanlineRasterizer) blender() func(x, y int, c color.RGBA64, alpha uint32) {
+	if buf, ok := r.buf.(*LinearImage); ok {
+		return buf.blendOver
+	}
+	buf := r.buf.(*image.RGBA64)
+	return func(x, y int, c color.RGBA64, alpha uint32) {
+		blendOver(buf, x, y, c, alpha)
+	}
+}
+
+// blendOver composites a premultiplied color with the given coverage over a pixel of the buffer with 16-bit precision.
+func blendOver(buf *image.RGBA64, x, y int, c color.RGBA64, alpha uint32) {
+	const m = 0xffff
+	sr := uint32(c.R) * alpha / m
+	sg := uint32(c.G) * alpha / m
+	sb := uint32(c.B) * alpha / m
+	sa := uint32(c.A) * alpha / m
+
+	i := buf.PixOffset(x, y)
+	pix := buf.Pix[i : i+8 : i+8]
+	dr := uint32(pix[0])<<8 | uint32(pix[1])
+	dg := uint32(pix[2])<<8 | uint32(pix[3])
+	db := uint32(pix[4])<<8 | uint32(pix[5])
+	da := uint32(pix[6])<<8 | uint32(pix[7])
+
+	a := m - sa
+	dr = sr + dr*a/m
+	dg = sg + dg*a/m
+	db = sb + db*a/m
+	da = sa + da*a/m
+	pix[0], pix[1] = uint8(dr>>8), uint8(dr)
+	pix[2], pix[3] = uint8(dg>>8), uint8(dg)
+	pix[4], pix[5] = uint8(db>>8), uint8(db)
+	pix[6], pix[7] = uint8(da>>8), uint8(da)
+}
+
+// RenderText renders a text object to the canvas using a transformation matrix.
+func (r *ScanlineRasterizer) RenderText(text *canvas.Text, m canvas.Matrix) {
+	text.RenderHintedTo(r, m, r.resolution)
+}
+
+// RenderImage renders an image to the canvas using a transformation matrix.
+func (r *ScanlineRasterizer) RenderImage(img image.Image, m canvas.Matrix) {
+	r.flush()
+	img, aff3 := imageTransform(img, m, r.resolution, r.colorSpace, float64(r.buf.Bounds().Dy()))
+	img, aff3, transformer := resample(img, aff3, r.resampling)
+	transformer.Transform(r.buf, aff3, img, img.Bounds(), draw.Over, nil)
+}
@@ -0,0 +1,266 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func scannerCoverage(s *Scanner, fillRule canvas.FillRule) [][]float64 {
+	grid := make([][]float64, s.height)
+	for y := range grid {
+		grid[y] = make([]float64, s.width)
+	}
+	s.Spans(fillRule, image.Rect(0, 0, s.width, s.height), func(y, x0, x1 int, alpha uint16) {
+		for x := x0; x < x1; x++ {
+			grid[y][x] = float64(alpha) / 0xffff
+		}
+	})
+	return grid
+}
+
+func TestScanner(t *testing.T) {
+	var tts = []struct {
+		name     string
+		path     func(*Scanner)
+		fillRule canvas.FillRule
+		expected [][]float64
+	}{
+		{"rectangle", func(s *Scanner) {
+			s.MoveTo(0.5, 0.5)
+			s.LineTo(2.5, 0.5)
+			s.LineTo(2.5, 1.5)
+			s.LineTo(0.5, 1.5)
+		}, canvas.NonZero, [][]float64{
+			{0.25, 0.5, 0.25},
+			{0.25, 0.5, 0.25},
+		}},
+		{"triangle", func(s *Scanner) {
+			s.MoveTo(0.0, 0.0)
+			s.LineTo(2.0, 2.0)
+			s.LineTo(0.0, 2.0)
+		}, canvas.NonZero, [][]float64{
+			{0.5, 0.0, 0.0},
+			{1.0, 0.5, 0.0},
+		}},
+		{"shared edge", func(s *Scanner) {
+			s.MoveTo(0.0, 0.0)
+			s.LineTo(2.3, 0.0)
+			s.LineTo(0.0, 2.0)
+			s.MoveTo(2.3, 0.0)
+			s.LineTo(2.3, 2.0)
+			s.LineTo(0.0, 2.0)
+		}, canvas.NonZero, [][]float64{
+			{1.0, 1.0, 0.3},
+			{1.0, 1.0, 0.3},
+		}},
+		{"outside", func(s *Scanner) {
+			s.MoveTo(-5.0, -1.0)
+			s.LineTo(1.5, -1.0)
+			s.LineTo(1.5, 1.0)
+			s.LineTo(-5.0, 1.0)
+		}, canvas.NonZero, [][]float64{
+			{1.0, 0.5, 0.0},
+			{0.0, 0.0, 0.0},
+		}},
+		{"overlap nonzero", overlappingSquares, canvas.NonZero, [][]float64{
+			{1.0, 1.0, 0.0},
+			{1.0, 1.0, 1.0},
+		}},
+		{"overlap evenodd", overlappingSquares, canvas.EvenOdd, [][]float64{
+			{1.0, 1.0, 0.0},
+			{1.0, 0.0, 1.0},
+		}},
+		{"overlap positive", overlappingSquares, canvas.Positive, [][]float64{
+			{1.0, 1.0, 0.0},
+			{1.0, 1.0, 1.0},
+		}},
+		{"overlap negative", overlappingSquares, canvas.Negative, [][]float64{
+			{0.0, 0.0, 0.0},
+			{0.0, 0.0, 0.0},
+		}},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(3, 2)
+			tt.path(s)
+			s.Close()
+			grid := scannerCoverage(s, tt.fillRule)
+			for y := range tt.expected {
+				for x := range tt.expected[y] {
+					test.FloatDiff(t, grid[y][x], tt.expected[y][x], 1e-4)
+				}
+			}
+		})
+	}
+}
+
+// overlappingSquares adds two squares with positive winding in pixel coordinates, which is counter clock-wise in canvas coordinates.
+func overlappingSquares(s *Scanner) {
+	s.MoveTo(0.0, 0.0)
+	s.LineTo(0.0, 2.0)
+	s.LineTo(2.0, 2.0)
+	s.LineTo(2.0, 0.0)
+	s.MoveTo(1.0, 1.0)
+	s.LineTo(1.0, 2.0)
+	s.LineTo(3.0, 2.0)
+	s.LineTo(3.0, 1.0)
+}
+
+func TestScanlineRasterizer(t *testing.T) {
+	c := canvas.New(4.0, 2.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.White)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(4.0, 2.0))
+	ctx.SetFillColor(canvas.Black)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(1.5, 2.0))
+
+	// half coverage is blended in linear light
+	img := DrawScanline(c, canvas.DPMM(1.0), canvas.SRGBColorSpace{})
+	test.T(t, img.At(0, 0), color.Color(color.RGBA{0, 0, 0, 255}))
+	test.T(t, img.At(1, 0), color.Color(color.RGBA{188, 188, 188, 255}))
+	test.T(t, img.At(2, 0), color.Color(color.RGBA{255, 255, 255, 255}))
+
+	img = DrawScanline(c, canvas.DPMM(1.0), canvas.LinearColorSpace{})
+	test.T(t, img.At(1, 0), color.Color(color.RGBA{127, 127, 127, 255}))
+
+	// rectangles are counter clock-wise
+	style := canvas.DefaultStyle
+	style.FillRule = canvas.Positive
+	ras := NewScanline(4.0, 2.0, canvas.DPMM(1.0), nil)
+	ras.RenderPath(canvas.Rectangle(1.0, 1.0), style, canvas.Identity)
+	style.FillRule = canvas.Negative
+	ras.RenderPath(canvas.Rectangle(1.0, 1.0), style, canvas.Identity.Translate(2.0, 0.0))
+	ras.Close()
+	test.T(t, ras.Image.At(0, 1), color.Color(color.RGBA{0, 0, 0, 255}))
+	test.T(t, ras.Image.At(2, 1), color.Color(color.RGBA{}))
+
+	// draw over existing image
+	dst := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	dst.Set(3, 1, color.RGBA{255, 0, 0, 255})
+	ras = ScanlineFromImage(dst, canvas.DPMM(1.0), nil)
+	ras.RenderPath(canvas.Rectangle(1.0, 1.0), canvas.DefaultStyle, canvas.Identity)
+	ras.Close()
+	test.T(t, dst.At(0, 1), color.Color(color.RGBA{0, 0, 0, 255}))
+	test.T(t, dst.At(3, 1), color.Color(color.RGBA{255, 0, 0, 255}))
+	test.T(t, dst.At(1, 1), color.Color(color.RGBA{}))
+}
+
+func TestScanlineRasterizerSeams(t *testing.T) {
+	left := canvas.MustParseSVGPath("M0 0L1.5 0L1.5 2L0 2z")
+	right := canvas.MustParseSVGPath("M1.5 0L4 0L4 2L1.5 2z")
+
+	// subpaths of one path share an edge without a seam
+	c := canvas.New(4.0, 2.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFillColor(canvas.White)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(4.0, 2.0))
+	ctx.SetFillColor(canvas.Black)
+	ctx.DrawPath(0.0, 0.0, left.Copy().Append(right))
+	img := DrawScanline(c, canvas.DPMM(1.0), canvas.SRGBColorSpace{})
+	test.T(t, img.At(1, 0), color.Color(color.RGBA{0, 0, 0, 255}))
+
+	// separate paths with the same opaque color share an edge without a seam, also with opposite orientations
+	c = canvas.New(4.0, 2.0)
+	ctx = canvas.NewContext(c)
+	ctx.SetFillColor(canvas.White)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(4.0, 2.0))
+	ctx.SetFillColor(canvas.Black)
+	ctx.DrawPath(0.0, 0.0, left)
+	ctx.DrawPath(0.0, 0.0, right.Reverse())
+	img = DrawScanline(c, canvas.DPMM(1.0), canvas.SRGBColorSpace{})
+	test.T(t, img.At(0, 0), color.Color(color.RGBA{0, 0, 0, 255}))
+	test.T(t, img.At(1, 0), color.Color(color.RGBA{0, 0, 0, 255}))
+	test.T(t, img.At(2, 0), color.Color(color.RGBA{0, 0, 0, 255}))
+	img64 := DrawRGBA64(c, canvas.DPMM(1.0), canvas.SRGBColorSpace{})
+	test.T(t, img64.RGBA64At(1, 0), color.RGBA64{0, 0, 0, 0xffff})
+
+	// overlapping paths with the same opaque color are the same as drawn one at a time
+	c = canvas.New(4.0, 2.0)
+	ctx = canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Black)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(2.5, 2.0))
+	ctx.DrawPath(1.5, 0.0, canvas.Rectangle(2.5, 2.0))
+	img = DrawScanline(c, canvas.DPMM(1.0), nil)
+	test.T(t, img.At(2, 0), color.Color(color.RGBA{0, 0, 0, 255}))
+
+	// semi-transparent paths are blended one at a time
+	c = canvas.New(4.0, 2.0)
+	ctx = canvas.NewContext(c)
+	ctx.SetFillColor(color.RGBA{0, 0, 0, 128})
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(2.0, 2.0))
+	ctx.DrawPath(1.0, 0.0, canvas.Rectangle(2.0, 2.0))
+	img = DrawScanline(c, canvas.DPMM(1.0), nil)
+	test.T(t, img.At(0, 0), color.Color(color.RGBA{0, 0, 0, 128}))
+	test.T(t, img.At(1, 0), color.Color(color.RGBA{0, 0, 0, 192}))
+}
+
+func TestScanlineHighBitDepth(t *testing.T) {
+	c := canvas.New(4.0, 1.0)
+	ctx := canvas.NewContext(c)
+	grad := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 4.0, Y: 0.0})
+	grad.Add(0.0, canvas.Black)
+	grad.Add(1.0, canvas.White)
+	ctx.SetFill(grad)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(4.0, 1.0))
+
+	// 16-bit gradient
+	img64 := DrawRGBA64(c, canvas.DPMM(1.0), nil)
+	test.T(t, img64.RGBA64At(0, 0), color.RGBA64{0x2000, 0x2000, 0x2000, 0xffff})
+	test.T(t, img64.RGBA64At(3, 0), color.RGBA64{0xdfff, 0xdfff, 0xdfff, 0xffff})
+
+	nimg64 := DrawNRGBA64(c, canvas.DPMM(1.0), nil)
+	test.T(t, nimg64.NRGBA64At(1, 0), color.NRGBA64{0x6000, 0x6000, 0x6000, 0xffff})
+
+	// linear light
+	c = canvas.New(2.0, 1.0)
+	ctx = canvas.NewContext(c)
+	ctx.SetFillColor(canvas.Gray)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(1.0, 1.0))
+	linear := DrawLinear(c, canvas.DPMM(1.0), canvas.SRGBColorSpace{})
+	r, g, b, a := linear.LinearAt(0, 0)
+	test.FloatDiff(t, float64(r), 0.2158605, 1e-4)
+	test.T(t, g, r)
+	test.T(t, b, r)
+	test.Float(t, float64(a), 1.0)
+	_, _, _, a = linear.LinearAt(1, 0)
+	test.Float(t, float64(a), 0.0)
+
+	// converted to sRGB with 16-bit precision
+	img64 = DrawRGBA64(c, canvas.DPMM(1.0), canvas.SRGBColorSpace{})
+	test.T(t, img64.RGBA64At(0, 0).R>>8, uint16(canvas.Gray.R))
+}
+
+func TestGradientColorSpace(t *testing.T) {
+	gray := color.RGBA{128, 128, 128, 255}
+	grad := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 2.0, Y: 0.0})
+	grad.Add(0.0, gray)
+	grad.Add(1.0, gray)
+
+	// a constant gradient matches the solid fill
+	c := canvas.New(2.0, 1.0)
+	ctx := canvas.NewContext(c)
+	ctx.SetFill(grad)
+	ctx.DrawPath(0.0, 0.0, canvas.Rectangle(1.0, 1.0))
+	ctx.SetFillColor(gray)
+	ctx.DrawPath(1.0, 0.0, canvas.Rectangle(1.0, 1.0))
+
+	colorSpace := canvas.SRGBColorSpace{}
+	img := Draw(c, canvas.DPMM(1.0), colorSpace)
+	test.T(t, img.At(0, 0), color.Color(gray))
+	test.T(t, img.At(1, 0), color.Color(gray))
+	img = DrawScanline(c, canvas.DPMM(1.0), colorSpace)
+	test.T(t, img.At(0, 0), color.Color(gray))
+	test.T(t, img.At(1, 0), color.Color(gray))
+	img64 := DrawRGBA64(c, canvas.DPMM(1.0), colorSpace)
+	test.T(t, img64.RGBA64At(0, 0), img64.RGBA64At(1, 0))
+	test.T(t, img64.RGBA64At(0, 0), color.RGBA64{0x8080, 0x8080, 0x8080, 0xffff})
+	linear := DrawLinear(c, canvas.DPMM(1.0), colorSpace)
+	test.T(t, linear.At(0, 0), linear.At(1, 0))
+
+	// the gradient itself is not converted
+	test.T(t, grad.At(0.5, 0.0), gray)
+}
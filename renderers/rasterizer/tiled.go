@@ -0,0 +1,284 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+
+	"github.com/tdewolff/canvas"
+)
+
+// DefaultTileSize is the default width and height of tiles in pixels.
+var DefaultTileSize = 256
+
+// DrawTiled draws the canvas on a new image with given resolution (in dots-per-millimeter) using a tiled rasterizer, see Draw.
+func DrawTiled(c *canvas.Canvas, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(c.W*resolution.DPMM()+0.5), int(c.H*resolution.DPMM()+0.5)))
+	ras := TiledFromImage(img, resolution, colorSpace)
+	c.RenderTo(ras)
+	ras.Close()
+	return img
+}
+
+// tileOp is a recorded drawing operation of the tiled rasterizer, which is either a transformed outline with a paint or a transformed image.
+type tileOp struct {
+	bounds image.Rectangle // in pixels of the destination image
+
+	path         *canvas.Path
+	paint        canvas.Paint
+	fillRule     canvas.FillRule
+	m            canvas.Matrix
+	op           draw.Op
+	antialiasing Antialiasing
+
+	img         image.Image
+	aff3        f64.Aff3
+	transformer draw.Transformer
+}
+
+// TiledRasterizer is a rasterizing renderer that renders in parallel. Paths are stroked and transformed while rendering, but drawing is deferred until Close, where the drawing operations are binned into square tiles of the image that are rasterized concurrently. Each tile draws its operations in order with the same scanner as Rasterizer, so that the result is identical to that of Rasterizer.
+type TiledRasterizer struct {
+	draw.Image
+	resolution   canvas.Resolution
+	colorSpace   canvas.ColorSpace
+	resampling   canvas.ImageResampling
+	antialiasing Antialiasing
+
+	TileSize int // width and height of tiles in pixels
+	Workers  int // number of concurrent goroutines, by default GOMAXPROCS
+
+	op  draw.Op
+	ops []tileOp
+}
+
+// NewTiled returns a tiled renderer that draws to a rasterized image, see New.
+func NewTiled(width, height float64, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *TiledRasterizer {
+	img := image.NewRGBA(image.Rect(0, 0, int(width*resolution.DPMM()+0.5), int(height*resolution.DPMM()+0.5)))
+	return TiledFromImage(img, resolution, colorSpace)
+}
+
+// TiledFromImage returns a tiled renderer that draws to an existing image, see FromImage. The image is only drawn to at Close.
+func TiledFromImage(img draw.Image, resolution canvas.Resolution, colorSpace canvas.ColorSpace) *TiledRasterizer {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		panic("raster size is zero, increase resolution")
+	} else if math.MaxInt32/bounds.Dx() < bounds.Dy() {
+		panic("raster size overflow, decrease resolution")
+	}
+
+	if colorSpace == nil {
+		colorSpace = canvas.DefaultColorSpace
+	}
+	return &TiledRasterizer{
+		Image:      img,
+		resolution: resolution,
+		colorSpace: colorSpace,
+		TileSize:   DefaultTileSize,
+		Workers:    runtime.GOMAXPROCS(0),
+		op:         draw.Over,
+	}
+}
+
+// SetOp sets the drawing operation for subsequent paths. Either draw.Src or draw.Over.
+func (r *TiledRasterizer) SetOp(op draw.Op) {
+	r.op = op
+}
+
+// SetImageResampling sets the interpolation used to draw subsequent images.
+func (r *TiledRasterizer) SetImageResampling(resampling canvas.ImageResampling) {
+	r.resampling = resampling
+}
+
+// Size returns the size of the canvas in millimeters.
+func (r *TiledRasterizer) Size() (float64, float64) {
+	size := r.Bounds().Size()
+	return float64(size.X) / r.resolution.DPMM(), float64(size.Y) / r.resolution.DPMM()
+}
+
+// RenderPath renders a path to the canvas using a style and a transformation matrix.
+func (r *TiledRasterizer) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix) {
+	fill, stroke := outlines(path, style, m, r.resolution)
+	if style.HasFill() {
+		r.renderPaint(fill, style.Fill, style.FillRule, m)
+	}
+	if style.HasStroke() {
+		r.renderPaint(stroke, style.Stroke, style.FillRule, m)
+	}
+}
+
+// renderPaint records a transformed outline with a paint, see Rasterizer.renderPaint.
+func (r *TiledRasterizer) renderPaint(path *canvas.Path, paint canvas.Paint, fillRule canvas.FillRule, m canvas.Matrix) {
+	if paint.IsPattern() {
+		if hatch, ok := paint.Pattern.(*canvas.HatchPattern); ok {
+			paint = hatch.Fill
+			path = hatch.Tile(path)
+		} else {
+			pattern := paint.Pattern.Transform(m).SetColorSpace(r.colorSpace)
+			pattern.RenderTo(r, path)
+			return
+		}
+	}
+	if !paint.IsGradient() && !paint.IsColor() {
+		return
+	}
+
+	// bounds in pixels with a margin for anti-aliasing
+	dpmm := r.resolution.DPMM()
+	h := float64(r.Bounds().Size().Y)
+	rect := path.FastBounds()
+	bounds := image.Rect(
+		int(math.Floor(rect.X0*dpmm))-1,
+		int(math.Floor(h-rect.Y1*dpmm))-1,
+		int(math.Ceil(rect.X1*dpmm))+1,
+		int(math.Ceil(h-rect.Y0*dpmm))+1,
+	).Add(r.Bounds().Min)
+	r.ops = append(r.ops, tileOp{
+		bounds:       bounds,
+		path:         path,
+		paint:        paint,
+		fillRule:     fillRule,
+		m:            m,
+		op:           r.op,
+		antialiasing: r.antialiasing,
+	})
+}
+
+// RenderText renders a text object to the canvas using a transformation matrix.
+func (r *TiledRasterizer) RenderText(text *canvas.Text, m canvas.Matrix) {
+	text.RenderHintedTo(r, m, r.resolution)
+}
+
+// RenderImage renders an image to the canvas using a transformation matrix.
+func (r *TiledRasterizer) RenderImage(img image.Image, m canvas.Matrix) {
+	img, aff3 := imageTransform(img, m, r.resolution, r.colorSpace, float64(r.Bounds().Size().Y))
+	img, aff3, transformer := resample(img, aff3, r.resampling)
+
+	// bounds in pixels of the destination image with a margin for the interpolation kernel
+	sr := img.Bounds()
+	x0, y0, x1, y1 := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range []image.Point{sr.Min, {sr.Max.X, sr.Min.Y}, {sr.Min.X, sr.Max.Y}, sr.Max} {
+		x := aff3[0]*float64(p.X) + aff3[1]*float64(p.Y) + aff3[2]
+		y := aff3[3]*float64(p.X) + aff3[4]*float64(p.Y) + aff3[5]
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+	}
+	bounds := image.Rect(int(math.Floor(x0))-2, int(math.Floor(y0))-2, int(math.Ceil(x1))+2, int(math.Ceil(y1))+2)
+	r.ops = append(r.ops, tileOp{
+		bounds:      bounds,
+		img:         img,
+		aff3:        aff3,
+		transformer: transformer,
+	})
+}
+
+// Close rasterizes all tiles concurrently and converts the image to the color space.
+func (r *TiledRasterizer) Close() {
+	tileSize := r.TileSize
+	if tileSize <= 0 {
+		tileSize = DefaultTileSize
+	}
+	workers := r.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// bin operations into tiles
+	bounds := r.Bounds()
+	nx := (bounds.Dx() + tileSize - 1) / tileSize
+	ny := (bounds.Dy() + tileSize - 1) / tileSize
+	tiles := make([][]int, nx*ny)
+	for i, op := range r.ops {
+		opBounds := op.bounds.Intersect(bounds)
+		if opBounds.Empty() {
+			continue
+		}
+		tx0 := (opBounds.Min.X - bounds.Min.X) / tileSize
+		ty0 := (opBounds.Min.Y - bounds.Min.Y) / tileSize
+		tx1 := (opBounds.Max.X - bounds.Min.X - 1) / tileSize
+		ty1 := (opBounds.Max.Y - bounds.Min.Y - 1) / tileSize
+		for ty := ty0; ty <= ty1; ty++ {
+			for tx := tx0; tx <= tx1; tx++ {
+				tiles[ty*nx+tx] = append(tiles[ty*nx+tx], i)
+			}
+		}
+	}
+
+	// rasterize tiles, every worker has its own scanner and writes only to the pixels of its tile
+	queue := make(chan int, len(tiles))
+	for i := range tiles {
+		queue <- i
+	}
+	close(queue)
+
+	wg := sync.WaitGroup{}
+	for range min(workers, len(tiles)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ras := FromImage(r.Image, r.resolution, r.colorSpace)
+			for i := range queue {
+				tx, ty := i%nx, i/nx
+				tile := image.Rect(tx*tileSize, ty*tileSize, (tx+1)*tileSize, (ty+1)*tileSize).Add(bounds.Min).Intersect(bounds)
+				r.drawTile(ras, tile, tiles[i])
+			}
+		}()
+	}
+	wg.Wait()
+	r.ops = nil
+}
+
+// drawTile draws the operations to a tile of the image.
+func (r *TiledRasterizer) drawTile(ras *Rasterizer, tile image.Rectangle, ops []int) {
+	// scanner coordinates are relative to the image origin
+	ras.setClip(tile.Sub(r.Bounds().Min))
+
+	// images are drawn through the draw.Image interface like Rasterizer does to obtain the same result
+	dst := clipImage{r.Image, tile}
+	for _, i := range ops {
+		op := r.ops[i]
+		if op.img != nil {
+			op.transformer.Transform(dst, op.aff3, op.img, op.img.Bounds(), draw.Over, nil)
+		} else {
+			ras.SetOp(op.op)
+			if ras.antialiasing != op.antialiasing {
+				ras.SetAntialiasing(op.antialiasing)
+			}
+			ras.drawPath(op.path, op.paint, op.fillRule, op.m)
+		}
+	}
+
+	if _, ok := r.colorSpace.(canvas.LinearColorSpace); !ok {
+		// gamma compress
+		rgba, isRGBA := r.Image.(*image.RGBA)
+		for y := tile.Min.Y; y < tile.Max.Y; y++ {
+			for x := tile.Min.X; x < tile.Max.X; x++ {
+				if isRGBA {
+					rgba.SetRGBA(x, y, r.colorSpace.FromLinear(rgba.At(x, y)))
+				} else {
+					r.Image.Set(x, y, r.colorSpace.FromLinear(r.Image.At(x, y)))
+				}
+			}
+		}
+	}
+}
+
+// clipImage restricts an image to a rectangle.
+type clipImage struct {
+	draw.Image
+	rect image.Rectangle
+}
+
+func (img clipImage) Bounds() image.Rectangle {
+	return img.rect
+}
+
+func (img clipImage) Set(x, y int, c color.Color) {
+	if (image.Point{x, y}).In(img.rect) {
+		img.Image.Set(x, y, c)
+	}
+}
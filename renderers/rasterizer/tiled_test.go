@@ -0,0 +1,53 @@
+package rasterizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/tdewolff/canvas"
+	"github.com/tdewolff/test"
+)
+
+func TestTiledRasterizer(t *testing.T) {
+	family := canvas.NewFontFamily("dejavu-serif")
+	if err := family.LoadFontFile("../../resources/DejaVuSerif.ttf", canvas.FontRegular); err != nil {
+		test.Error(t, err)
+	}
+	face := family.Face(24.0, canvas.Black, canvas.FontRegular, canvas.FontNormal)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 16)
+	}
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+
+	c := canvas.New(60, 40)
+	ctx := canvas.NewContext(c)
+	grad := canvas.NewLinearGradient(canvas.Point{X: 0.0, Y: 0.0}, canvas.Point{X: 60.0, Y: 0.0})
+	grad.Add(0.0, canvas.Red)
+	grad.Add(1.0, canvas.Blue)
+	ctx.SetFill(grad)
+	ctx.DrawPath(5.0, 5.0, canvas.Rectangle(50.0, 30.0))
+	ctx.SetFill(canvas.NewLineHatch(canvas.Green, 45.0, 2.0, 0.2))
+	ctx.SetStrokeColor(canvas.Black)
+	ctx.SetStrokeWidth(0.7)
+	ctx.DrawPath(30.0, 20.0, canvas.Circle(12.0))
+	ctx.SetFillColor(canvas.Transparent)
+	ctx.DrawText(8.0, 30.0, canvas.NewTextLine(face, "Tiles", canvas.Left))
+	ctx.Rotate(30.0)
+	ctx.DrawImage(20.0, 0.0, img, canvas.DPMM(0.5))
+
+	for _, colorSpace := range []canvas.ColorSpace{canvas.LinearColorSpace{}, canvas.SRGBColorSpace{}} {
+		serial := Draw(c, canvas.DPMM(4.0), colorSpace)
+
+		ras := NewTiled(c.W, c.H, canvas.DPMM(4.0), colorSpace)
+		ras.TileSize = 17
+		c.RenderTo(ras)
+		ras.Close()
+		test.T(t, ras.Image.Bounds(), serial.Bounds())
+		test.Bytes(t, ras.Image.(*image.RGBA).Pix, serial.Pix)
+
+		test.Bytes(t, DrawTiled(c, canvas.DPMM(4.0), colorSpace).Pix, serial.Pix)
+	}
+}
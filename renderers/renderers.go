@@ -3,10 +3,14 @@ package renderers
 import (
 	"compress/flate"
 	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"math"
 	"path/filepath"
 	"strings"
 
@@ -24,8 +28,19 @@ const mmPerPt = 25.4 / 72.0
 const ptPerMm = 72.0 / 25.4
 const mmPerPx = 25.4 / 96.0
 
-// Write renders the canvas and writes to a file. A renderer is chosen based on the filename extension. The options will be passed to the respective renderer. Supported extensions: .(png|jpe?g|gif|tiff?|bmp|webp|avif|svgz?|pdf|tex|pgf|ps|eps).
-func Write(filename string, c *canvas.Canvas, opts ...interface{}) error {
+// Write renders the canvas or document and writes to a file. A renderer is chosen based on the filename extension. The options will be passed to the respective renderer. Supported extensions: .(png|jpe?g|gif|tiff?|bmp|pbm|webp|avif|svgz?|pdf|tex|pgf|ps|eps). The drawing must be either a *canvas.Canvas or a *canvas.Document, see WriteDocument for the latter.
+func Write(filename string, drawing interface{}, opts ...interface{}) error {
+	switch d := drawing.(type) {
+	case *canvas.Canvas:
+		return writeCanvas(filename, d, opts...)
+	case *canvas.Document:
+		return WriteDocument(filename, d, opts...)
+	default:
+		return fmt.Errorf("unknown drawing type: %T", drawing)
+	}
+}
+
+func writeCanvas(filename string, c *canvas.Canvas, opts ...interface{}) error {
 	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
 	case ".png":
 		return c.WriteFile(filename, PNG(opts...))
@@ -37,6 +52,8 @@ func Write(filename string, c *canvas.Canvas, opts ...interface{}) error {
 		return c.WriteFile(filename, TIFF(opts...))
 	case ".bmp":
 		return c.WriteFile(filename, BMP(opts...))
+	case ".pbm":
+		return c.WriteFile(filename, PBM(opts...))
 	case ".webp":
 		return c.WriteFile(filename, WebP(opts...))
 	case ".avif":
@@ -59,17 +76,59 @@ func Write(filename string, c *canvas.Canvas, opts ...interface{}) error {
 	return nil
 }
 
+// StackPages is an option for writing a document as a single SVG with its pages stacked vertically and centered horizontally, separated by the given spacing in millimeters.
+type StackPages float64
+
+// WriteDocument renders the document and writes to a file. PDF and PostScript files are written as a single multi-page document, and SVG files too if the StackPages option is given. For other extensions, each page is written to a separate file numbered from one, see canvas.Document.WriteFiles.
+func WriteDocument(filename string, doc *canvas.Document, opts ...interface{}) error {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".pdf":
+		return doc.WriteFile(filename, PDFDocument(opts...))
+	case ".ps":
+		return doc.WriteFile(filename, PSDocument(opts...))
+	case ".svg":
+		if stacked(opts) {
+			return doc.WriteFile(filename, SVGDocument(opts...))
+		}
+	case ".svgz":
+		if stacked(opts) {
+			return doc.WriteFile(filename, SVGZDocument(opts...))
+		}
+	}
+	for i, page := range doc.Pages {
+		if err := writeCanvas(canvas.PageFilename(filename, i+1), page.Canvas, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stacked(opts []interface{}) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(StackPages); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func errorWriter(err error) canvas.Writer {
 	return func(w io.Writer, c *canvas.Canvas) error {
 		return err
 	}
 }
 
-// PNG returns a PNG writer and accepts the following options: canvas.Resolution, canvas.Colorspace, image/png.Encoder
+// BitDepth is an option for the number of bits per color channel of raster images, either 8 (default) or 16. Images with 16 bits per channel are drawn with rasterizer.ScanlineRasterizer instead of rasterizer.Rasterizer, which computes the exact area coverage and always fills strokes with the NonZero fill rule, so that antialiased edges and self-overlapping strokes may differ slightly from an 8-bit image of the same canvas.
+type BitDepth int
+
+// PNG returns a PNG writer and accepts the following options: canvas.Resolution, canvas.Colorspace, image/png.Encoder, BitDepth, image/color.Palette, rasterizer.Dithering. With a bit depth of 16, the canvas is drawn with the scanline rasterizer and written as a 16-bit PNG, see BitDepth. When a palette or dithering is given, the image is reduced to the palette and written as a paletted PNG, see rasterizer.Dither. Without a palette, a palette of 256 colors is quantized from the image.
 func PNG(opts ...interface{}) canvas.Writer {
 	resolution := canvas.DPMM(1.0)
 	colorSpace := canvas.DefaultColorSpace
 	encoder := png.Encoder{}
+	bitDepth := BitDepth(8)
+	var palette color.Palette
+	dithering, paletted := rasterizer.NoDithering, false
 	for _, opt := range opts {
 		switch o := opt.(type) {
 		case canvas.Resolution:
@@ -78,12 +137,33 @@ func PNG(opts ...interface{}) canvas.Writer {
 			colorSpace = o
 		case png.Encoder:
 			encoder = o
+		case BitDepth:
+			if o != 8 && o != 16 {
+				return errorWriter(fmt.Errorf("unsupported PNG bit depth: %d", o))
+			}
+			bitDepth = o
+		case color.Palette:
+			if len(o) == 0 || 256 < len(o) {
+				return errorWriter(fmt.Errorf("unsupported PNG palette size: %d", len(o)))
+			}
+			palette, paletted = o, true
+		case rasterizer.Dithering:
+			dithering, paletted = o, true
 		default:
 			return errorWriter(fmt.Errorf("unknown PNG option: %T(%v)", opt, opt))
 		}
 	}
+	if bitDepth == 16 && paletted {
+		return errorWriter(fmt.Errorf("unsupported PNG bit depth for paletted images: %d", bitDepth))
+	}
 	return func(w io.Writer, c *canvas.Canvas) error {
+		if bitDepth == 16 {
+			return encoder.Encode(w, rasterizer.DrawNRGBA64(c, resolution, colorSpace))
+		}
 		img := rasterizer.Draw(c, resolution, colorSpace)
+		if paletted {
+			return encoder.Encode(w, rasterizer.Dither(img, palette, dithering))
+		}
 		return encoder.Encode(w, img)
 	}
 }
@@ -111,11 +191,13 @@ func JPEG(opts ...interface{}) canvas.Writer {
 	}
 }
 
-// GIF returns a GIF writer and accepts the following options: canvas.Resolution, canvas.Colorspace, image/gif.*Options
+// GIF returns a GIF writer and accepts the following options: canvas.Resolution, canvas.Colorspace, image/gif.*Options, image/color.Palette, rasterizer.Dithering. When a palette or dithering is given, the image is reduced to the palette using rasterizer.Dither instead of the quantizer and drawer of the GIF options. Without a palette, a palette of 256 colors is quantized from the image.
 func GIF(opts ...interface{}) canvas.Writer {
 	resolution := canvas.DPMM(1.0)
 	colorSpace := canvas.DefaultColorSpace
 	var options *gif.Options
+	var palette color.Palette
+	dithering, paletted := rasterizer.NoDithering, false
 	for _, opt := range opts {
 		switch o := opt.(type) {
 		case canvas.Resolution:
@@ -124,12 +206,22 @@ func GIF(opts ...interface{}) canvas.Writer {
 			colorSpace = o
 		case *gif.Options:
 			options = o
+		case color.Palette:
+			if len(o) == 0 || 256 < len(o) {
+				return errorWriter(fmt.Errorf("unsupported GIF palette size: %d", len(o)))
+			}
+			palette, paletted = o, true
+		case rasterizer.Dithering:
+			dithering, paletted = o, true
 		default:
 			return errorWriter(fmt.Errorf("unknown GIF option: %T(%v)", opt, opt))
 		}
 	}
 	return func(w io.Writer, c *canvas.Canvas) error {
 		img := rasterizer.Draw(c, resolution, colorSpace)
+		if paletted {
+			return gif.Encode(w, rasterizer.Dither(img, palette, dithering), options)
+		}
 		return gif.Encode(w, img, options)
 	}
 }
@@ -177,6 +269,55 @@ func BMP(opts ...interface{}) canvas.Writer {
 	}
 }
 
+// PBM returns a 1-bit portable bitmap (PBM) writer and accepts the following options: canvas.Resolution, canvas.Colorspace, rasterizer.Dithering. The image is drawn over a white background and reduced to black and white using the dithering method, which is no dithering by default.
+func PBM(opts ...interface{}) canvas.Writer {
+	resolution := canvas.DPMM(1.0)
+	colorSpace := canvas.DefaultColorSpace
+	dithering := rasterizer.NoDithering
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case canvas.Resolution:
+			resolution = o
+		case canvas.ColorSpace:
+			colorSpace = o
+		case rasterizer.Dithering:
+			dithering = o
+		default:
+			return errorWriter(fmt.Errorf("unknown PBM option: %T(%v)", opt, opt))
+		}
+	}
+	return func(w io.Writer, c *canvas.Canvas) error {
+		img := image.NewRGBA(image.Rect(0, 0, int(c.W*resolution.DPMM()+0.5), int(c.H*resolution.DPMM()+0.5)))
+		draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+		ras := rasterizer.FromImage(img, resolution, colorSpace)
+		c.RenderTo(ras)
+		ras.Close()
+		return encodePBM(w, rasterizer.Dither(img, rasterizer.BlackWhite, dithering))
+	}
+}
+
+// encodePBM writes a black and white image in the binary PBM format, where each row is padded to whole bytes and a set bit is black.
+func encodePBM(w io.Writer, img *image.Paletted) error {
+	bounds := img.Bounds()
+	if _, err := fmt.Fprintf(w, "P4\n%d %d\n", bounds.Dx(), bounds.Dy()); err != nil {
+		return err
+	}
+	row := make([]byte, (bounds.Dx()+7)/8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		clear(row)
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if img.ColorIndexAt(x, y) == 0 {
+				i := x - bounds.Min.X
+				row[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SVG returns an SVG writer and accepts the following options: canvas/renderers/svg.*Options
 func SVG(opts ...interface{}) canvas.Writer {
 	var options *svg.Options
@@ -298,3 +439,143 @@ func EPS(opts ...interface{}) canvas.Writer {
 		return ps.Close()
 	}
 }
+
+// PDFDocument returns a multi-page PDF document writer and accepts the following options: canvas/renderers/pdf.*Options. Page names are added to the outline and page metadata identifiers are added as anchors.
+func PDFDocument(opts ...interface{}) canvas.DocumentWriter {
+	var options *pdf.Options
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case *pdf.Options:
+			options = o
+		default:
+			return errorDocumentWriter(fmt.Errorf("unknown PDF option: %T(%v)", opt, opt))
+		}
+	}
+	return func(w io.Writer, doc *canvas.Document) error {
+		if doc.Len() == 0 {
+			return fmt.Errorf("document has no pages")
+		}
+		pdf := pdf.New(w, doc.Pages[0].W, doc.Pages[0].H, options)
+		for i, page := range doc.Pages {
+			if i != 0 {
+				pdf.NewPage(page.W, page.H)
+			}
+			if page.Name != "" {
+				pdf.AddOutline(page.Name, 0, page.H)
+			}
+			if page.Metadata.ID != "" {
+				pdf.AddAnchor(page.Metadata.ID, canvas.Rect{})
+			}
+			page.RenderTo(pdf)
+		}
+		return pdf.Close()
+	}
+}
+
+// PSDocument returns a multi-page PostScript document writer and accepts the following options: canvas/renderers/ps.*Options. Page names are used as page labels.
+func PSDocument(opts ...interface{}) canvas.DocumentWriter {
+	var options *ps.Options
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case *ps.Options:
+			options = o
+		default:
+			return errorDocumentWriter(fmt.Errorf("unknown PS option: %T(%v)", opt, opt))
+		}
+	}
+	if options == nil {
+		defaultOptions := ps.DefaultOptions
+		options = &defaultOptions
+	}
+	options.Format = ps.PostScript
+	return func(w io.Writer, doc *canvas.Document) error {
+		if doc.Len() == 0 {
+			return fmt.Errorf("document has no pages")
+		}
+		opts := *options
+		opts.PageLabel = doc.Pages[0].Name
+		ps := ps.New(w, doc.Pages[0].W, doc.Pages[0].H, &opts)
+		for i, page := range doc.Pages {
+			if i != 0 {
+				ps.NewPage(page.W, page.H, page.Name)
+			}
+			page.RenderTo(ps)
+		}
+		return ps.Close()
+	}
+}
+
+// SVGDocument returns an SVG document writer that stacks the pages vertically and accepts the following options: canvas/renderers/svg.*Options, StackPages.
+func SVGDocument(opts ...interface{}) canvas.DocumentWriter {
+	var options *svg.Options
+	spacing := 0.0
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case *svg.Options:
+			options = o
+		case StackPages:
+			spacing = float64(o)
+		default:
+			return errorDocumentWriter(fmt.Errorf("unknown SVG option: %T(%v)", opt, opt))
+		}
+	}
+	if options != nil && options.Compression != 0 {
+		options.Compression = 0
+	}
+	return func(w io.Writer, doc *canvas.Document) error {
+		return writeStackedSVG(w, doc, options, spacing)
+	}
+}
+
+// SVGZDocument returns a GZIP compressed SVG document writer that stacks the pages vertically and accepts the following options: canvas/renderers/svg.*Options, StackPages.
+func SVGZDocument(opts ...interface{}) canvas.DocumentWriter {
+	var options *svg.Options
+	spacing := 0.0
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case *svg.Options:
+			options = o
+		case StackPages:
+			spacing = float64(o)
+		default:
+			return errorDocumentWriter(fmt.Errorf("unknown SVGZ option: %T(%v)", opt, opt))
+		}
+	}
+	if options == nil {
+		defaultOptions := svg.DefaultOptions
+		options = &defaultOptions
+		options.Compression = flate.DefaultCompression
+	} else if options.Compression < -2 || options.Compression == 0 || 9 < options.Compression {
+		options.Compression = flate.DefaultCompression
+	}
+	return func(w io.Writer, doc *canvas.Document) error {
+		return writeStackedSVG(w, doc, options, spacing)
+	}
+}
+
+func writeStackedSVG(w io.Writer, doc *canvas.Document, options *svg.Options, spacing float64) error {
+	if doc.Len() == 0 {
+		return fmt.Errorf("document has no pages")
+	}
+
+	width, height := 0.0, spacing*float64(doc.Len()-1)
+	for _, page := range doc.Pages {
+		width = math.Max(width, page.W)
+		height += page.H
+	}
+
+	svg := svg.New(w, width, height, options)
+	top := height
+	for _, page := range doc.Pages {
+		top -= page.H
+		page.RenderViewTo(svg, canvas.Identity.Translate((width-page.W)/2.0, top))
+		top -= spacing
+	}
+	return svg.Close()
+}
+
+func errorDocumentWriter(err error) canvas.DocumentWriter {
+	return func(w io.Writer, doc *canvas.Document) error {
+		return err
+	}
+}
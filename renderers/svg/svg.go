@@ -13,6 +13,7 @@ import (
 	"image/png"
 	"io"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/tdewolff/canvas"
@@ -45,7 +46,10 @@ type SVG struct {
 	maskID        int
 	defs          map[any][2]string
 	classes       []string
+	metadata      canvas.Metadata
+	idWritten     bool
 	customStyle   string
+	resampling    canvas.ImageResampling
 	opts          *Options
 }
 
@@ -124,11 +128,45 @@ func (r *SVG) Close() error {
 }
 
 func (r *SVG) writeClasses(w io.Writer) {
-	if len(r.classes) != 0 {
-		fmt.Fprintf(w, `" class="%s`, strings.Join(r.classes, " "))
+	if !r.idWritten && r.metadata.ID != "" {
+		fmt.Fprintf(w, `" id="%s`, html.EscapeString(r.metadata.ID))
+		r.idWritten = true
+	}
+	if classes := append(r.classes[:len(r.classes):len(r.classes)], r.metadata.Classes...); len(classes) != 0 {
+		fmt.Fprintf(w, `" class="%s`, html.EscapeString(strings.Join(classes, " ")))
+	}
+	if 0 < len(r.metadata.Data) {
+		keys := make([]string, 0, len(r.metadata.Data))
+		for key := range r.metadata.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, `" data-%s="%s`, html.EscapeString(key), html.EscapeString(r.metadata.Data[key]))
+		}
+	}
+}
+
+// writeLink opens a link element if the metadata has a link (see SetMetadata), and returns a function that closes it. Nested drawing operations, such as text decorations, are not linked again.
+func (r *SVG) writeLink() func() {
+	link := r.metadata.Link
+	if link == "" {
+		return func() {}
+	}
+	fmt.Fprintf(r.w, `<a xlink:href="%s">`, html.EscapeString(link))
+	r.metadata.Link = ""
+	return func() {
+		fmt.Fprintf(r.w, `</a>`)
+		r.metadata.Link = link
 	}
 }
 
+// SetMetadata sets the metadata of the drawn objects that follow. The identifier is assigned to the first drawn object, the classes are added to the classes of each drawn object (see SetClass), data is written as data-* attributes, and drawn objects are wrapped in a link element.
+func (r *SVG) SetMetadata(meta canvas.Metadata) {
+	r.metadata = meta
+	r.idWritten = false
+}
+
 // SetClass sets the classes to be assigned to drawn objects.
 func (r *SVG) SetClass(classes ...string) {
 	r.classes = classes
@@ -162,6 +200,11 @@ func (r *SVG) SetImageEncoding(enc cimage.ImageEncoding) {
 	r.opts.ImageEncoding = enc
 }
 
+// SetImageResampling sets the interpolation of the images that follow, which is written as the image-rendering attribute. Nearest resampling is written as pixelated, Lanczos resampling as optimizeQuality, and other resamplings use the default smooth rendering of the viewer.
+func (r *SVG) SetImageResampling(resampling canvas.ImageResampling) {
+	r.resampling = resampling
+}
+
 // SetCustomStyle defines a custom CSS code to add in the SVG
 func (r *SVG) SetCustomStyle(style string) {
 	r.customStyle = style
@@ -177,6 +220,8 @@ func (r *SVG) RenderPath(path *canvas.Path, style canvas.Style, m canvas.Matrix)
 	fillPaint, fillOpacity := r.writePaint(style.Fill, m)
 	strokePaint, strokeOpacity := r.writePaint(style.Stroke, m)
 
+	defer r.writeLink()()
+
 	stroke := path
 	path = path.Copy().Transform(canvas.Identity.ReflectYAbout(r.height / 2.0).Mul(m))
 	fmt.Fprintf(r.w, `<path d="%s`, path.ToSVG())
@@ -400,6 +445,8 @@ func (r *SVG) RenderText(text *canvas.Text, m canvas.Matrix) {
 		}
 	})
 
+	defer r.writeLink()()
+
 	faceMain := text.MostCommonFontFace()
 	x0, y0 := 0.0, 0.0
 	if m.IsTranslation() {
@@ -475,6 +522,8 @@ func (r *SVG) RenderImage(img image.Image, m canvas.Matrix) {
 	size := img.Bounds().Size()
 	writeTo, refMask, mimetype := r.encodableImage(img)
 
+	defer r.writeLink()()
+
 	m = m.Translate(0.0, float64(size.Y))
 	fmt.Fprintf(r.w, `<image transform="%s" width="%d" height="%d" xlink:href="data:%s;base64,`,
 		m.ToSVG(r.height), size.X, size.Y, mimetype)
@@ -491,6 +540,12 @@ func (r *SVG) RenderImage(img image.Image, m canvas.Matrix) {
 	if refMask != "" {
 		fmt.Fprintf(r.w, `" mask="url(#%s)`, refMask)
 	}
+	switch r.resampling {
+	case canvas.NearestResampling:
+		fmt.Fprintf(r.w, `" image-rendering="pixelated`)
+	case canvas.LanczosResampling:
+		fmt.Fprintf(r.w, `" image-rendering="optimizeQuality`)
+	}
 	r.writeClasses(r.w)
 	fmt.Fprintf(r.w, `"/>`)
 }
@@ -2,6 +2,7 @@ package svg
 
 import (
 	"bytes"
+	"image"
 	"image/color"
 	"strings"
 	"testing"
@@ -113,6 +114,35 @@ func TestPaintOpacity(t *testing.T) {
 	}
 }
 
+func TestSVGMetadata(t *testing.T) {
+	s := renderSVG(func(ctx *canvas.Context) {
+		ctx.SetMetadata(canvas.Metadata{
+			ID:      "a&b",
+			Classes: []string{"x", "y"},
+			Link:    "https://example.com/?a=1&b=2",
+			Data:    map[string]string{"z": "3", "k": `"v"`},
+		})
+		ctx.DrawPath(10.0, 10.0, canvas.Rectangle(80.0, 80.0))
+		ctx.DrawPath(10.0, 10.0, canvas.Rectangle(10.0, 10.0))
+		ctx.SetMetadata(canvas.Metadata{})
+		ctx.DrawPath(0.0, 0.0, canvas.Rectangle(10.0, 10.0))
+	})
+	test.String(t, s, `<a xlink:href="https://example.com/?a=1&amp;b=2"><path d="M10 90H90V10H10z" id="a&amp;b" class="x y" data-k="&#34;v&#34;" data-z="3"/></a>`+
+		`<a xlink:href="https://example.com/?a=1&amp;b=2"><path d="M10 90H20V80H10z" class="x y" data-k="&#34;v&#34;" data-z="3"/></a>`+
+		`<path d="M0 100H10V90H0z"/>`)
+}
+
+func TestSVGImageResampling(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	s := renderSVG(func(ctx *canvas.Context) {
+		ctx.DrawImage(0.0, 0.0, img, canvas.DPMM(1.0))
+		ctx.SetImageResampling(canvas.NearestResampling)
+		ctx.DrawImage(0.0, 0.0, img, canvas.DPMM(1.0))
+	})
+	test.T(t, strings.Count(s, `<image `), 2)
+	test.T(t, strings.Count(s, `" image-rendering="pixelated"/>`), 1)
+}
+
 func TestSplitAlpha(t *testing.T) {
 	var tests = []struct {
 		col     color.RGBA
@@ -0,0 +1,128 @@
+package canvas
+
+import (
+	"math"
+	"sort"
+)
+
+// rtreeNodeCapacity is the maximum number of children per node of an R-tree.
+const rtreeNodeCapacity = 16
+
+type rtreeNode struct {
+	Rect
+	i, j int // range of children in the level below, or the index of the item for the leaves
+}
+
+// RTree is a static spatial index of rectangles for fast point and rectangle queries. It is bulk-loaded using the Sort-Tile-Recursive (STR) algorithm, which packs nearby rectangles into the same nodes. See S.T. Leutenegger, et al., "STR: A simple and efficient algorithm for R-tree packing", Proceedings 13th International Conference on Data Engineering, 1997, DOI: 10.1109/ICDE.1997.582015.
+type RTree struct {
+	levels [][]rtreeNode // from the leaves to the root
+}
+
+// NewRTree returns an R-tree of the given rectangles, where queries return the index into rects. It is built in O(n log n), with n the number of rectangles.
+func NewRTree(rects []Rect) *RTree {
+	nodes := make([]rtreeNode, len(rects))
+	for i, rect := range rects {
+		nodes[i] = rtreeNode{Rect: rect, i: i}
+	}
+
+	t := &RTree{}
+	for {
+		strSort(nodes)
+		t.levels = append(t.levels, nodes)
+		if len(nodes) <= 1 {
+			break
+		}
+
+		parents := make([]rtreeNode, 0, (len(nodes)+rtreeNodeCapacity-1)/rtreeNodeCapacity)
+		for i := 0; i < len(nodes); i += rtreeNodeCapacity {
+			j := min(i+rtreeNodeCapacity, len(nodes))
+			rect := nodes[i].Rect
+			for _, node := range nodes[i+1 : j] {
+				rect = rect.Add(node.Rect)
+			}
+			parents = append(parents, rtreeNode{rect, i, j})
+		}
+		nodes = parents
+	}
+	return t
+}
+
+// strSort sorts the nodes so that consecutive runs of rtreeNodeCapacity nodes form the tiles of the STR algorithm, i.e. it sorts the nodes in vertical slices by their center's X coordinate, and each slice by their center's Y coordinate.
+func strSort(nodes []rtreeNode) {
+	if len(nodes) <= rtreeNodeCapacity {
+		return
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].X0+nodes[i].X1 < nodes[j].X0+nodes[j].X1
+	})
+
+	numParents := (len(nodes) + rtreeNodeCapacity - 1) / rtreeNodeCapacity
+	numSlices := int(math.Ceil(math.Sqrt(float64(numParents))))
+	sliceSize := numSlices * rtreeNodeCapacity
+	for i := 0; i < len(nodes); i += sliceSize {
+		slice := nodes[i:min(i+sliceSize, len(nodes))]
+		sort.Slice(slice, func(i, j int) bool {
+			return slice[i].Y0+slice[i].Y1 < slice[j].Y0+slice[j].Y1
+		})
+	}
+}
+
+// Len returns the number of rectangles in the R-tree.
+func (t *RTree) Len() int {
+	if len(t.levels) == 0 {
+		return 0
+	}
+	return len(t.levels[0])
+}
+
+// Bounds returns the bounding box of all rectangles in the R-tree.
+func (t *RTree) Bounds() Rect {
+	if t.Len() == 0 {
+		return Rect{}
+	}
+	return t.levels[len(t.levels)-1][0].Rect
+}
+
+// Search returns the indices of the rectangles that touch or overlap rect, in ascending order. It runs in O(log n + k), with n the number of rectangles and k the number of results.
+func (t *RTree) Search(rect Rect) []int {
+	return t.search(func(r Rect) bool {
+		return r.Touches(rect)
+	})
+}
+
+// SearchPoint returns the indices of the rectangles that contain or touch the given point, in ascending order, see Search.
+func (t *RTree) SearchPoint(p Point) []int {
+	return t.search(func(r Rect) bool {
+		return r.TouchesPoint(p)
+	})
+}
+
+func (t *RTree) search(f func(Rect) bool) []int {
+	if t.Len() == 0 {
+		return nil
+	}
+
+	type item struct {
+		rtreeNode
+		level int
+	}
+	root := len(t.levels) - 1
+	stack := []item{{t.levels[root][0], root}}
+
+	var is []int
+	for 0 < len(stack) {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !f(cur.Rect) {
+			continue
+		} else if cur.level == 0 {
+			is = append(is, cur.i)
+			continue
+		}
+		for _, node := range t.levels[cur.level-1][cur.i:cur.j] {
+			stack = append(stack, item{node, cur.level - 1})
+		}
+	}
+	sort.Ints(is)
+	return is
+}
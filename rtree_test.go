@@ -0,0 +1,57 @@
+package canvas
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestRTree(t *testing.T) {
+	index := NewRTree(nil)
+	test.T(t, index.Len(), 0)
+	test.T(t, index.Bounds(), Rect{})
+	test.T(t, len(index.Search(Rect{0.0, 0.0, 1.0, 1.0})), 0)
+
+	index = NewRTree([]Rect{{0.0, 0.0, 1.0, 1.0}})
+	test.T(t, index.Len(), 1)
+	test.T(t, index.Bounds(), Rect{0.0, 0.0, 1.0, 1.0})
+	test.T(t, index.SearchPoint(Point{0.5, 0.5}), []int{0})
+	test.T(t, len(index.SearchPoint(Point{2.0, 0.5})), 0)
+
+	index = NewRTree([]Rect{{0.0, 0.0, 1.0, 1.0}, {2.0, 0.0, 3.0, 1.0}, {0.5, 0.5, 2.5, 2.0}})
+	test.T(t, index.Bounds(), Rect{0.0, 0.0, 3.0, 2.0})
+	test.T(t, index.SearchPoint(Point{0.75, 0.75}), []int{0, 2})
+	test.T(t, index.SearchPoint(Point{1.0, 0.25}), []int{0})
+	test.T(t, index.Search(Rect{1.5, 0.0, 2.0, 0.25}), []int{1})
+	test.T(t, index.Search(Rect{-1.0, -1.0, 4.0, 4.0}), []int{0, 1, 2})
+}
+
+func TestRTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, n := range []int{10, 100, 1000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			rects := make([]Rect, n)
+			for i := range rects {
+				x, y := 100.0*r.Float64(), 100.0*r.Float64()
+				rects[i] = Rect{x, y, x + 10.0*r.Float64(), y + 10.0*r.Float64()}
+			}
+			index := NewRTree(rects)
+			test.T(t, index.Len(), n)
+
+			for k := 0; k < 100; k++ {
+				x, y := 100.0*r.Float64(), 100.0*r.Float64()
+				rect := Rect{x, y, x + 5.0*r.Float64(), y + 5.0*r.Float64()}
+
+				var is []int
+				for i := range rects {
+					if rects[i].Touches(rect) {
+						is = append(is, i)
+					}
+				}
+				test.T(t, index.Search(rect), is)
+			}
+		})
+	}
+}
@@ -1237,24 +1237,31 @@ func (t *Text) RenderAsPath(r Renderer, m Matrix, resolution Resolution) {
 
 func (t *Text) RenderDecorationsTo(r Renderer, m Matrix, resolution Resolution) {
 	for index := range t.lines {
-		t.renderLineTo(r, m, resolution, index, false, true)
+		t.renderLineTo(r, m, resolution, index, false, true, false)
 	}
 }
 
 func (t *Text) RenderTextTo(r Renderer, m Matrix, resolution Resolution) {
 	for index := range t.lines {
-		t.renderLineTo(r, m, resolution, index, true, false)
+		t.renderLineTo(r, m, resolution, index, true, false, false)
 	}
 }
 
 // RenderTo renders the text and its decorations converted to paths, calling r.RenderPath on the renderer. Note that text lines are drawn downwards starting at the origin, that is along negative Y. The origin is thus the top-left corner of the text box.
 func (t *Text) RenderTo(r Renderer, m Matrix, resolution Resolution) {
 	for index := range t.lines {
-		t.renderLineTo(r, m, resolution, index, true, true)
+		t.renderLineTo(r, m, resolution, index, true, true, false)
 	}
 }
 
-func (t *Text) renderLineTo(r Renderer, m Matrix, resolution Resolution, index int, renderText, renderDeco bool) {
+// RenderHintedTo is the same as RenderTo, but grid-fits the glyphs vertically for rasterization at the given resolution, see FontFace.Hinting. It is meant for rasterizers, as the outlines of hinted glyphs are distorted for other resolutions.
+func (t *Text) RenderHintedTo(r Renderer, m Matrix, resolution Resolution) {
+	for index := range t.lines {
+		t.renderLineTo(r, m, resolution, index, true, true, true)
+	}
+}
+
+func (t *Text) renderLineTo(r Renderer, m Matrix, resolution Resolution, index int, renderText, renderDeco, hinting bool) {
 	line := t.lines[index]
 	ps := make([]*Path, len(line.spans))
 	xs := make([]float64, len(line.spans))
@@ -1272,8 +1279,12 @@ func (t *Text) renderLineTo(r Renderer, m Matrix, resolution Resolution, index i
 		}
 		xs[i] = x
 		ys[i] = y
-		if span.IsText() {
-			ps[i], _ = span.Face.toPath(span.Glyphs, span.Face.PPEM(resolution))
+		if span.IsText() && (renderText || span.Face.HasDecoration()) {
+			hintingPPEM := 0.0
+			if hinting && resolution != 0.0 && t.WritingMode == HorizontalTB && span.Rotation == text.NoRotation && !m.HasRotation() {
+				hintingPPEM = span.Face.hintingPPEM(resolution) * math.Abs(m[1][1])
+			}
+			ps[i], _ = span.Face.toPath(span.Glyphs, hintingPPEM)
 		}
 	}
 
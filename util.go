@@ -1089,6 +1089,89 @@ func solveCubicFormula(a, b, c, d float64) (float64, float64, float64) {
 	return x1, x2, x3
 }
 
+// polynomialEval evaluates the polynomial with coefficients p[k] for x^k at x.
+func polynomialEval(p []float64, x float64) float64 {
+	y := 0.0
+	for k := len(p) - 1; 0 <= k; k-- {
+		y = y*x + p[k]
+	}
+	return y
+}
+
+// polynomialRoots returns the real roots in [a,b] of the polynomial with coefficients p[k] for x^k, in ascending order. The polynomial is monotone between the roots of its derivative, which are found recursively, so that each root is bracketed and found to machine precision. Roots of even multiplicity are only found when they are exact.
+func polynomialRoots(p []float64, a, b float64) []float64 {
+	for 0 < len(p) && p[len(p)-1] == 0.0 {
+		p = p[:len(p)-1]
+	}
+	if len(p) < 2 {
+		return nil
+	} else if len(p) < 4 {
+		var roots []float64
+		x1, x2 := math.NaN(), math.NaN()
+		if len(p) == 2 {
+			x1 = -p[0] / p[1]
+		} else {
+			x1, x2 = solveQuadraticFormula(p[2], p[1], p[0])
+		}
+		for _, x := range []float64{x1, x2} {
+			if !math.IsNaN(x) && a <= x && x <= b {
+				roots = append(roots, x)
+			}
+		}
+		return roots
+	}
+
+	deriv := make([]float64, len(p)-1)
+	for k := 1; k < len(p); k++ {
+		deriv[k-1] = float64(k) * p[k]
+	}
+	xs := append([]float64{a}, polynomialRoots(deriv, a, b)...)
+	xs = append(xs, b)
+
+	var roots []float64
+	x0, y0 := xs[0], polynomialEval(p, xs[0])
+	if y0 == 0.0 {
+		roots = append(roots, x0)
+	}
+	for _, x1 := range xs[1:] {
+		y1 := polynomialEval(p, x1)
+		if y1 == 0.0 {
+			if len(roots) == 0 || roots[len(roots)-1] != x1 {
+				roots = append(roots, x1)
+			}
+		} else if (y0 < 0.0) != (y1 < 0.0) && y0 != 0.0 {
+			// bisection with Newton steps, the polynomial is monotone in [x0,x1]
+			lo, hi := x0, x1
+			if 0.0 < y0 {
+				lo, hi = x1, x0 // p(lo) < 0 < p(hi)
+			}
+			x := (x0 + x1) / 2.0
+			for i := 0; i < 100; i++ {
+				y := polynomialEval(p, x)
+				if y == 0.0 {
+					break
+				} else if y < 0.0 {
+					lo = x
+				} else {
+					hi = x
+				}
+
+				xNext := x - y/polynomialEval(deriv, x)
+				if math.IsNaN(xNext) || xNext <= math.Min(lo, hi) || math.Max(lo, hi) <= xNext {
+					xNext = (lo + hi) / 2.0
+				}
+				if xNext == x || xNext == lo || xNext == hi {
+					break
+				}
+				x = xNext
+			}
+			roots = append(roots, x)
+		}
+		x0, y0 = x1, y1
+	}
+	return roots
+}
+
 type gaussLegendreFunc func(func(float64) float64, float64, float64) float64
 
 // Gauss-Legendre quadrature integration from a to b with n=3, see https://pomax.github.io/bezierinfo/legendre-gauss.html for more values
@@ -1255,6 +1338,20 @@ func invSpeedPolynomialChebyshevApprox(N int, gaussLegendre gaussLegendreFunc, f
 	return polynomialChebyshevApprox(N, t, 0.0, totalLength, tmin, tmax), totalLength
 }
 
+// invSpeedRefine refines t, as approximated by invSpeedPolynomialChebyshevApprox for length L, using Newton's method on the length along fp from tmin.
+func invSpeedRefine(fp func(float64) float64, tmin, tmax, t, L float64) float64 {
+	sign := 1.0
+	if tmax < tmin {
+		sign = -1.0
+	}
+	for i := 0; i < 2; i++ {
+		if speed := fp(t); speed != 0.0 {
+			t -= sign * (math.Abs(gaussLegendre7(fp, tmin, t)) - L) / speed
+		}
+	}
+	return math.Max(math.Min(tmin, tmax), math.Min(math.Max(tmin, tmax), t))
+}
+
 func polynomialChebyshevApprox(N int, f func(float64) float64, xmin, xmax, ymin, ymax float64) func(float64) float64 {
 	fs := make([]float64, N)
 	for k := 0; k < N; k++ {
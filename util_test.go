@@ -337,6 +337,35 @@ func TestSolveCubicFormula(t *testing.T) {
 	}
 }
 
+func TestPolynomialRoots(t *testing.T) {
+	var tests = []struct {
+		p     []float64
+		a, b  float64
+		roots []float64
+	}{
+		{[]float64{1.0}, -10.0, 10.0, nil},
+		{[]float64{-2.0, 1.0}, -10.0, 10.0, []float64{2.0}},
+		{[]float64{-2.0, 1.0, 0.0}, 0.0, 1.0, nil},
+		{[]float64{-1.0, 0.0, 1.0}, -10.0, 10.0, []float64{-1.0, 1.0}},
+		{[]float64{0.0, 0.0, 0.0, 1.0}, -10.0, 10.0, []float64{0.0}},
+		{[]float64{-6.0, 11.0, -6.0, 1.0}, 0.0, 10.0, []float64{1.0, 2.0, 3.0}},
+		{[]float64{-6.0, 11.0, -6.0, 1.0}, 1.5, 3.0, []float64{2.0, 3.0}},
+		{[]float64{4.0, 0.0, -5.0, 0.0, 1.0}, -10.0, 10.0, []float64{-2.0, -1.0, 1.0, 2.0}},
+		{[]float64{-120.0, 274.0, -225.0, 85.0, -15.0, 1.0}, -10.0, 10.0, []float64{1.0, 2.0, 3.0, 4.0, 5.0}},
+		{[]float64{0.0, 1.0, 0.0, 1.0, 0.0, 1.0}, -10.0, 10.0, []float64{0.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.p), func(t *testing.T) {
+			roots := polynomialRoots(tt.p, tt.a, tt.b)
+			test.T(t, len(roots), len(tt.roots))
+			for i := range roots {
+				test.Float(t, roots[i], tt.roots[i])
+			}
+		})
+	}
+}
+
 func TestGaussLegendre(t *testing.T) {
 	test.Float(t, gaussLegendre3(math.Log, 0.0, 1.0), -0.9476723836)
 	test.Float(t, gaussLegendre5(math.Log, 0.0, 1.0), -0.9790015666)